@@ -0,0 +1,137 @@
+// Package aip maps paginator to list methods following Google AIP-158
+// (https://google.aip.dev/158), where page tokens are opaque cursors of the
+// paginator and the last page has empty next page token.
+package aip
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Errors of list requests, they are INVALID_ARGUMENT errors of AIP-158
+var (
+	ErrInvalidPageSize  = errors.New("page_size should not be negative")
+	ErrInvalidSkip      = errors.New("skip should not be negative")
+	ErrSkipTooLarge     = errors.New("skip should not exceed max skip")
+	ErrInvalidPageToken = errors.New("page_token is invalid")
+)
+
+const (
+	// defaultPageSize is default page size, which is default limit of
+	// paginator
+	defaultPageSize = 10
+	// defaultMaxSkip is max skip of options without MaxSkip and MaxPageSize
+	defaultMaxSkip = 1000
+)
+
+// Request are paging fields of list request
+type Request struct {
+	PageSize  int32
+	PageToken string
+	Skip      int32
+}
+
+// Options of binding list requests
+type Options struct {
+	// DefaultPageSize is page size of requests without page_size, default
+	// is 10
+	DefaultPageSize int32
+	// MaxPageSize coerces page_size down to it if it is positive
+	MaxPageSize int32
+	// MaxSkip rejects requests skipping more rows by ErrSkipTooLarge, since
+	// skipped rows are fetched along with the page. Default is MaxPageSize
+	// if it is positive, or 1000.
+	MaxSkip int32
+	// Filters are other fields of request affecting results, e.g. filter
+	// and order_by, page tokens of requests with different filters are
+	// rejected by ErrInvalidPageToken
+	Filters map[string]interface{}
+}
+
+// Bind binds list request to p. Page tokens are bound to filters of options
+// by cursor fingerprint, and skip drops that many rows after page token
+// before the page.
+func Bind(p *paginator.Paginator, req Request, opts Options) error {
+	if req.PageSize < 0 {
+		return ErrInvalidPageSize
+	}
+	if req.Skip < 0 {
+		return ErrInvalidSkip
+	}
+	maxSkip := opts.MaxSkip
+	if maxSkip <= 0 {
+		maxSkip = opts.MaxPageSize
+	}
+	if maxSkip <= 0 {
+		maxSkip = defaultMaxSkip
+	}
+	if req.Skip > maxSkip {
+		return fmt.Errorf("%w: %d > %d", ErrSkipTooLarge, req.Skip, maxSkip)
+	}
+	size := req.PageSize
+	if size == 0 {
+		size = opts.DefaultPageSize
+	}
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	if opts.MaxPageSize > 0 && size > opts.MaxPageSize {
+		size = opts.MaxPageSize
+	}
+	// limit should not overflow int of 32-bit platforms
+	if int64(size)+int64(req.Skip) > math.MaxInt32 {
+		return fmt.Errorf("%w: %d", ErrSkipTooLarge, req.Skip)
+	}
+	p.SetFilters(opts.Filters)
+	if req.PageToken != "" {
+		p.SetAfterCursor(req.PageToken)
+	}
+	// skipped rows are fetched along with the page
+	p.SetLimit(int(size) + int(req.Skip))
+	if req.Skip > 0 {
+		p.SetTransform(skipRows(int(req.Skip)))
+	}
+	return nil
+}
+
+// skipRows returns transform hook dropping first n rows of page
+func skipRows(n int) paginator.TransformHook {
+	return func(page interface{}) error {
+		elems := reflect.ValueOf(page).Elem()
+		skip := n
+		if skip > elems.Len() {
+			skip = elems.Len()
+		}
+		elems.Set(elems.Slice(skip, elems.Len()))
+		return nil
+	}
+}
+
+// NextPageToken returns next page token of paginated page of p, which is
+// empty on the last page
+func NextPageToken(p *paginator.Paginator) string {
+	if next := p.GetNextCursor().After; next != nil {
+		return *next
+	}
+	return ""
+}
+
+// MapError maps errors of page tokens to ErrInvalidPageToken, other errors
+// are returned as they are. Errors of this package and ErrInvalidPageToken
+// should be reported as INVALID_ARGUMENT.
+func MapError(err error) error {
+	for _, target := range []error{
+		paginator.ErrInvalidCursor,
+		paginator.ErrCursorExpired,
+		paginator.ErrCursorFingerprintMismatch,
+	} {
+		if errors.Is(err, target) {
+			return fmt.Errorf("%w: %s", ErrInvalidPageToken, err)
+		}
+	}
+	return err
+}
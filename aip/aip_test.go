@@ -0,0 +1,98 @@
+package aip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+type order struct {
+	ID int
+}
+
+var options = Options{
+	DefaultPageSize: 2,
+	MaxPageSize:     3,
+	Filters:         map[string]interface{}{"filter": "state = OPEN"},
+}
+
+func list(req Request, opts Options) ([]int, string, error) {
+	rows := []order{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}
+	p := paginator.New()
+	p.SetOrder(paginator.ASC)
+	if err := Bind(p, req, opts); err != nil {
+		return nil, "", err
+	}
+	var out []order
+	if _, err := p.Paginate(paginatortest.NewQuery("orders", rows, &out)); err != nil {
+		return nil, "", MapError(err)
+	}
+	ids := make([]int, len(out))
+	for i, o := range out {
+		ids[i] = o.ID
+	}
+	return ids, NextPageToken(p), nil
+}
+
+func TestBind(t *testing.T) {
+	ids, token, err := list(Request{}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.NotEmpty(t, token)
+
+	// page size is coerced to max page size
+	ids, token, err = list(Request{PageSize: 10, PageToken: token}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, ids)
+
+	ids, token, err = list(Request{PageToken: token}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{6}, ids)
+	assert.Empty(t, token)
+}
+
+func TestBindSkip(t *testing.T) {
+	ids, token, err := list(Request{PageSize: 2, Skip: 1}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ids)
+
+	// skip applies after page token
+	ids, token, err = list(Request{PageSize: 2, PageToken: token, Skip: 2}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{6}, ids)
+	assert.Empty(t, token)
+}
+
+func TestBindShouldRejectInvalidRequest(t *testing.T) {
+	_, _, err := list(Request{PageSize: -1}, options)
+	assert.Equal(t, ErrInvalidPageSize, err)
+
+	_, _, err = list(Request{Skip: -1}, options)
+	assert.Equal(t, ErrInvalidSkip, err)
+
+	// skip is capped by max page size without max skip
+	_, _, err = list(Request{Skip: 2147483647}, options)
+	assert.True(t, errors.Is(err, ErrSkipTooLarge))
+	_, _, err = list(Request{Skip: 4}, options)
+	assert.True(t, errors.Is(err, ErrSkipTooLarge))
+	ids, _, err := list(Request{PageSize: 1, Skip: 4}, Options{MaxSkip: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, ids)
+	_, _, err = list(Request{Skip: 1001}, Options{})
+	assert.True(t, errors.Is(err, ErrSkipTooLarge))
+	_, _, err = list(Request{PageSize: 2147483647, Skip: 1}, Options{MaxSkip: 2147483647})
+	assert.True(t, errors.Is(err, ErrSkipTooLarge))
+
+	_, _, err = list(Request{PageToken: "invalid"}, options)
+	assert.True(t, errors.Is(err, ErrInvalidPageToken))
+
+	// page token of other filters
+	_, token, err := list(Request{}, options)
+	assert.NoError(t, err)
+	_, _, err = list(Request{PageToken: token}, Options{Filters: map[string]interface{}{"filter": "state = CLOSED"}})
+	assert.True(t, errors.Is(err, ErrInvalidPageToken))
+}
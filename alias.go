@@ -0,0 +1,22 @@
+package paginator
+
+// SetAlias sets alias of the paginated side of queries joining the table to
+// itself, e.g. o of `orders AS o JOIN orders AS p ON ...`. Keys are qualified
+// by the alias in WHERE and ORDER BY regardless of Table() of query, and
+// queries without selects select only columns of the alias, so that columns
+// of the other side neither shadow nor are scanned into the paginated rows.
+func (p *Paginator) SetAlias(alias string) {
+	p.alias = alias
+}
+
+// appendAliasSelects selects columns of alias for queries without selects
+func (p *Paginator) appendAliasSelects(query Query) Query {
+	if p.alias == "" || p.wrapped {
+		return query
+	}
+	sq, ok := query.(SelectQuery)
+	if !ok || len(sq.Selects()) > 0 {
+		return query
+	}
+	return sq.AddSelects(p.alias + ".*")
+}
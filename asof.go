@@ -0,0 +1,53 @@
+package paginator
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetAsOfSystemTime sets queries to read historical data of CockroachDB at
+// interval before now by AS OF SYSTEM TIME, e.g. 5s for follower reads,
+// which requires query to implement HintQuery. Pages are read from the same
+// snapshot if time of the first page (see GetSnapshotTime) is carried to
+// next pages by SetSnapshotTime, EachPage and EncodeState carry it along.
+func (p *Paginator) SetAsOfSystemTime(interval time.Duration) {
+	if interval < 0 {
+		interval = -interval
+	}
+	p.asOf = interval
+}
+
+// SetSnapshotTime sets time of AS OF SYSTEM TIME, which is resolved by
+// interval of SetAsOfSystemTime if not set
+func (p *Paginator) SetSnapshotTime(t time.Time) {
+	p.snapshot = t
+}
+
+// GetSnapshotTime returns time of AS OF SYSTEM TIME the page is read at,
+// which is zero if historical reads are not enabled. It is kept by the page
+// only, paginating again without SetSnapshotTime reads a new snapshot.
+func (p *Paginator) GetSnapshotTime() time.Time {
+	return p.resolved().snapshot
+}
+
+// getSnapshotTime returns time of AS OF SYSTEM TIME, which is zero if
+// historical reads are not enabled
+func (p *Paginator) getSnapshotTime() time.Time {
+	if !p.snapshot.IsZero() || p.asOf == 0 {
+		return p.snapshot
+	}
+	return p.getNow().Add(-p.asOf)
+}
+
+// getHint returns hint of paginator with AS OF SYSTEM TIME of snapshot
+func (p *Paginator) getHint() Hint {
+	hint := p.hint
+	if snapshot := p.getSnapshotTime(); !snapshot.IsZero() {
+		asOf := fmt.Sprintf("AS OF SYSTEM TIME '%s'", snapshot.UTC().Format("2006-01-02 15:04:05.999999"))
+		if hint.Table != "" {
+			asOf = hint.Table + " " + asOf
+		}
+		hint.Table = asOf
+	}
+	return hint
+}
@@ -0,0 +1,85 @@
+package paginator
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// Errors for batches
+var (
+	// ErrBatchPaginatorShared is returned for items of batch sharing
+	// paginator with a prior item, since result of the page is kept by
+	// paginator
+	ErrBatchPaginatorShared = errors.New("paginator should not be shared by items of batch")
+	// ErrBatchItemInvalid is returned for items of batch without paginator
+	// or query
+	ErrBatchItemInvalid = errors.New("item of batch should have paginator and query")
+)
+
+// BatchItem is a query paginated by its paginator in PaginateBatch
+type BatchItem struct {
+	Paginator *Paginator
+	Query     Query
+}
+
+// BatchResult is result of paginating a BatchItem
+type BatchResult struct {
+	// Query is the executed query returned by Paginate
+	Query Query
+	// Cursor is cursor for next pagination
+	Cursor Cursor
+	Err    error
+}
+
+// PaginateBatch paginates independent items concurrently by at most workers
+// goroutines, or one per item if workers is not positive, e.g. to fetch the
+// first pages of several lists of a dashboard at once. Results are returned
+// in order of items, an error of an item does not stop other items. Each
+// item should have its own paginator (see Clone), page of item is then got
+// by GetPage of its paginator. Items without paginator or query fail by
+// ErrBatchItemInvalid.
+func PaginateBatch(workers int, items ...BatchItem) []BatchResult {
+	results := make([]BatchResult, len(items))
+	if workers <= 0 || workers > len(items) {
+		workers = len(items)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item := items[i]
+				result, err := item.Paginator.Paginate(item.Query)
+				results[i] = BatchResult{Query: result, Cursor: item.Paginator.GetNextCursor(), Err: err}
+			}
+		}()
+	}
+	seen := make(map[*Paginator]bool, len(items))
+	for i, item := range items {
+		if item.Paginator == nil || isNilQuery(item.Query) {
+			results[i].Err = ErrBatchItemInvalid
+			continue
+		}
+		if seen[item.Paginator] {
+			results[i].Err = ErrBatchPaginatorShared
+			continue
+		}
+		seen[item.Paginator] = true
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// isNilQuery reports whether query is nil or a nil pointer
+func isNilQuery(query Query) bool {
+	if query == nil {
+		return true
+	}
+	rv := reflect.ValueOf(query)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
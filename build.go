@@ -0,0 +1,167 @@
+package paginator
+
+import (
+	"reflect"
+
+	"github.com/iancoleman/strcase"
+	"github.com/jinzhu/inflection"
+)
+
+// BuildSQL builds cursor predicate (WHERE clause, empty if there is no
+// cursor), its args, ORDER BY expressions and limit (including the extra row
+// to detect more rows, see SetLookAhead, or Unlimited) for model without
+// executing query.
+// Table of model is its TableName() or pluralized snake case of its type
+// name as GORM does.
+// Placeholders of the predicate follow dialect of the paginator (see
+// SetDialect). Use Validate to check paging keys, since invalid keys result
+// in empty SQL.
+func (p *Paginator) BuildSQL(model interface{}) (where string, args []interface{}, order string, limit int) {
+	rt, err := toStructType(model)
+	if err != nil {
+		return
+	}
+	dry := p.dry()
+	if _, err := dry.paginate(newDryQuery(rt)); err != nil {
+		return
+	}
+	return p.dialect.bind(dry.where), dry.args, dry.orderBy, dry.fetchLimit()
+}
+
+// BuildCursorWhere builds cursor predicate and its args of configured keys
+// for model from fields decoded from cursor (see NewCursorDecoder), to be
+// composed into query which cannot be represented by Query such as UNION, CTE
+// or subquery. Direction follows cursor set on the paginator, after cursor
+// is assumed if there is none. Placeholders are left as ?.
+func (p *Paginator) BuildCursorWhere(model interface{}, fields []interface{}) (string, []interface{}, error) {
+	rt, err := toStructType(model)
+	if err != nil {
+		return "", nil, err
+	}
+	dry := p.dry()
+	dry.cursor = Cursor{}
+	if _, err := dry.paginate(newDryQuery(rt)); err != nil {
+		return "", nil, err
+	}
+	if len(fields) != len(dry.rules) {
+		return "", nil, invalidCursorError("cursor has %d values for %d keys", len(fields), len(dry.rules))
+	}
+	dry.cursor = p.cursor
+	if !dry.hasAfterCursor() && !dry.hasBeforeCursor() {
+		dry.cursor.After = new(string)
+	}
+	where, args := dry.buildCursorQuery(fields)
+	return where, args, nil
+}
+
+// BuildOrderBy builds ORDER BY expressions of configured keys for model,
+// flipped if before cursor is set on the paginator, to be reused by query
+// assembled outside of the paginator. Results are then in reversed order for
+// before cursor.
+func (p *Paginator) BuildOrderBy(model interface{}) (string, error) {
+	rt, err := toStructType(model)
+	if err != nil {
+		return "", err
+	}
+	dry := p.dry()
+	dry.cursor = Cursor{}
+	if _, err := dry.paginate(newDryQuery(rt)); err != nil {
+		return "", err
+	}
+	dry.cursor = p.cursor
+	return dry.getOrder(), nil
+}
+
+// dry returns copy of paginator to build SQL without side effects
+func (p *Paginator) dry() Paginator {
+	dry := *p
+	dry.last = nil
+	dry.keys = nil
+	dry.deferredJoin = false
+	// options running extra queries cannot be applied to dry query
+	dry.totalCount, dry.indexAdvisor, dry.explain = false, false, false
+	dry.router, dry.queryTimeout = nil, 0
+	// pages of dry run must not be cached under keys of real pages
+	dry.cache = nil
+	dry.tracer, dry.metrics, dry.logger = nil, nil, nil
+	dry.before, dry.after = nil, nil
+	return dry
+}
+
+type tabler interface {
+	TableName() string
+}
+
+// dryQuery is a Query building nothing but table name, it never executes
+type dryQuery struct {
+	out   interface{}
+	table string
+}
+
+func newDryQuery(rt reflect.Type) *dryQuery {
+	table := inflection.Plural(strcase.ToSnake(rt.Name()))
+	if t, ok := reflect.New(rt).Interface().(tabler); ok {
+		table = t.TableName()
+	}
+	return &dryQuery{out: reflect.New(reflect.SliceOf(rt)).Interface(), table: table}
+}
+
+func (q *dryQuery) Model() interface{} {
+	return q.out
+}
+
+func (q *dryQuery) Value() interface{} {
+	return q.out
+}
+
+func (q *dryQuery) Table() string {
+	return q.table
+}
+
+func (q *dryQuery) Where(query string, args ...interface{}) Query {
+	return q
+}
+
+func (q *dryQuery) Limit(int) Query {
+	return q
+}
+
+func (q *dryQuery) Order(string) Query {
+	return q
+}
+
+func (q *dryQuery) Select() Query {
+	return q
+}
+
+func (q *dryQuery) Distinct() Query {
+	return q
+}
+
+func (q *dryQuery) DistinctOn(expressions ...string) Query {
+	return q
+}
+
+func (q *dryQuery) Having(query string, args ...interface{}) Query {
+	return q
+}
+
+// UnionAll refers the union by alias
+func (q *dryQuery) UnionAll(alias string, others ...Query) Query {
+	return &dryQuery{out: q.out, table: alias}
+}
+
+// With refers the common table expression by name
+func (q *dryQuery) With(name string) Query {
+	return &dryQuery{out: q.out, table: name}
+}
+
+// Hint is applied by statement builder
+func (q *dryQuery) Hint(Hint) Query {
+	return q
+}
+
+// Wrap refers the wrapped query by alias
+func (q *dryQuery) Wrap(alias string, selects ...string) Query {
+	return &dryQuery{out: q.out, table: alias}
+}
@@ -0,0 +1,144 @@
+package paginator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cache stores serialized pages, e.g. in Redis or memory, so that hot pages
+// can be served without querying database
+type Cache interface {
+	// Get returns cached value of key, ok is false if key is missing or
+	// expired
+	Get(key string) (value []byte, ok bool, err error)
+	// Set caches value of key, which expires after ttl (no expiration if ttl
+	// is zero)
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// SetCache sets cache consulted before querying database, pages are cached by
+// cursor and fingerprint of the query (table, paging keys, order, limit,
+// filter hash, see SetFilterHash, and options deciding rows of the page) for
+// ttl. Rows of the model should survive
+// JSON round trip. Filters applied on the query are not visible to paginator,
+// so queries with different filters must set different filter hashes to not
+// share cached pages. Cache errors are treated as cache misses and reported
+// as warnings of log entry.
+func (p *Paginator) SetCache(cache Cache, ttl time.Duration) {
+	p.cache, p.cacheTTL = cache, ttl
+}
+
+// cachedPage is the serialized form of a page in cache
+type cachedPage struct {
+	Page    json.RawMessage `json:"page"`
+	After   *string         `json:"after,omitempty"`
+	Before  *string         `json:"before,omitempty"`
+	HasMore bool            `json:"has_more"`
+	Total   *int64          `json:"total,omitempty"`
+}
+
+// getCacheKey returns cache key of a page of query, which covers options
+// deciding rows of the page and their order
+func (p *Paginator) getCacheKey(query Query) string {
+	h := sha256.New()
+	snapshot := ""
+	if !p.snapshot.IsZero() {
+		snapshot = p.snapshot.UTC().Format(time.RFC3339Nano)
+	}
+	for _, part := range []string{
+		query.Table(),
+		p.getFingerprint(),
+		strconv.Itoa(p.limit),
+		derefString(p.cursor.After),
+		derefString(p.cursor.Before),
+		strconv.FormatBool(p.hasBeforeCursor()),
+		strconv.FormatBool(p.totalCount),
+		strconv.FormatBool(p.inclusive),
+		strconv.FormatBool(p.skipFlip),
+		strconv.FormatBool(p.tail),
+		string(p.lookAhead),
+		string(p.predicate),
+		strconv.FormatBool(p.distinct),
+		strings.Join(p.distinctOn, ","),
+		p.asOf.String(),
+		snapshot,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if p.offset > 0 {
+		h.Write([]byte("offset=" + strconv.Itoa(p.offset)))
+	}
+	return "paginator:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// loadCachedPage loads cached page into the out of query, it reports whether
+// page is found in cache
+func (p *Paginator) loadCachedPage(query Query) bool {
+	if p.cache == nil {
+		return false
+	}
+	p.cacheKey = p.getCacheKey(query)
+	b, ok, err := p.cache.Get(p.cacheKey)
+	if err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("cache: %s", err))
+		return false
+	}
+	if !ok {
+		return false
+	}
+	var cached cachedPage
+	if err := json.Unmarshal(b, &cached); err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("cache: %s", err))
+		return false
+	}
+	if err := json.Unmarshal(cached.Page, query.Value()); err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("cache: %s", err))
+		return false
+	}
+	p.page = query.Value()
+	p.next = Cursor{After: cached.After, Before: cached.Before}
+	p.hasMore = cached.HasMore
+	p.pageInfo.Total = cached.Total
+	if elems := reflect.ValueOf(p.page).Elem(); elems.Kind() == reflect.Slice {
+		p.count = elems.Len()
+	}
+	return true
+}
+
+// storeCachedPage caches paginated page
+func (p *Paginator) storeCachedPage() {
+	if p.cache == nil {
+		return
+	}
+	page, err := json.Marshal(p.page)
+	if err == nil {
+		var b []byte
+		b, err = json.Marshal(cachedPage{
+			Page:    page,
+			After:   p.next.After,
+			Before:  p.next.Before,
+			HasMore: p.hasMore,
+			Total:   p.pageInfo.Total,
+		})
+		if err == nil {
+			err = p.cache.Set(p.cacheKey, b, p.cacheTTL)
+		}
+	}
+	if err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("cache: %s", err))
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
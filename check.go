@@ -0,0 +1,243 @@
+package paginator
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// CheckSampleSize is number of rows sampled to check uniqueness of keys
+const CheckSampleSize = 1000
+
+// CheckDB is a database to run diagnostics of Check against, e.g. *sql.DB
+type CheckDB interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// CheckReport is report of Check
+type CheckReport struct {
+	Table string
+	Keys  []KeyCheck
+	// IndexChecked reports whether indexes of table are listed, which is
+	// supported on MySQL, Postgres and SQLite
+	IndexChecked bool
+	// Indexed reports whether some index of table leads with key columns
+	Indexed bool
+	// SampledRows is number of rows sampled to check uniqueness of keys
+	SampledRows int
+	// Duplicates is number of sampled rows whose keys equal to those of
+	// another sampled row
+	Duplicates int
+	// Problems describe each problem found, there is none if pagination is
+	// configured well
+	Problems []string
+}
+
+// KeyCheck is report of a key of Check
+type KeyCheck struct {
+	Key    string
+	Column string
+	// Exists reports whether column exists in table
+	Exists bool
+	// Rows and Nulls are numbers of rows and NULL values of column in table
+	Rows  int64
+	Nulls int64
+}
+
+// OK reports whether no problem is found
+func (r *CheckReport) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Check verifies paging keys of model on db before they hit production, e.g.
+// at startup of service: columns of keys exist, some index leads with them,
+// they are unique in a sample of CheckSampleSize rows, and NULL values of
+// keys without Nulls. Keys default to paging keys of paginator. It costs a
+// scan of table counting NULL values. An error is only returned for invalid
+// model or keys, failed diagnostics are reported as problems.
+func (p *Paginator) Check(db CheckDB, model interface{}, keys ...string) (*CheckReport, error) {
+	rt, err := toStructType(model)
+	if err != nil {
+		return nil, err
+	}
+	rules := toRules(keys)
+	if len(rules) == 0 {
+		rules = p.getRules(rt)
+	}
+	if err := validateRules(rt, rules); err != nil {
+		return nil, err
+	}
+	report := &CheckReport{Table: p.qualifyTable(newDryQuery(rt).table)}
+	var checked []Rule
+	for _, rule := range rules {
+		// expressions are not columns of table
+		if rule.SQLRepr != "" || rule.Subquery != "" || rule.Alias || len(rule.JSONPath) > 0 {
+			continue
+		}
+		key := KeyCheck{Key: rule.Key, Column: rule.column()}
+		if err := p.checkColumn(db, report.Table, key.Column); err != nil {
+			report.problemf("column %s of key %s does not exist in %s: %s", key.Column, key.Key, report.Table, err)
+		} else {
+			key.Exists = true
+			checked = append(checked, rule)
+		}
+		report.Keys = append(report.Keys, key)
+	}
+	if len(checked) < len(report.Keys) {
+		return report, nil
+	}
+	columns := make([]string, len(report.Keys))
+	for i, key := range report.Keys {
+		columns[i] = key.Column
+	}
+	p.checkIndex(db, report, columns)
+	p.checkUniqueness(db, report, columns)
+	p.checkNulls(db, report, checked)
+	return report, nil
+}
+
+func (r *CheckReport) problemf(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// quoteColumns returns quoted columns separated by comma
+func (p *Paginator) quoteColumns(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = p.dialect.quote(column)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// checkColumn selects no row of column to check it exists in table
+func (p *Paginator) checkColumn(db CheckDB, table, column string) error {
+	rows, err := db.Query(fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0", p.dialect.quote(column), p.dialect.quoteTable(table)))
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+func (p *Paginator) checkIndex(db CheckDB, report *CheckReport, columns []string) {
+	var query string
+	switch p.dialect {
+	case MySQL:
+		query = MySQLIndexesSQL
+	case Postgres:
+		query = PostgresIndexesSQL
+	case SQLite:
+		query = SQLiteIndexesSQL
+	default:
+		return
+	}
+	// catalogs are queried by table without schema
+	table := report.Table[strings.LastIndex(report.Table, ".")+1:]
+	rows, err := db.Query(p.dialect.bind(query), table)
+	if err != nil {
+		report.problemf("cannot list indexes of %s: %s", report.Table, err)
+		return
+	}
+	defer rows.Close()
+	indexes := make(map[string][]string)
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			report.problemf("cannot list indexes of %s: %s", report.Table, err)
+			return
+		}
+		indexes[name] = append(indexes[name], column)
+	}
+	report.IndexChecked = true
+	for _, indexColumns := range indexes {
+		if hasPrefixColumns(indexColumns, columns) {
+			report.Indexed = true
+			return
+		}
+	}
+	report.problemf("no index of %s leads with paging keys (%s)", report.Table, strings.Join(columns, ", "))
+}
+
+// checkUniqueness counts sampled rows whose keys equal to those of previous
+// row in order of keys
+func (p *Paginator) checkUniqueness(db CheckDB, report *CheckReport, columns []string) {
+	quoted := p.quoteColumns(columns)
+	rows, err := db.Query(fmt.Sprintf(
+		"SELECT %s FROM %s ORDER BY %s %s", quoted, p.dialect.quoteTable(report.Table), quoted, p.dialect.limit(CheckSampleSize),
+	))
+	if err != nil {
+		report.problemf("cannot sample keys of %s: %s", report.Table, err)
+		return
+	}
+	defer rows.Close()
+	var prev []sql.NullString
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			report.problemf("cannot sample keys of %s: %s", report.Table, err)
+			return
+		}
+		if prev != nil && equalNullStrings(prev, values) {
+			report.Duplicates++
+		}
+		prev = values
+		report.SampledRows++
+	}
+	if err := rows.Err(); err != nil {
+		report.problemf("cannot sample keys of %s: %s", report.Table, err)
+		return
+	}
+	if report.Duplicates > 0 {
+		report.problemf("paging keys (%s) are not unique, %d of %d sampled rows are duplicates",
+			strings.Join(columns, ", "), report.Duplicates, report.SampledRows)
+	}
+}
+
+func equalNullStrings(a, b []sql.NullString) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkNulls counts rows and NULL values of each key column
+func (p *Paginator) checkNulls(db CheckDB, report *CheckReport, rules []Rule) {
+	counts := make([]string, len(report.Keys))
+	for i, key := range report.Keys {
+		counts[i] = fmt.Sprintf("COUNT(%s)", p.dialect.quote(key.Column))
+	}
+	rows, err := db.Query(fmt.Sprintf("SELECT COUNT(*), %s FROM %s", strings.Join(counts, ", "), p.dialect.quoteTable(report.Table)))
+	if err != nil {
+		report.problemf("cannot count NULL values of %s: %s", report.Table, err)
+		return
+	}
+	defer rows.Close()
+	var total int64
+	nonNulls := make([]int64, len(report.Keys))
+	dest := []interface{}{&total}
+	for i := range nonNulls {
+		dest = append(dest, &nonNulls[i])
+	}
+	if !rows.Next() {
+		err = rows.Err()
+	} else {
+		err = rows.Scan(dest...)
+	}
+	if err != nil {
+		report.problemf("cannot count NULL values of %s: %s", report.Table, err)
+		return
+	}
+	for i := range report.Keys {
+		key := &report.Keys[i]
+		key.Rows, key.Nulls = total, total-nonNulls[i]
+		if key.Nulls > 0 && rules[i].Nulls == "" {
+			report.problemf("key %s has %d NULL values of %d rows, which are skipped unless Nulls of the key is set",
+				key.Key, key.Nulls, key.Rows)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package chi binds paging query parameters of go-chi requests to paginator,
+// its middleware is a plain net/http middleware to be used by chi.Router.Use
+package chi
+
+import (
+	"context"
+	"net/http"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/openapi"
+)
+
+// Errors for binding paging parameters
+var (
+	ErrInvalidLimit = paginator.ErrInvalidLimit
+	ErrInvalidOrder = paginator.ErrInvalidOrder
+)
+
+// Options of middleware
+type Options struct {
+	// New creates paginator of each request with keys and other options,
+	// default is paginator.New
+	New func(r *http.Request) *paginator.Paginator
+	// MaxLimit caps limit of requests, no cap if it is zero
+	MaxLimit int
+	// LinkHeader sets whether to write Link header with next and prev page
+	// links to responses
+	LinkHeader bool
+	// ErrorHandler writes response of invalid parameters, default responds
+	// 400 Bad Request with error message
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type contextKey struct{}
+
+// Middleware parses after, before, limit and order query parameters into a
+// paginator stored in request context (see FromContext)
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := Bind(r, opts)
+			if err != nil {
+				if opts.ErrorHandler != nil {
+					opts.ErrorHandler(w, r, err)
+				} else {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				}
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), contextKey{}, p))
+			if opts.LinkHeader {
+				w = &linkWriter{ResponseWriter: w, r: r, p: p}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// FromContext returns paginator stored by middleware, it is nil if there is
+// none
+func FromContext(ctx context.Context) *paginator.Paginator {
+	p, _ := ctx.Value(contextKey{}).(*paginator.Paginator)
+	return p
+}
+
+// Bind creates paginator by opts and binds paging query parameters of r to
+// it
+func Bind(r *http.Request, opts Options) (*paginator.Paginator, error) {
+	p := paginator.New()
+	if opts.New != nil {
+		p = opts.New(r)
+	}
+	if err := openapi.Bind(p, r.URL.Query(), opts.MaxLimit); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Links returns Link header value with next and prev page links of r by
+// paginated page of p, it is empty if there is neither
+func Links(r *http.Request, p *paginator.Paginator) string {
+	return openapi.Links(r.URL.Path, r.URL.Query(), p)
+}
+
+// linkWriter writes Link header before the response is written
+type linkWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	p       *paginator.Paginator
+	written bool
+}
+
+func (w *linkWriter) WriteHeader(status int) {
+	if !w.written {
+		w.written = true
+		if links := Links(w.r, w.p); links != "" {
+			w.Header().Add("Link", links)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *linkWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
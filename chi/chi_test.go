@@ -0,0 +1,82 @@
+package chi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+type order struct {
+	ID int
+}
+
+type response struct {
+	Items  []order          `json:"items"`
+	Paging paginator.Paging `json:"paging"`
+}
+
+func serve(opts Options, target string) *httptest.ResponseRecorder {
+	rows := []order{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+	handler := Middleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := FromContext(r.Context())
+		var out []order
+		if _, err := p.Paginate(paginatortest.NewQuery("orders", rows, &out)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(response{Items: out, Paging: p.GetPaging()})
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func decodeIDs(t *testing.T, w *httptest.ResponseRecorder) []int {
+	var page response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	ids := make([]int, len(page.Items))
+	for i, o := range page.Items {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func TestMiddleware(t *testing.T) {
+	opts := Options{MaxLimit: 2, LinkHeader: true}
+	w := serve(opts, "/orders?limit=10&order=asc&status=paid")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{1, 2}, decodeIDs(t, w))
+
+	link := w.Header().Get("Link")
+	assert.True(t, strings.HasPrefix(link, "</orders?after="), link)
+	assert.Contains(t, link, `status=paid`)
+	assert.Contains(t, link, `rel="next"`)
+	assert.NotContains(t, link, `rel="prev"`)
+
+	next := strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+	w = serve(opts, next)
+	assert.Equal(t, []int{3, 4}, decodeIDs(t, w))
+	assert.Contains(t, w.Header().Get("Link"), `rel="prev"`)
+}
+
+func TestMiddlewareShouldRejectInvalidParams(t *testing.T) {
+	w := serve(Options{}, "/orders?limit=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), ErrInvalidLimit.Error())
+
+	var handled error
+	w = serve(Options{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}}, "/orders?order=up")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.True(t, errors.Is(handled, ErrInvalidOrder))
+}
@@ -0,0 +1,21 @@
+package paginator
+
+import "time"
+
+// Clone returns copy of configuration of paginator without state of requests,
+// i.e. cursors, offset, snapshot time and result of paginated page, so that a
+// template paginator configured at startup is cloned for each request.
+// Setters of the clone, e.g. SetKeys appending keys, affect neither the
+// template nor other clones.
+func (p *Paginator) Clone() *Paginator {
+	c := p.newRun()
+	c.last = new(lastPage)
+	c.cursor, c.offset, c.snapshot = Cursor{}, 0, time.Time{}
+	c.distinctOn = append([]string(nil), p.distinctOn...)
+	c.union = append([]Query(nil), p.union...)
+	c.before = append([]BeforePaginateHook(nil), p.before...)
+	c.after = append([]AfterPaginateHook(nil), p.after...)
+	c.transform = append([]TransformHook(nil), p.transform...)
+	c.legacy = append([]LegacyDecoder(nil), p.legacy...)
+	return c
+}
@@ -0,0 +1,12 @@
+package paginator
+
+// SetCursorCodec sets custom encoder and decoder of cursor tokens, e.g. an
+// org-wide token format, predicates and orders are still built by paginator.
+// Encoder should encode values of all paging keys including tie-breaker, and
+// decoder should decode them in the same order (see NewCursorEncoder and
+// NewCursorDecoder for default ones to wrap). Cursor options such as
+// fingerprint, TTL, URL-safe encoding and compression are not applied to
+// custom tokens.
+func (p *Paginator) SetCursorCodec(encoder CursorEncoder, decoder CursorDecoder) {
+	p.encoder, p.decoder = encoder, decoder
+}
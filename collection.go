@@ -0,0 +1,93 @@
+package paginator
+
+import "reflect"
+
+// Collection is implemented by destinations of pages, e.g. pointer to a named
+// slice of model, so that pages are post-processed and their cursors are
+// encoded without reflection. Pages are post-processed by reflection when
+// transforms (see SetTransform) or custom cursor codec are set.
+type Collection interface {
+	// Len returns number of rows
+	Len() int
+	// Truncate keeps the first n rows
+	Truncate(n int)
+	// Reverse reverses order of rows
+	Reverse()
+	// KeyOf returns value of paging key, a field name of model, of row i
+	KeyOf(i int, key string) interface{}
+}
+
+// keyValues are values of paging keys of a row in order of paging rules
+type keyValues []interface{}
+
+// postProcessCollection post-processes page c as postProcess does
+func (p *Paginator) postProcessCollection(c Collection, hasMore bool) error {
+	if !p.unlimited() && c.Len() > p.limit {
+		c.Truncate(c.Len() - 1)
+	}
+	p.count, p.hasMore = c.Len(), hasMore
+	if p.hasBeforeCursor() && !p.skipFlip {
+		c.Reverse()
+	}
+	first, last := 0, c.Len()-1
+	if p.hasBeforeCursor() && p.skipFlip {
+		first, last = last, first
+	}
+	if err := p.checkZeroKeys(p.keyValuesOf(c, first), p.keyValuesOf(c, last)); err != nil {
+		return err
+	}
+	encoder := p.newKeyEncoder()
+	if p.hasBeforeCursor() || hasMore || p.tail {
+		cursor := encoder.Encode(p.keyValuesOf(c, last))
+		p.next.After = &cursor
+	}
+	if p.hasPrevious(hasMore) {
+		cursor := encoder.Encode(p.keyValuesOf(c, first))
+		p.next.Before = &cursor
+	}
+	return nil
+}
+
+// keyValuesOf returns values of paging keys of row i of c
+func (p *Paginator) keyValuesOf(c Collection, i int) keyValues {
+	values := make(keyValues, len(p.rules))
+	for j, rule := range p.rules {
+		values[j] = c.KeyOf(i, rule.Key)
+	}
+	return values
+}
+
+// pageLen returns number of rows of page out
+func pageLen(out interface{}) int {
+	if c, ok := out.(Collection); ok {
+		return c.Len()
+	}
+	return reflect.ValueOf(out).Elem().Len()
+}
+
+// RowAppender is a destination of pages which is not a slice, e.g. a domain
+// wrapper of result set, rows of page are appended into it by PaginateInto
+type RowAppender interface {
+	// Append appends row, an element of the out of query (model or pointer
+	// to model)
+	Append(row interface{})
+}
+
+// PaginateInto paginates query as Paginate does and appends rows of the
+// page into out in paging order, so that domain wrappers are filled without
+// copying the out of query by callers
+func (p *Paginator) PaginateInto(query Query, out RowAppender) (Query, error) {
+	run, result, err := p.paginateRun(query)
+	if err != nil {
+		return result, err
+	}
+	elems := reflect.ValueOf(run.page)
+	if elems.Kind() != reflect.Ptr || elems.Elem().Kind() != reflect.Slice {
+		return result, nil
+	}
+	elems = elems.Elem()
+	for i := 0; i < elems.Len(); i++ {
+		out.Append(elems.Index(i).Interface())
+	}
+	return result, nil
+}
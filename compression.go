@@ -0,0 +1,57 @@
+package paginator
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+// compressedMarker leads compressed cursor payload, it never leads JSON or
+// old encoding which are text
+const compressedMarker byte = 0x01
+
+// maxDecompressedLength bounds size of decompressed cursor payload
+const maxDecompressedLength = 64 << 10
+
+var errDecompressedTooLarge = errors.New("decompressed cursor is too large")
+
+// SetCursorCompression sets whether to compress cursor payload by flate,
+// which shortens cursors carrying several long string keys. Payload is only
+// compressed if it gets shorter, and uncompressed cursors are accepted
+// regardless.
+func (p *Paginator) SetCursorCompression(enabled bool) {
+	p.compression = enabled
+}
+
+// compress returns compressed b led by marker, or b itself if compressed one
+// is not shorter
+func compress(b []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(compressedMarker)
+	w, _ := flate.NewWriter(&buf, flate.BestCompression)
+	w.Write(b)
+	w.Close()
+	if buf.Len() >= len(b) {
+		return b
+	}
+	return buf.Bytes()
+}
+
+// decompress returns decompressed b if it is led by marker, or b itself
+func decompress(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != compressedMarker {
+		return b, nil
+	}
+	r := flate.NewReader(bytes.NewReader(b[1:]))
+	defer r.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedLength+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxDecompressedLength {
+		return nil, errDecompressedTooLarge
+	}
+	return b, nil
+}
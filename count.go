@@ -0,0 +1,58 @@
+package paginator
+
+import "errors"
+
+// CountQuery is a Query supporting counting its rows, which is required for
+// total count mode
+type CountQuery interface {
+	Query
+	// Counter returns a function counting rows matching the query as it is
+	// now, the function runs concurrently with the page query, so it must not
+	// share state with the query (e.g. it should run on its own session)
+	Counter() func() (int64, error)
+}
+
+// ErrCountNotSupported is returned when query does not implement CountQuery in
+// total count mode
+var ErrCountNotSupported = errors.New("query should implement CountQuery to count total rows")
+
+// SetTotalCount sets whether to count total rows matching the query
+// regardless of cursor, which is set to Total of GetPageInfo. The count query
+// runs concurrently with the page query.
+func (p *Paginator) SetTotalCount(enabled bool) {
+	p.totalCount = enabled
+}
+
+// countTotal starts counting rows of base query, the returned function waits
+// for the count and sets it to page info
+func (p *Paginator) countTotal(base Query) (func() error, error) {
+	if !p.totalCount {
+		return func() error { return nil }, nil
+	}
+	base, err := p.route(base, QueryCount)
+	if err != nil {
+		return nil, err
+	}
+	cq, ok := base.(CountQuery)
+	if !ok {
+		return nil, ErrCountNotSupported
+	}
+	type result struct {
+		total int64
+		err   error
+	}
+	count := cq.Counter()
+	done := make(chan result, 1)
+	go func() {
+		total, err := count()
+		done <- result{total, err}
+	}()
+	return func() error {
+		r := <-done
+		if r.err != nil {
+			return r.err
+		}
+		p.pageInfo.Total = &r.total
+		return nil
+	}, nil
+}
@@ -0,0 +1,42 @@
+package paginator
+
+import "errors"
+
+// CTEQuery is a Query which can be defined as common table expression, which
+// is required for CTE mode
+type CTEQuery interface {
+	Query
+	// With returns a new query selecting all columns from this query defined
+	// as common table expression named name, i.e.
+	// WITH name AS (...) SELECT * FROM name
+	With(name string) Query
+}
+
+// ErrCTENotSupported is returned when query does not implement CTEQuery in
+// CTE mode
+var ErrCTENotSupported = errors.New("query should implement CTEQuery to paginate common table expressions")
+
+// SetCTE sets name of common table expression the paginated query is defined
+// as, cursor predicate, ordering and limit are then placed on the outer query
+// selecting from it, i.e.
+// WITH name AS (...) SELECT * FROM name WHERE ... ORDER BY ... LIMIT ...,
+// so that analytical queries can be paginated by their output columns. Keys
+// refer to columns of the CTE, expressions of keys (see Rule.SQLRepr) are
+// selected as key columns by the query.
+func (p *Paginator) SetCTE(name string) {
+	p.cte = name
+}
+
+func (p *Paginator) appendCTE(query Query) (Query, error) {
+	if p.cte == "" {
+		return query, nil
+	}
+	cq, ok := query.(CTEQuery)
+	if !ok {
+		return query, ErrCTENotSupported
+	}
+	query = cq.With(p.cte)
+	p.wrapped = true
+	p.initTableKeys(query)
+	return query, nil
+}
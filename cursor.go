@@ -1,7 +1,103 @@
 package paginator
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // Cursor cursor data
 type Cursor struct {
 	After  *string `json:"after" query:"after"`
 	Before *string `json:"before" query:"before"`
 }
+
+// CursorJSON is JSON representation of Cursor, cursors are marshaled by
+// zero CursorJSON, i.e. fields after and before with null of missing
+// cursors, unless by Marshal of another CursorJSON or by FormattedCursor
+type CursorJSON struct {
+	// After is field name of after cursor, e.g. next, it is after by default
+	After string
+	// Before is field name of before cursor, e.g. prev, it is before by
+	// default
+	Before string
+	// OmitEmpty omits fields of missing cursors instead of marshaling null
+	OmitEmpty bool
+}
+
+// names returns field names of after and before cursor
+func (c CursorJSON) names() (after, before string) {
+	after, before = c.After, c.Before
+	if after == "" {
+		after = "after"
+	}
+	if before == "" {
+		before = "before"
+	}
+	return after, before
+}
+
+// MarshalJSON marshals cursor by zero CursorJSON
+func (c Cursor) MarshalJSON() ([]byte, error) {
+	return CursorJSON{}.Marshal(c)
+}
+
+// UnmarshalJSON unmarshals cursor by zero CursorJSON
+func (c *Cursor) UnmarshalJSON(data []byte) error {
+	return CursorJSON{}.Unmarshal(data, c)
+}
+
+// Marshal marshals cursor by the format
+func (f CursorJSON) Marshal(c Cursor) ([]byte, error) {
+	after, before := f.names()
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{{after, c.After}, {before, c.Before}} {
+		if field.value == nil && f.OmitEmpty {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(field.name)
+		value, _ := json.Marshal(field.value)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Unmarshal unmarshals cursor by the format
+func (f CursorJSON) Unmarshal(data []byte, c *Cursor) error {
+	var fields map[string]*string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	after, before := f.names()
+	c.After, c.Before = fields[after], fields[before]
+	return nil
+}
+
+// FormattedCursor is cursor marshaled by its own format, it can be embedded
+// into responses in place of Cursor, e.g.
+//
+//	FormattedCursor{Cursor: p.GetNextCursor(), Format: CursorJSON{After: "next"}}
+type FormattedCursor struct {
+	Cursor
+	Format CursorJSON
+}
+
+// MarshalJSON marshals cursor by its format
+func (c FormattedCursor) MarshalJSON() ([]byte, error) {
+	return c.Format.Marshal(c.Cursor)
+}
+
+// UnmarshalJSON unmarshals cursor by its format, which should be set before
+// unmarshaling
+func (c *FormattedCursor) UnmarshalJSON(data []byte) error {
+	return c.Format.Unmarshal(data, &c.Cursor)
+}
@@ -2,21 +2,28 @@ package paginator
 
 import (
 	"bytes"
-	"encoding/base64"
+	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"reflect"
 	"strings"
 	"time"
 )
 
-// CursorDecoder decoder for cursor
+// CursorDecoder decoder for cursor, which decodes cursor token into values of
+// keys typed as fields of model, nil is returned if cursor is invalid
 type CursorDecoder interface {
 	Decode(cursor string) []interface{}
 }
 
 // NewCursorDecoder creates cursor decoder
 func NewCursorDecoder(ref interface{}, keys ...string) (CursorDecoder, error) {
+	return newCursorDecoder(ref, toRules(keys)...)
+}
+
+func newCursorDecoder(ref interface{}, rules ...Rule) (*cursorDecoder, error) {
 	// Get the reflected type
 	rt := toReflectValue(ref).Type()
 
@@ -25,12 +32,12 @@ func NewCursorDecoder(ref interface{}, keys ...string) (CursorDecoder, error) {
 		rt = rt.Elem()
 	}
 
-	if rt.Kind() != reflect.Struct {
+	if rt.Kind() != reflect.Struct && !isMapType(rt) {
 		// element of out must be struct, if not, just pass it to gorm to handle the error
 		return nil, ErrInvalidDecodeReference
 	}
 
-	return &cursorDecoder{ref: rt, keys: keys}, nil
+	return &cursorDecoder{ref: rt, rules: rules}, nil
 }
 
 // Errors for decoders
@@ -38,77 +45,412 @@ var (
 	ErrInvalidDecodeReference = errors.New("decode reference should be struct")
 	ErrInvalidField           = errors.New("invalid field")
 	ErrInvalidOldField        = errors.New("invalid old field")
+	ErrInvalidCursor          = errors.New("invalid cursor")
 )
 
+// maxCursorLength is the max length of cursor to be decoded, which bounds
+// resources spent on adversarial input
+const maxCursorLength = 16 << 10
+
 type cursorDecoder struct {
 	// ref is the reference objects reflected type
-	ref  reflect.Type
-	keys []string
+	ref   reflect.Type
+	rules []Rule
+	// fingerprint is fingerprint of query following values, it is not
+	// expected if empty
+	fingerprint string
+	// ttl is TTL of cursor with issued-at time following values, issued-at
+	// time is not expected if ttl is zero
+	ttl time.Duration
+	now func() time.Time
+	// legacy decoders are tried in order if cursor cannot be decoded
+	legacy []LegacyDecoder
+	// verbose embeds invalid values into errors
+	verbose bool
 }
 
 func (d *cursorDecoder) Decode(cursor string) []interface{} {
-	b, err := base64.StdEncoding.DecodeString(cursor)
-	// @TODO: return proper error
+	fields, err := d.decode(cursor)
 	if err != nil {
 		return nil
 	}
+	return fields
+}
+
+// decode decodes cursor into values of keys, an error wrapping
+// ErrInvalidCursor is returned if cursor is malformed or does not match keys
+func (d *cursorDecoder) decode(cursor string) (fields []interface{}, err error) {
+	// custom scanners must not crash paginator on adversarial input
+	defer func() {
+		if r := recover(); r != nil {
+			fields, err = nil, invalidCursorError("cannot decode cursor")
+		}
+	}()
+	if len(cursor) > maxCursorLength {
+		return nil, invalidCursorError("cursor exceeds %d bytes", maxCursorLength)
+	}
+	if fields, err = d.decodeCursor(cursor); err == nil || !errors.Is(err, ErrInvalidCursor) {
+		return fields, err
+	}
+	for _, legacy := range d.legacy {
+		b, legacyErr := legacy(cursor)
+		if legacyErr != nil {
+			continue
+		}
+		// legacy cursors carry neither fingerprint nor issued-at time
+		if legacyFields, legacyErr := d.decodeValues(b, false); legacyErr == nil {
+			return legacyFields, nil
+		}
+	}
+	return nil, err
+}
+
+// decodeCursor decodes cursor of current format
+func (d *cursorDecoder) decodeCursor(cursor string) ([]interface{}, error) {
+	b, err := decodeBase64(cursor)
+	if err != nil {
+		return nil, invalidCursorError("cursor is not base64 encoded")
+	}
+	if b, err = decompress(b); err != nil {
+		return nil, invalidCursorError("cursor cannot be decompressed")
+	}
+	if isCompact(b) {
+		if b, err = d.decodeCompact(b); err != nil {
+			return nil, err
+		}
+	}
+	return d.decodeValues(b, true)
+}
 
+// decodeValues decodes payload of cursor into values of keys, fingerprint
+// and issued-at time following values are checked if withMeta
+func (d *cursorDecoder) decodeValues(b []byte, withMeta bool) ([]interface{}, error) {
 	// If it is not valid JSON, we should attempt to use the old decoding
 	// technique for backwards compatability.
 	if !json.Valid(b) {
-		return decodeOld(b)
+		fields := decodeOld(b, d.oldTextKeys())
+		if len(fields) != len(d.rules) {
+			return nil, invalidCursorError("cursor has %d values for %d keys", len(fields), len(d.rules))
+		}
+		return fields, nil
 	}
 
 	// Create a JSON decoder
 	dec := json.NewDecoder(bytes.NewBuffer(b))
 
 	// Read open bracket
-	_, err = dec.Token()
-	if err != nil {
-		return nil
+	if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+		return nil, invalidCursorError("cursor is not an array")
 	}
 
 	// Iterate over each key and decode the value
-	result := make([]interface{}, len(d.keys))
-	for i, key := range d.keys {
-		// Find the field in the struct
-		field, ok := d.ref.FieldByName(key)
+	result := make([]interface{}, len(d.rules))
+	for i, rule := range d.rules {
+		if !dec.More() {
+			return nil, invalidCursorError("cursor has %d values for %d keys", i, len(d.rules))
+		}
+		// raw value is kept for errors of verbose mode
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, invalidCursorError("cannot decode value of key %s", rule.Key)
+		}
+		v, ok := d.decodeValue(json.NewDecoder(bytes.NewReader(raw)), rule)
 		if !ok {
-			return nil
+			return nil, d.invalidValueError(rule.Key, raw, "cannot decode value of key %s")
+		}
+		if rule.Zero == ZeroReject && isZeroKey(reflect.ValueOf(v)) {
+			return nil, d.invalidValueError(rule.Key, raw, "value of key %s is zero")
+		}
+		result[i] = v
+	}
+	if d.fingerprint != "" && withMeta {
+		if err := d.decodeFingerprint(dec); err != nil {
+			return nil, err
+		}
+	}
+	if d.ttl > 0 && withMeta {
+		if err := d.decodeIssuedAt(dec); err != nil {
+			return nil, err
 		}
+	}
+	if dec.More() {
+		return nil, invalidCursorError("cursor has more values than %d keys", len(d.rules))
+	}
+	return result, nil
+}
+
+// decodeFingerprint decodes fingerprint following values and checks it
+// against fingerprint of current query
+func (d *cursorDecoder) decodeFingerprint(dec *json.Decoder) error {
+	var fingerprint string
+	if !dec.More() || dec.Decode(&fingerprint) != nil {
+		return invalidCursorError("cursor has no fingerprint")
+	}
+	if fingerprint != d.fingerprint {
+		return ErrCursorFingerprintMismatch
+	}
+	return nil
+}
+
+// decodeIssuedAt decodes issued-at time following values and checks it
+// against ttl
+func (d *cursorDecoder) decodeIssuedAt(dec *json.Decoder) error {
+	var issuedAt int64
+	if !dec.More() || dec.Decode(&issuedAt) != nil {
+		return invalidCursorError("cursor has no issued-at time")
+	}
+	if d.now().Sub(time.Unix(issuedAt, 0)) > d.ttl {
+		return ErrCursorExpired
+	}
+	return nil
+}
 
-		// Get a copy of the field. JSON decoding requires a pointer but we want
-		// to return the same type as that of the referenced object. Therefore
-		// capture whether the value is a pointer or not and we will dereference
-		// the unmarshalled value before returning it if it is not originally a
-		// pointer.
-		isPtr := false
-		objType := field.Type
-		if objType.Kind() == reflect.Ptr {
-			isPtr = true
-			objType = objType.Elem()
+// oldTextKeys reports whether value of each key is text in old encoding,
+// which are values other than numbers, booleans and time
+func (d *cursorDecoder) oldTextKeys() []bool {
+	text := make([]bool, len(d.rules))
+	for i, rule := range d.rules {
+		text[i] = true
+		if rule.DecodeValue != nil || isMapType(d.ref) || len(rule.JSONPath) > 0 {
+			continue
+		}
+		field, ok := d.ref.FieldByName(rule.Key)
+		if !ok {
+			continue
+		}
+		t := field.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
 		}
-		v := reflect.New(objType).Interface()
+		switch t.Kind() {
+		case reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			text[i] = false
+		default:
+			text[i] = t != reflect.TypeOf(time.Time{})
+		}
+	}
+	return text
+}
 
-		// Decode the value
-		if err := dec.Decode(&v); err != nil {
-			return nil
+// decodeValue decodes next value of rule from dec
+func (d *cursorDecoder) decodeValue(dec *json.Decoder, rule Rule) (interface{}, bool) {
+	// Transformed values are decoded as generic JSON values
+	if rule.DecodeValue != nil {
+		v, ok := decodeJSONValue(dec)
+		if !ok {
+			return nil, false
 		}
+		v, err := rule.DecodeValue(v)
+		return v, err == nil
+	}
+
+	// Values of map entries are decoded as generic JSON values
+	if isMapType(d.ref) {
+		return decodeMapValue(dec)
+	}
+
+	// Find the field in the struct
+	field, ok := d.ref.FieldByName(rule.Key)
+	if !ok {
+		return nil, false
+	}
 
-		// Need to dereference since everything is now a pointer
+	// Values extracted from JSON are decoded as generic JSON values
+	if len(rule.JSONPath) > 0 {
+		return decodeJSONValue(dec)
+	}
+
+	// Get a copy of the field. JSON decoding requires a pointer but we want
+	// to return the same type as that of the referenced object. Therefore
+	// capture whether the value is a pointer or not and we will dereference
+	// the unmarshalled value before returning it if it is not originally a
+	// pointer.
+	isPtr := false
+	objType := field.Type
+	if objType.Kind() == reflect.Ptr {
+		isPtr = true
+		objType = objType.Elem()
+	}
+	// Protobuf timestamps are decoded from their time
+	if isTimestamp(objType) {
+		return decodeTimestamp(dec, objType, isPtr)
+	}
+
+	// Big floats are decoded from their exact mantissa form
+	if isBigFloat(objType) {
+		return decodeBigFloat(dec, isPtr)
+	}
+
+	// Byte arrays are decoded from their raw bytes
+	if isByteArray(objType) {
+		return decodeByteArray(dec, objType, isPtr)
+	}
+
+	// Custom types are decoded from their driver value through sql.Scanner
+	if isValuerScanner(objType) {
+		return decodeScanner(dec, objType, isPtr)
+	}
+
+	v := reflect.New(objType).Interface()
+
+	// Decode the value
+	if err := dec.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	// Need to dereference since everything is now a pointer, NULL values of
+	// non-pointer fields (see ZeroAsNull) are left nil
+	if !isPtr && v != nil {
+		v = reflect.ValueOf(v).Elem().Interface()
+	}
+	return normalizeTime(v), true
+}
+
+// decodeJSONValue decodes next generic JSON value from dec, numbers are kept
+// as json.Number to be lossless
+func decodeJSONValue(dec *json.Decoder) (interface{}, bool) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, false
+	}
+	v, err := unmarshalJSONValue(raw)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// decodeMapValue decodes next generic JSON value from dec, strings of RFC
+// 3339 format are decoded as times
+func decodeMapValue(dec *json.Decoder) (interface{}, bool) {
+	v, ok := decodeJSONValue(dec)
+	if str, isStr := v.(string); isStr && rfc3339.MatchString(str) {
+		if t, err := time.Parse(time.RFC3339Nano, str); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return v, ok
+}
+
+// decodeBigFloat decodes next big.Float from dec with enough precision to hold
+// the encoded mantissa, the returned value is a pointer when isPtr is true.
+func decodeBigFloat(dec *json.Decoder, isPtr bool) (interface{}, bool) {
+	var str *string
+	if err := dec.Decode(&str); err != nil {
+		return nil, false
+	}
+	if str == nil {
 		if !isPtr {
-			v = reflect.ValueOf(v).Elem().Interface()
+			return nil, false
 		}
-		result[i] = v
+		return (*big.Float)(nil), true
 	}
+	// each hexadecimal digit of mantissa holds 4 bits
+	prec := uint(4 * len(*str))
+	if prec < 64 {
+		prec = 64
+	}
+	f, _, err := big.ParseFloat(*str, 0, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+	// values of big.Float must not be copied, query args are dereferenced
+	// by toQueryArg
+	return f, true
+}
 
-	return result
+// decodeByteArray decodes next base64 encoded bytes from dec into a new value
+// of byte array type t, the returned value is a pointer to t when isPtr is true.
+func decodeByteArray(dec *json.Decoder, t reflect.Type, isPtr bool) (interface{}, bool) {
+	var b *[]byte
+	if err := dec.Decode(&b); err != nil {
+		return nil, false
+	}
+	if b == nil {
+		if !isPtr {
+			return nil, false
+		}
+		return reflect.Zero(reflect.PtrTo(t)).Interface(), true
+	}
+	if len(*b) != t.Len() {
+		return nil, false
+	}
+	pv := reflect.New(t)
+	reflect.Copy(pv.Elem(), reflect.ValueOf(*b))
+	if isPtr {
+		return pv.Interface(), true
+	}
+	return pv.Elem().Interface(), true
+}
+
+// decodeScanner decodes next driver value from dec and scans it into a new
+// value of type t, the returned value is a pointer to t when isPtr is true.
+func decodeScanner(dec *json.Decoder, t reflect.Type, isPtr bool) (interface{}, bool) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, false
+	}
+	if isPtr && string(raw) == "null" {
+		return reflect.Zero(reflect.PtrTo(t)).Interface(), true
+	}
+	value, err := toDriverValue(raw)
+	if err != nil {
+		return nil, false
+	}
+	pv := reflect.New(t)
+	scanner := pv.Interface().(sql.Scanner)
+	if err := scanner.Scan(value); err != nil {
+		// time-like strings are retried as plain strings
+		if _, ok := value.(time.Time); !ok {
+			return nil, false
+		}
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil || scanner.Scan(str) != nil {
+			return nil, false
+		}
+	}
+	if isPtr {
+		return pv.Interface(), true
+	}
+	return pv.Elem().Interface(), true
+}
+
+// toDriverValue converts marshaled driver value back to one of the types
+// defined by driver.Value.
+func toDriverValue(raw json.RawMessage) (driver.Value, error) {
+	v, err := unmarshalJSONValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case string:
+		if rfc3339.MatchString(v) {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t, nil
+			}
+		}
+		return v, nil
+	case bool, nil:
+		return v, nil
+	default:
+		return nil, ErrInvalidField
+	}
 }
 
 /* deprecated */
 
-func decodeOld(b []byte) []interface{} {
-	fieldsWithType := strings.Split(string(b), ",")
+// decodeOld decodes old encoding of values of keys, text reports whether
+// value of each key is text (see splitOld), keys are unknown if it is nil
+func decodeOld(b []byte, text []bool) []interface{} {
+	fieldsWithType := splitOld(string(b), text)
 	fields := make([]interface{}, len(fieldsWithType))
 	for i, fieldWithType := range fieldsWithType {
 		v, err := revert(fieldWithType)
@@ -122,6 +464,53 @@ func decodeOld(b []byte) []interface{} {
 	return fields
 }
 
+// splitOld splits old encoding into typed fields of keys, which are values
+// followed by type suffix and joined by the separator. Old encoding has no
+// escaping, so that its tokens are fields ending at type suffix followed by
+// the separator, and tokens are joined back into text values of keys as long
+// as there are tokens left for the other keys. Values of other keys, e.g.
+// numbers and time, never contain the separator. If text is nil, each token
+// is a field.
+func splitOld(s string, text []bool) []string {
+	var tokens []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ',' && hasFieldType(s[start:i]) {
+			tokens = append(tokens, s[start:i])
+			start = i + 1
+		}
+	}
+	tokens = append(tokens, s[start:])
+	if len(tokens) <= len(text) || !hasText(text) {
+		return tokens
+	}
+	fields := make([]string, 0, len(text))
+	for i := range text {
+		n := 1
+		if text[i] {
+			// text value takes tokens not needed by the rest of keys
+			n = len(tokens) - (len(text) - i - 1)
+		}
+		fields = append(fields, strings.Join(tokens[:n], ","))
+		tokens = tokens[n:]
+	}
+	return fields
+}
+
+func hasText(text []bool) bool {
+	for _, t := range text {
+		if t {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFieldType(fieldWithType string) bool {
+	return strings.HasSuffix(fieldWithType, "?"+string(fieldString)) ||
+		strings.HasSuffix(fieldWithType, "?"+string(fieldTime))
+}
+
 type fieldType string
 
 const (
@@ -4,44 +4,150 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 	"time"
 )
 
-// CursorEncoder encoder for cursor
+// CursorEncoder encoder for cursor, which encodes row v (a struct, a pointer
+// to struct or a reflect.Value of them) into cursor token
 type CursorEncoder interface {
 	Encode(v interface{}) string
 }
 
 // NewCursorEncoder creates cursor encoder
 func NewCursorEncoder(keys ...string) CursorEncoder {
-	return &cursorEncoder{keys}
+	return newCursorEncoder(toRules(keys)...)
+}
+
+func newCursorEncoder(rules ...Rule) *cursorEncoder {
+	return &cursorEncoder{rules: rules}
 }
 
 type cursorEncoder struct {
-	keys []string
+	rules []Rule
+	// fingerprint of query appended to values if not empty
+	fingerprint string
+	// issuedAt returns issued-at time appended to values if set
+	issuedAt func() time.Time
+	// encoding of cursor, standard base64 encoding if not set
+	encoding *base64.Encoding
+	// compress indicates whether to compress payload
+	compress bool
+	// compact indicates whether to encode lone key by its raw bytes
+	compact bool
 }
 
 func (e *cursorEncoder) Encode(v interface{}) string {
-	return base64.StdEncoding.EncodeToString(e.marshalJSON(v))
+	encoding := e.encoding
+	if encoding == nil {
+		encoding = base64.StdEncoding
+	}
+	b := e.marshal(v)
+	if e.compress {
+		b = compress(b)
+	}
+	return encoding.EncodeToString(b)
+}
+
+// marshal returns payload of cursor, which is compact if possible or JSON
+func (e *cursorEncoder) marshal(value interface{}) []byte {
+	if e.compact && len(e.rules) == 1 && e.fingerprint == "" && e.issuedAt == nil {
+		if b, ok := encodeCompact(e.encodeRules(value)[0]); ok {
+			return b
+		}
+	}
+	return e.marshalJSON(value)
 }
 
 func (e *cursorEncoder) marshalJSON(value interface{}) []byte {
-	rv := toReflectValue(value)
-	// reduce reflect value to underlying value
-	for rv.Kind() == reflect.Ptr {
-		rv = rv.Elem()
+	fields := e.encodeRules(value)
+	if e.fingerprint != "" {
+		fields = append(fields, e.fingerprint)
 	}
-	fields := make([]interface{}, len(e.keys))
-	for i, key := range e.keys {
-		fields[i] = rv.FieldByName(key).Interface()
+	if e.issuedAt != nil {
+		fields = append(fields, e.issuedAt().Unix())
 	}
 	// @TODO: return proper error
 	b, _ := json.Marshal(fields)
 	return b
 }
 
+// encodeRules returns values of paging keys of row value for encoding
+func (e *cursorEncoder) encodeRules(value interface{}) []interface{} {
+	fields := make([]interface{}, len(e.rules))
+	if values, ok := value.(keyValues); ok {
+		for i, rule := range e.rules {
+			fields[i] = rule.encodeValue(reflect.ValueOf(values[i]))
+		}
+		return fields
+	}
+	rv := reduceValue(value)
+	for i, rule := range e.rules {
+		fields[i] = rule.encode(rv)
+	}
+	return fields
+}
+
+// encodeField returns the value of field to be marshaled into cursor. Times
+// are normalized to UTC and keep their nanosecond precision. Byte arrays (e.g.
+// UUIDs) are encoded by their raw bytes in base64 form. Fields of custom types
+// implementing both driver.Valuer and sql.Scanner are encoded by their driver
+// value, so that decoder is able to scan them back. Protobuf timestamps are
+// encoded as times.
+func encodeField(field reflect.Value) interface{} {
+	if v, ok := timestampToTime(field.Interface()); ok {
+		return v
+	}
+	if isBigFloat(field.Type()) {
+		return encodeBigFloat(field)
+	}
+	// methods of big.Int are of its pointer, values would be marshaled as {}
+	if isBigInt(field.Type()) {
+		return toAddressable(field).Addr().Interface()
+	}
+	if !isByteArray(field.Type()) && !isValuerScanner(field.Type()) {
+		return normalizeTime(field.Interface())
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		if isByteArray(field.Type()) {
+			field = field.Elem()
+		}
+	}
+	if isByteArray(field.Type()) {
+		b := make([]byte, field.Len())
+		reflect.Copy(reflect.ValueOf(b), field)
+		return b
+	}
+	v, err := toValuer(field).Value()
+	// @TODO: return proper error
+	if err != nil {
+		return nil
+	}
+	// bytes are marshaled as base64 by json, keep them as text instead
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return normalizeTime(v)
+}
+
+// encodeBigFloat encodes big.Float in its exact hexadecimal mantissa form,
+// since its decimal text form does not carry the precision of the value
+func encodeBigFloat(field reflect.Value) interface{} {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	f := toAddressable(field).Addr().Interface().(*big.Float)
+	return f.Text('p', 0)
+}
+
 /* deprecated */
 
 func encodeOld(rv reflect.Value, keys []string) string {
@@ -0,0 +1,59 @@
+package paginator
+
+// CursorField is value of paging key decoded from cursor
+type CursorField struct {
+	// Name is struct field name of paging key
+	Name string
+	// Value is value of key typed as field of model
+	Value interface{}
+}
+
+// CursorFieldDecoder decoder for cursor, which decodes cursor token into
+// values named by their keys, nil is returned if cursor is invalid
+type CursorFieldDecoder interface {
+	DecodeFields(cursor string) []CursorField
+}
+
+// NewCursorFieldDecoder creates cursor decoder of named values
+func NewCursorFieldDecoder(ref interface{}, keys ...string) (CursorFieldDecoder, error) {
+	return newCursorDecoder(ref, toRules(keys)...)
+}
+
+func (d *cursorDecoder) DecodeFields(cursor string) []CursorField {
+	values := d.Decode(cursor)
+	if values == nil {
+		return nil
+	}
+	return toCursorFields(d.rules, values)
+}
+
+// DecodeCursor decodes cursor token by paging keys and cursor options of
+// paginator for model into values named by their keys, e.g. for hooks or
+// audit logs reasoning about cursors presented by clients. Keys and table
+// of model are resolved as BuildSQL does.
+func (p *Paginator) DecodeCursor(model interface{}, cursor string) ([]CursorField, error) {
+	rt, err := toStructType(model)
+	if err != nil {
+		return nil, err
+	}
+	dry := p.dry()
+	dry.cursor = Cursor{}
+	query := newDryQuery(rt)
+	if _, err := dry.paginate(query); err != nil {
+		return nil, err
+	}
+	values, err := dry.decodeToken(query, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return toCursorFields(dry.rules, values), nil
+}
+
+// toCursorFields names values decoded from cursor by keys of rules
+func toCursorFields(rules []Rule, values []interface{}) []CursorField {
+	fields := make([]CursorField, len(values))
+	for i, v := range values {
+		fields[i] = CursorField{Name: rules[i].Key, Value: v}
+	}
+	return fields
+}
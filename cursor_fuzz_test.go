@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package paginator
+
+import (
+	"errors"
+	"testing"
+)
+
+func FuzzCursorDecoder(f *testing.F) {
+	model := createCursorModelFixture()
+	f.Add(model.Encode())
+	f.Add(model.EncodeOld())
+	f.Add("")
+	f.Add("WzEsMl0=")
+	decoder, err := newCursorDecoder(model, toRules(model.Keys())...)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, cursor string) {
+		fields, err := decoder.decode(cursor)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		}
+		if len(fields) != len(model.Keys()) {
+			t.Fatalf("got %d fields for %d keys", len(fields), len(model.Keys()))
+		}
+	})
+}
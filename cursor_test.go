@@ -1,15 +1,21 @@
 package paginator
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestCursor(t *testing.T) {
@@ -31,8 +37,336 @@ func (s *cursorSuite) TestCursorEncoderAndDecoder() {
 	s.assertFields(model, fields)
 }
 
+func (s *cursorSuite) TestCursorEncoderAndDecoderForValuerScanner() {
+	var model = createValuerModelFixture()
+	cursor := NewCursorEncoder(model.Keys()...).Encode(model)
+	decoder, _ := NewCursorDecoder(model, model.Keys()...)
+	fields := decoder.Decode(cursor)
+	s.Len(fields, len(model.Keys()))
+
+	nullStringVal, _ := fields[0].(sql.NullString)
+	s.Equal(model.NullString, nullStringVal)
+
+	nullTimeVal, _ := fields[1].(sql.NullTime)
+	s.True(nullTimeVal.Valid)
+	s.True(model.NullTime.Time.Equal(nullTimeVal.Time))
+
+	nullInt64PtrVal, _ := fields[2].(*sql.NullInt64)
+	s.Equal(model.NullInt64Ptr, nullInt64PtrVal)
+
+	nilPtrVal, _ := fields[3].(*sql.NullInt64)
+	s.Nil(nilPtrVal)
+
+	enumVal, _ := fields[4].(cursorEnum)
+	s.Equal(model.Enum, enumVal)
+}
+
+func (s *cursorSuite) TestCursorDecoderShouldReturnNilWhenScannerValueIsNotMatched() {
+	var model = createValuerModelFixture()
+	cursor := base64.StdEncoding.EncodeToString([]byte(`["hello","2020-01-01T00:00:00Z",1,null,"pending"]`))
+	decoder, _ := NewCursorDecoder(model, model.Keys()...)
+	fields := decoder.Decode(cursor)
+	s.Nil(fields)
+}
+
+func (s *cursorSuite) TestCursorEncoderAndDecoderForByteArray() {
+	var model = createUUIDModelFixture()
+	cursor := NewCursorEncoder(model.Keys()...).Encode(model)
+	decoder, _ := NewCursorDecoder(model, model.Keys()...)
+	fields := decoder.Decode(cursor)
+	s.Len(fields, len(model.Keys()))
+
+	uuidVal, _ := fields[0].(cursorUUID)
+	s.Equal(model.UUID, uuidVal)
+
+	uuidPtrVal, _ := fields[1].(*cursorUUID)
+	s.Equal(model.UUIDPtr, uuidPtrVal)
+
+	nilPtrVal, _ := fields[2].(*cursorUUID)
+	s.Nil(nilPtrVal)
+}
+
+func (s *cursorSuite) TestCursorEncoderShouldEncodeByteArrayInCompactForm() {
+	var model = createUUIDModelFixture()
+	cursor := NewCursorEncoder("UUID").Encode(model)
+	b, _ := base64.StdEncoding.DecodeString(cursor)
+	s.Equal(fmt.Sprintf(`["%s"]`, base64.StdEncoding.EncodeToString(model.UUID[:])), string(b))
+}
+
+func (s *cursorSuite) TestCursorDecoderShouldReturnNilWhenByteArrayLengthIsNotMatched() {
+	var model = createUUIDModelFixture()
+	cursor := base64.StdEncoding.EncodeToString([]byte(`["dGVzdA==",null,null]`))
+	decoder, _ := NewCursorDecoder(model, model.Keys()...)
+	fields := decoder.Decode(cursor)
+	s.Nil(fields)
+}
+
+func (s *cursorSuite) TestCursorEncoderAndDecoderShouldKeepTimePrecisionInUTC() {
+	var model = createCursorModelFixture()
+	model.Time = time.Date(2020, 1, 5, 17, 51, 12, 123456789, time.FixedZone("UTC+8", 8*60*60))
+	cursor := model.Encode()
+	fields, _ := model.Decode(cursor)
+
+	timeVal, _ := fields[5].(time.Time)
+	s.True(model.Time.Equal(timeVal))
+	s.Equal(123456789, timeVal.Nanosecond())
+	s.Equal(time.UTC, timeVal.Location())
+}
+
+func (s *cursorSuite) TestCursorEncoderShouldEncodeTimeInUTC() {
+	var model = createCursorModelFixture()
+	model.Time = time.Date(2020, 1, 5, 17, 51, 12, 123456789, time.FixedZone("UTC+8", 8*60*60))
+	cursor := NewCursorEncoder("Time").Encode(model)
+	b, _ := base64.StdEncoding.DecodeString(cursor)
+	s.Equal(`["2020-01-05T09:51:12.123456789Z"]`, string(b))
+}
+
+func (s *cursorSuite) TestCursorEncoderAndDecoderForBigNumber() {
+	var model = createBigNumberModelFixture()
+	cursor := NewCursorEncoder(model.Keys()...).Encode(model)
+	decoder, _ := NewCursorDecoder(model, model.Keys()...)
+	fields := decoder.Decode(cursor)
+	s.Len(fields, len(model.Keys()))
+
+	bigIntPtrVal, _ := fields[0].(*big.Int)
+	s.Equal(0, model.BigIntPtr.Cmp(bigIntPtrVal))
+
+	bigFloatPtrVal, _ := fields[1].(*big.Float)
+	s.Equal(0, model.BigFloatPtr.Cmp(bigFloatPtrVal))
+
+	decimalVal, _ := fields[2].(cursorDecimal)
+	s.Equal(model.Decimal, decimalVal)
+}
+
+func (s *cursorSuite) TestCursorEncoderAndDecoderForBigNumberValues() {
+	type bigValueModel struct {
+		BigInt   big.Int
+		BigFloat big.Float
+	}
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigFloat, _, _ := big.ParseFloat("12345678901234567890.123456789", 10, 256, big.ToNearestEven)
+	model := bigValueModel{BigInt: *bigInt, BigFloat: *bigFloat}
+	for _, value := range []interface{}{model, &model} {
+		cursor := NewCursorEncoder("BigInt", "BigFloat").Encode(value)
+		decoder, _ := NewCursorDecoder(model, "BigInt", "BigFloat")
+		fields := decoder.Decode(cursor)
+		s.Len(fields, 2)
+
+		bigIntVal, _ := fields[0].(big.Int)
+		s.Equal(0, bigInt.Cmp(&bigIntVal))
+		s.Equal(bigInt.String(), toQueryArg(fields[0]))
+
+		bigFloatVal, _ := fields[1].(*big.Float)
+		s.Equal(0, bigFloat.Cmp(bigFloatVal))
+		s.Equal(bigFloatVal.Text('g', -1), toQueryArg(fields[1]))
+	}
+}
+
+func (s *cursorSuite) TestCursorEncoderAndDecoderForJSONPath() {
+	type jsonModel struct {
+		Data    json.RawMessage
+		DataMap map[string]interface{}
+	}
+	var model = jsonModel{
+		Data:    json.RawMessage(`{"score":{"value":12345678901234567890.5}}`),
+		DataMap: map[string]interface{}{"name": "hello"},
+	}
+	var rules = []Rule{
+		{Key: "Data", JSONPath: []string{"score", "value"}},
+		{Key: "DataMap", JSONPath: []string{"name"}},
+		{Key: "Data", JSONPath: []string{"unknown"}},
+	}
+	cursor := newCursorEncoder(rules...).Encode(model)
+	decoder, _ := newCursorDecoder(model, rules...)
+	fields := decoder.Decode(cursor)
+	s.Equal([]interface{}{json.Number("12345678901234567890.5"), "hello", nil}, fields)
+}
+
+func (s *cursorSuite) TestCursorFieldDecoder() {
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	cursor := NewCursorEncoder("CreatedAt", "ID").Encode(order{ID: 3, CreatedAt: createdAt})
+
+	decoder, err := NewCursorFieldDecoder(order{}, "CreatedAt", "ID")
+	s.Nil(err)
+	s.Equal([]CursorField{{"CreatedAt", createdAt}, {"ID", 3}}, decoder.DecodeFields(cursor))
+	s.Nil(decoder.DecodeFields("invalid"))
+
+	p := New()
+	p.SetKeys("CreatedAt")
+	p.SetTieBreaker(true)
+	fields, err := p.DecodeCursor(order{}, cursor)
+	s.Nil(err)
+	s.Equal([]CursorField{{"CreatedAt", createdAt}, {"ID", 3}}, fields)
+
+	_, err = p.DecodeCursor(order{}, NewCursorEncoder("ID").Encode(order{ID: 3}))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *cursorSuite) TestCursorDecoderForEnumKeys() {
+	type status string
+	type level int
+	type enumModel struct {
+		Status    status
+		Level     level
+		StatusPtr *status
+		Valuer    cursorEnum
+	}
+	paid := status("paid")
+	keys := []string{"Status", "Level", "StatusPtr", "Valuer"}
+	cursor := NewCursorEncoder(keys...).Encode(enumModel{"open", 3, &paid, cursorEnumActive})
+	decoder, _ := NewCursorDecoder(enumModel{}, keys...)
+	fields := decoder.Decode(cursor)
+	s.Equal([]interface{}{status("open"), level(3), &paid, cursorEnumActive}, fields)
+
+	// enums are query args of their own types, which drivers convert by
+	// their underlying types or driver.Valuer
+	args := New().getCursorQueryArgs(fields[:1])
+	s.Equal([]interface{}{status("open")}, args)
+}
+
+func (s *cursorSuite) TestCursorJSON() {
+	after := "abc"
+	b, err := json.Marshal(Cursor{After: &after})
+	s.Nil(err)
+	s.Equal(`{"after":"abc","before":null}`, string(b))
+
+	format := CursorJSON{After: "next", Before: "prev", OmitEmpty: true}
+	b, err = json.Marshal(struct {
+		Cursor FormattedCursor `json:"cursor"`
+	}{FormattedCursor{Cursor{After: &after}, format}})
+	s.Nil(err)
+	s.Equal(`{"cursor":{"next":"abc"}}`, string(b))
+	b, _ = format.Marshal(Cursor{})
+	s.Equal(`{}`, string(b))
+	// other cursors are still marshaled by default format
+	b, _ = json.Marshal(Cursor{})
+	s.Equal(`{"after":null,"before":null}`, string(b))
+
+	var c Cursor
+	s.Nil(format.Unmarshal([]byte(`{"next":"abc","after":"x"}`), &c))
+	s.Equal(&after, c.After)
+	s.Nil(c.Before)
+	s.NotNil(format.Unmarshal([]byte(`[]`), &c))
+
+	fc := FormattedCursor{Format: format}
+	s.Nil(json.Unmarshal([]byte(`{"prev":"abc"}`), &fc))
+	s.Nil(fc.After)
+	s.Equal(&after, fc.Before)
+
+	c = Cursor{}
+	s.Nil(json.Unmarshal([]byte(`{"next":"x","after":"abc"}`), &c))
+	s.Equal(&after, c.After)
+}
+
 /* cursor encoder */
 
+func (s *cursorSuite) TestDumpCursor() {
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	token := NewCursorEncoder("CreatedAt", "ID").Encode(struct {
+		ID        int
+		CreatedAt time.Time
+	}{3, createdAt})
+
+	p := New()
+	p.SetKeys("CreatedAt")
+	s.Equal(`CreatedAt="2020-01-01T00:00:00Z", [1]=3`, p.DumpCursor(token))
+	s.Equal(`ID="2020-01-01T00:00:00Z", [1]=3`, New().DumpCursor(token))
+	s.Contains(p.DumpCursor("invalid"), "invalid cursor")
+
+	invalid := "invalid"
+	s.Equal(`after: <nil>, before: <invalid>`, Cursor{Before: &invalid}.String())
+
+	// values are redacted from formatted cursors, e.g. logged by hooks
+	email := NewCursorEncoder("Email", "ID").Encode(struct {
+		ID    int
+		Email string
+	}{3, "jane@example.com"})
+	printed := fmt.Sprintf("%v", Cursor{After: &token, Before: &email})
+	s.Equal(`after: [<redacted>, <redacted>], before: [<redacted>, <redacted>]`, printed)
+	s.NotContains(printed, "jane@example.com")
+	s.NotContains(printed, "2020")
+}
+
+func (s *cursorSuite) TestCursorCompression() {
+	type model struct {
+		Name string
+		ID   int
+	}
+	v := model{Name: strings.Repeat("name", 32), ID: 1}
+	encoder := newCursorEncoder(toRules([]string{"Name", "ID"})...)
+	plain := encoder.Encode(v)
+	encoder.compress = true
+	compressed := encoder.Encode(v)
+	s.Less(len(compressed), len(plain))
+
+	decoder, _ := newCursorDecoder(model{}, toRules([]string{"Name", "ID"})...)
+	for _, cursor := range []string{plain, compressed} {
+		fields, err := decoder.decode(cursor)
+		s.Nil(err)
+		s.Equal([]interface{}{v.Name, v.ID}, fields)
+	}
+
+	// short payload is kept uncompressed
+	short := model{Name: "a", ID: 1}
+	s.Equal(NewCursorEncoder("Name", "ID").Encode(short), encoder.Encode(short))
+}
+
+func (s *cursorSuite) TestCursorTimestamp() {
+	type model struct {
+		CreatedAt *timestamppb.Timestamp
+		DeletedAt *timestamppb.Timestamp
+	}
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	v := model{CreatedAt: &timestamppb.Timestamp{Seconds: createdAt.Unix(), Nanos: int32(createdAt.Nanosecond())}}
+	cursor := NewCursorEncoder("CreatedAt", "DeletedAt").Encode(v)
+	p := New()
+	p.SetKeys("CreatedAt", "DeletedAt")
+	s.Equal(`CreatedAt="2020-01-01T00:00:00.123456789Z", DeletedAt=null`, p.DumpCursor(cursor))
+
+	decoder, _ := NewCursorDecoder(model{}, "CreatedAt", "DeletedAt")
+	fields := decoder.Decode(cursor)
+	s.Len(fields, 2)
+	s.Equal(createdAt.Unix(), fields[0].(*timestamppb.Timestamp).Seconds)
+	s.Equal(int32(createdAt.Nanosecond()), fields[0].(*timestamppb.Timestamp).Nanos)
+	s.Nil(fields[1].(*timestamppb.Timestamp))
+
+	// timestamps are passed to query as times
+	s.Equal(createdAt, toQueryArg(fields[0]))
+	s.Nil(toQueryArg(fields[1]))
+}
+
+func (s *cursorSuite) TestCursorLargeIntegers() {
+	type model struct {
+		ID        int64
+		Snowflake uint64
+		Payload   json.RawMessage
+	}
+	v := model{
+		ID:        math.MaxInt64,
+		Snowflake: math.MaxUint64,
+		Payload:   json.RawMessage(`{"id":9223372036854775807}`),
+	}
+	rules := []Rule{{Key: "ID"}, {Key: "Snowflake"}, {Key: "Payload", JSONPath: []string{"id"}}}
+	cursor := newCursorEncoder(rules...).Encode(v)
+
+	decoder, _ := newCursorDecoder(model{}, rules...)
+	fields, err := decoder.decode(cursor)
+	s.Nil(err)
+	s.Equal(int64(math.MaxInt64), fields[0])
+	s.Equal(uint64(math.MaxUint64), fields[1])
+	s.Equal(int64(math.MaxInt64), toQueryArg(fields[2]))
+
+	// entries of maps are decoded as generic JSON values
+	decoder, _ = newCursorDecoder(map[string]interface{}{}, rules[:2]...)
+	fields, err = decoder.decode(newCursorEncoder(rules[:2]...).Encode(v))
+	s.Nil(err)
+	s.Equal(int64(math.MaxInt64), toQueryArg(fields[0]))
+	s.Equal(uint64(math.MaxUint64), toQueryArg(fields[1]))
+
+	// deprecated decoder keeps digits of numbers
+	s.Equal([]interface{}{"9223372036854775807", "18446744073709551615"}, Decode(Encode(reflect.ValueOf(v), []string{"ID", "Snowflake"})))
+}
+
 func (s *cursorSuite) TestCursorEncoderBackwardCompatibility() {
 	var model = createCursorModelFixture()
 	cursor := model.Encode()
@@ -97,6 +431,60 @@ func (s *cursorSuite) TestCursorDecoderShouldReturnNilWhenTimeValueIsNotMatched(
 	s.Nil(fields)
 }
 
+func (s *cursorSuite) TestCursorDecoderShouldReturnInvalidCursorError() {
+	decoder, _ := newCursorDecoder(struct {
+		ID   int
+		Name string
+	}{}, toRules([]string{"ID", "Name"})...)
+	encode := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	for _, cursor := range []string{
+		"hello world",
+		"WzEsImE",
+		encode(`[1]`),
+		encode(`[1,"a",2]`),
+		encode(`{"ID":1}`),
+		encode(`["a","a"]`),
+		encode("a?STRING"),
+		encode("\x01not flate"),
+		encode(`[1,"` + strings.Repeat("a", maxCursorLength) + `"]`),
+	} {
+		fields, err := decoder.decode(cursor)
+		s.Nil(fields, cursor)
+		s.True(errors.Is(err, ErrInvalidCursor), cursor)
+	}
+	fields, err := decoder.decode(encode(`[1,"a"]`))
+	s.NoError(err)
+	s.Equal([]interface{}{1, "a"}, fields)
+}
+
+func (s *cursorSuite) TestCursorDecoderShouldRedactValuesOfErrors() {
+	decoder, _ := newCursorDecoder(struct {
+		ID    int
+		Email string
+	}{}, toRules([]string{"ID", "Email"})...)
+	cursor := base64.StdEncoding.EncodeToString([]byte(`["bob@example.com","a"]`))
+	_, err := decoder.decode(cursor)
+	s.EqualError(err, "invalid cursor: cannot decode value of key ID")
+	var cursorErr *CursorError
+	s.True(errors.As(err, &cursorErr))
+	s.Equal("ID", cursorErr.Key)
+	s.Equal("", cursorErr.Value)
+
+	decoder.verbose = true
+	_, err = decoder.decode(cursor)
+	s.EqualError(err, `invalid cursor: cannot decode value of key ID: "bob@example.com"`)
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *cursorSuite) TestCursorDecoderShouldRecoverFromScannerPanic() {
+	decoder, _ := newCursorDecoder(struct{ Value panicScanner }{}, Rule{Key: "Value"})
+	fields, err := decoder.decode(base64.StdEncoding.EncodeToString([]byte(`[1]`)))
+	s.Nil(fields)
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
 func (s *cursorSuite) TestCursorDecoderBackwardCompatibility() {
 	var model = createCursorModelFixture()
 	cursor := model.EncodeOld()
@@ -111,6 +499,31 @@ func (s *cursorSuite) TestCursorDecoderBackwardCompatibilityForPtr() {
 	s.assertDeprecatedFields(model, fields)
 }
 
+func (s *cursorSuite) TestCursorDecoderBackwardCompatibilityForSeparator() {
+	var model = createCursorModelFixture()
+	for _, value := range []string{"a,b?c", "a?STRING,b", "a?STRING,", "?TIME,?STRING"} {
+		model.String = value
+		cursor := model.EncodeOld()
+		fields, _ := model.Decode(cursor)
+		s.assertDeprecatedFields(model, fields)
+	}
+
+	// text value is one of text keys
+	type named struct {
+		ID   int
+		Name string
+		Time time.Time
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	decoder, err := NewCursorDecoder(named{}, "ID", "Name", "Time")
+	s.Nil(err)
+	cursor := encodeOld(reflect.ValueOf(named{ID: 1, Name: "x?STRING,y?TIME,z", Time: now}), []string{"ID", "Name", "Time"})
+	fields := decoder.Decode(cursor)
+	s.Len(fields, 3)
+	s.Equal("x?STRING,y?TIME,z", fields[1])
+	s.True(now.Equal(fields[2].(time.Time)))
+}
+
 /* cursor deprecated encode & decode */
 
 func (s *cursorSuite) TestCursorDeprecatedEncodeAndDecode() {
@@ -231,6 +644,149 @@ func (m *cursorModel) Decoder() (CursorDecoder, error) {
 	return NewCursorDecoder(m, m.Keys()...)
 }
 
+type panicScanner struct{}
+
+func (panicScanner) Value() (driver.Value, error) {
+	return nil, nil
+}
+
+func (*panicScanner) Scan(interface{}) error {
+	panic("scan")
+}
+
+/* cursor valuer test model */
+
+type cursorEnum int
+
+const (
+	cursorEnumUnknown cursorEnum = iota
+	cursorEnumActive
+)
+
+func (e cursorEnum) Value() (driver.Value, error) {
+	switch e {
+	case cursorEnumActive:
+		return "active", nil
+	default:
+		return "unknown", nil
+	}
+}
+
+func (e *cursorEnum) Scan(src interface{}) error {
+	switch src {
+	case "active":
+		*e = cursorEnumActive
+	case "unknown":
+		*e = cursorEnumUnknown
+	default:
+		return fmt.Errorf("invalid cursor enum: %v", src)
+	}
+	return nil
+}
+
+type valuerModel struct {
+	NullString   sql.NullString
+	NullTime     sql.NullTime
+	NullInt64Ptr *sql.NullInt64
+	NilPtr       *sql.NullInt64
+	Enum         cursorEnum
+}
+
+func createValuerModelFixture() valuerModel {
+	return valuerModel{
+		NullString:   sql.NullString{String: "hello", Valid: true},
+		NullTime:     sql.NullTime{Time: time.Now(), Valid: true},
+		NullInt64Ptr: &sql.NullInt64{Int64: 123, Valid: true},
+		Enum:         cursorEnumActive,
+	}
+}
+
+func (m valuerModel) Keys() []string {
+	return []string{"NullString", "NullTime", "NullInt64Ptr", "NilPtr", "Enum"}
+}
+
+/* cursor uuid test model */
+
+// cursorUUID mimics uuid.UUID from github.com/google/uuid
+type cursorUUID [16]byte
+
+func (u cursorUUID) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+func (u cursorUUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u cursorUUID) Value() (driver.Value, error) {
+	return u.String(), nil
+}
+
+func (u *cursorUUID) Scan(src interface{}) error {
+	return fmt.Errorf("scan is not expected: %v", src)
+}
+
+type uuidModel struct {
+	UUID    cursorUUID
+	UUIDPtr *cursorUUID
+	NilPtr  *cursorUUID
+}
+
+func createUUIDModelFixture() uuidModel {
+	return uuidModel{
+		UUID:    cursorUUID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+		UUIDPtr: &cursorUUID{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+	}
+}
+
+func (m uuidModel) Keys() []string {
+	return []string{"UUID", "UUIDPtr", "NilPtr"}
+}
+
+/* cursor big number test model */
+
+// cursorDecimal mimics decimal.Decimal from github.com/shopspring/decimal
+type cursorDecimal struct {
+	value *big.Rat
+}
+
+func (d cursorDecimal) Value() (driver.Value, error) {
+	return d.value.FloatString(20), nil
+}
+
+func (d *cursorDecimal) Scan(src interface{}) error {
+	str, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("invalid cursor decimal: %v", src)
+	}
+	d.value, ok = new(big.Rat).SetString(str)
+	if !ok {
+		return fmt.Errorf("invalid cursor decimal: %v", src)
+	}
+	return nil
+}
+
+type bigNumberModel struct {
+	BigIntPtr   *big.Int
+	BigFloatPtr *big.Float
+	Decimal     cursorDecimal
+}
+
+func createBigNumberModelFixture() bigNumberModel {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigFloat, _, _ := big.ParseFloat("12345678901234567890.123456789", 10, 256, big.ToNearestEven)
+	decimal, _ := new(big.Rat).SetString("12345678901234567890.12345678901234567890")
+	return bigNumberModel{
+		BigIntPtr:   bigInt,
+		BigFloatPtr: bigFloat,
+		Decimal:     cursorDecimal{decimal},
+	}
+}
+
+func (m bigNumberModel) Keys() []string {
+	return []string{"BigIntPtr", "BigFloatPtr", "Decimal"}
+}
+
 /* util */
 
 func (s *cursorSuite) assertFields(model cursorModel, fields []interface{}) {
@@ -0,0 +1,60 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/iancoleman/strcase"
+)
+
+// DeferredJoinQuery is a Query supporting replacing selected columns, which is
+// required for deferred join mode
+type DeferredJoinQuery interface {
+	Query
+	// SelectOnly replaces selected columns of the query
+	SelectOnly(columns ...string) Query
+}
+
+// Errors for deferred join
+var (
+	ErrDeferredJoinNotSupported = errors.New("query should implement DeferredJoinQuery to paginate with deferred join")
+	ErrDeferredJoinPrimaryKey   = errors.New("deferred join requires model with a single primary key")
+)
+
+// SetDeferredJoin sets whether to fetch page in two phases: primary keys of
+// the page are selected with cursor predicate first, then full rows are
+// fetched by primary keys. It is faster on wide tables with large columns.
+func (p *Paginator) SetDeferredJoin(enabled bool) {
+	p.deferredJoin = enabled
+}
+
+// selectDeferred executes paged query selecting only primary key, then
+// fetches full rows by base query, which is the query without cursor
+// predicate, ordering and limit
+func (p *Paginator) selectDeferred(base, paged Query, rt reflect.Type) (Query, error) {
+	dq, ok := paged.(DeferredJoinQuery)
+	if !ok {
+		return paged, ErrDeferredJoinNotSupported
+	}
+	pks := primaryKeys(rt)
+	if len(pks) != 1 {
+		return paged, ErrDeferredJoinPrimaryKey
+	}
+	pk := p.dialect.column(p.getTable(base), strcase.ToSnake(pks[0]))
+	result := dq.SelectOnly(pk).Select()
+	elems := reflect.ValueOf(paged.Value()).Elem()
+	if elems.Kind() != reflect.Slice || elems.Len() == 0 {
+		return result, nil
+	}
+	ids := make([]interface{}, elems.Len())
+	for i := 0; i < elems.Len(); i++ {
+		ids[i] = fieldByName(reflect.Indirect(elems.Index(i)), pks[0]).Interface()
+	}
+	elems.Set(reflect.MakeSlice(elems.Type(), 0, len(ids)))
+	rows, err := p.route(base.Where(fmt.Sprintf("%s IN (?)", pk), ids).Order(p.getOrder()), QueryPage)
+	if err != nil {
+		return rows, err
+	}
+	return rows.Select(), nil
+}
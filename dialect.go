@@ -0,0 +1,195 @@
+package paginator
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Dialect SQL dialect of database, which decides quoting of identifiers,
+// placeholders and limit syntax of the built SQL
+type Dialect string
+
+// Dialects
+const (
+	MySQL     Dialect = "mysql"
+	Postgres  Dialect = "postgres"
+	SQLite    Dialect = "sqlite"
+	SQLServer Dialect = "sqlserver"
+	Oracle    Dialect = "oracle"
+)
+
+// SetDialect sets SQL dialect, table and column identifiers of keys are then
+// quoted by the dialect. Placeholders and limit syntax of the dialect are
+// applied to SQL built by BuildSQL and BuildStatement only, since query
+// builders such as GORM convert "?" placeholders by themselves.
+func (p *Paginator) SetDialect(dialect Dialect) {
+	p.dialect = dialect
+}
+
+// BuildStatement builds a complete statement selecting a page of model, e.g.
+// SELECT * FROM orders WHERE ... ORDER BY ... LIMIT 11, together with its
+// args, in SQL dialect of the paginator, for use with database/sql.
+func (p *Paginator) BuildStatement(model interface{}) (string, []interface{}) {
+	where, args, order, limit := p.BuildSQL(model)
+	rt, err := toStructType(model)
+	if err != nil {
+		return "", nil
+	}
+	hint := p.getHint()
+	var b strings.Builder
+	if hint.Comment != "" && p.dialect == Postgres {
+		fmt.Fprintf(&b, "/*+ %s */ ", hint.Comment)
+	}
+	b.WriteString("SELECT ")
+	if hint.Comment != "" && p.dialect != Postgres {
+		fmt.Fprintf(&b, "/*+ %s */ ", hint.Comment)
+	}
+	fmt.Fprintf(&b, "* FROM %s", p.dialect.quoteTable(p.qualifyTable(newDryQuery(rt).table)))
+	if hint.Table != "" {
+		fmt.Fprintf(&b, " %s", hint.Table)
+	}
+	if where != "" {
+		fmt.Fprintf(&b, " WHERE %s", where)
+	}
+	if order != "" {
+		fmt.Fprintf(&b, " ORDER BY %s", order)
+	}
+	if limit != Unlimited {
+		fmt.Fprintf(&b, " %s", p.dialect.limit(limit))
+	}
+	return b.String(), args
+}
+
+// column returns column of table in quoted form, table may be qualified by
+// schema (schema.table)
+func (d Dialect) column(table, column string) string {
+	return fmt.Sprintf("%s.%s", d.quoteTable(table), d.quote(column))
+}
+
+// quoteTable quotes each part of table qualified by schema
+func (d Dialect) quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = d.quote(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quote quotes identifier, identifier is not quoted if dialect is not set.
+// Oracle folds unquoted identifiers to upper case, so that lower case
+// identifiers (e.g. snake_case columns of GORM) are quoted in upper case to
+// match them, identifiers of any upper case letter are quoted as they are.
+func (d Dialect) quote(identifier string) string {
+	switch d {
+	case MySQL:
+		return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
+	case SQLServer:
+		return "[" + strings.Replace(identifier, "]", "]]", -1) + "]"
+	case Oracle:
+		if identifier == strings.ToLower(identifier) {
+			identifier = strings.ToUpper(identifier)
+		}
+		return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+	case Postgres, SQLite:
+		return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+	default:
+		return identifier
+	}
+}
+
+// nullsOrderBy returns orderBy of key with its NULL values placed last or
+// first, Oracle has NULLS LAST and NULLS FIRST, SQL Server orders by a CASE
+// expression since it has no boolean expressions, and the others order by
+// IS NULL, whose NULL values sort last when it is ascending
+func (d Dialect) nullsOrderBy(sqlKey, orderBy string, nullsLast bool) string {
+	nullsOrder := DESC
+	if nullsLast {
+		nullsOrder = ASC
+	}
+	switch d {
+	case Oracle:
+		if nullsLast {
+			return orderBy + " NULLS LAST"
+		}
+		return orderBy + " NULLS FIRST"
+	case SQLServer:
+		return fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END %s, %s", sqlKey, nullsOrder, orderBy)
+	default:
+		return fmt.Sprintf("%s IS NULL %s, %s", sqlKey, nullsOrder, orderBy)
+	}
+}
+
+// collation returns collation name in COLLATE clause, Postgres collations are
+// identifiers which are quoted to keep their case
+func (d Dialect) collation(name string) string {
+	if d == Postgres {
+		return d.quote(name)
+	}
+	return name
+}
+
+// savepointStatements returns statements creating, rolling back to and
+// releasing savepoints of dialect, release is empty if not supported
+func (d Dialect) savepointStatements() (savepoint, rollbackTo, release string) {
+	switch d {
+	case SQLServer:
+		return "SAVE TRANSACTION", "ROLLBACK TRANSACTION", ""
+	case Oracle:
+		return "SAVEPOINT", "ROLLBACK TO SAVEPOINT", ""
+	default:
+		return "SAVEPOINT", "ROLLBACK TO SAVEPOINT", "RELEASE SAVEPOINT"
+	}
+}
+
+// snapshotIsolation returns the lowest isolation level of dialect reading a
+// transaction from a single snapshot, Oracle supports read committed and
+// serializable only
+func (d Dialect) snapshotIsolation() sql.IsolationLevel {
+	if d == Oracle {
+		return sql.LevelSerializable
+	}
+	return sql.LevelRepeatableRead
+}
+
+// placeholder returns n-th (1-based) placeholder
+func (d Dialect) placeholder(n int) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("$%d", n)
+	case SQLServer:
+		return fmt.Sprintf("@p%d", n)
+	case Oracle:
+		return fmt.Sprintf(":%d", n)
+	default:
+		return "?"
+	}
+}
+
+// bind replaces "?" placeholders of query by placeholders of dialect
+func (d Dialect) bind(query string) string {
+	if d.placeholder(1) == "?" {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString(d.placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// limit returns limit clause of n rows
+func (d Dialect) limit(n int) string {
+	switch d {
+	case SQLServer, Oracle:
+		return fmt.Sprintf("OFFSET 0 ROWS FETCH NEXT %d ROWS ONLY", n)
+	default:
+		return fmt.Sprintf("LIMIT %d", n)
+	}
+}
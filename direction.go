@@ -0,0 +1,39 @@
+package paginator
+
+import "fmt"
+
+// Direction decides how a key is ordered and compared in an order, which is
+// the order of the key flipped for pages of before cursor. It is for
+// orderings other than plain ASC and DESC of the key, e.g. custom operator
+// classes (ORDER BY key USING ~<~) or reversals by expression.
+type Direction interface {
+	// OrderBy returns ORDER BY expression of key sqlKey in order
+	OrderBy(sqlKey string, order Order) string
+	// Operator returns comparison operator matching keys following value of
+	// cursor in order
+	Operator(order Order) string
+}
+
+// DefaultDirection orders keys by ASC and DESC and compares them by > and <
+var DefaultDirection Direction = defaultDirection{}
+
+type defaultDirection struct{}
+
+func (defaultDirection) OrderBy(sqlKey string, order Order) string {
+	return fmt.Sprintf("%s %s", sqlKey, order)
+}
+
+func (defaultDirection) Operator(order Order) string {
+	if order == ASC {
+		return ">"
+	}
+	return "<"
+}
+
+// direction returns direction of key, which defaults to DefaultDirection
+func (r Rule) direction() Direction {
+	if r.Direction != nil {
+		return r.Direction
+	}
+	return DefaultDirection
+}
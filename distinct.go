@@ -0,0 +1,69 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iancoleman/strcase"
+)
+
+// DistinctQuery is a Query supporting SELECT DISTINCT, which is required for
+// distinct mode
+type DistinctQuery interface {
+	Query
+	// Distinct selects only distinct rows of the selection
+	Distinct() Query
+}
+
+// Errors for distinct mode
+var (
+	ErrDistinctNotSupported = errors.New("query should implement DistinctQuery to paginate with DISTINCT")
+	ErrDistinctKey          = errors.New("paging key should be selected by DISTINCT query")
+)
+
+// SetDistinct sets whether to select only distinct rows. Paging keys must be
+// part of the selection since key columns cannot be added without changing
+// the distinct set, which is checked if query implements SelectQuery.
+func (p *Paginator) SetDistinct(enabled bool) {
+	p.distinct = enabled
+}
+
+func (p *Paginator) appendDistinct(query Query) (Query, error) {
+	if !p.distinct {
+		return query, nil
+	}
+	dq, ok := query.(DistinctQuery)
+	if !ok {
+		return query, ErrDistinctNotSupported
+	}
+	if sq, ok := query.(SelectQuery); ok {
+		if err := p.validateDistinctKeys(p.getTable(query), sq.Selects()); err != nil {
+			return query, err
+		}
+	}
+	return dq.Distinct(), nil
+}
+
+// validateDistinctKeys checks column of each key is in selects of table,
+// empty selects means all columns
+func (p *Paginator) validateDistinctKeys(table string, selects []string) error {
+	if len(selects) == 0 {
+		return nil
+	}
+	for _, rule := range append(p.rules, p.orderRules...) {
+		// expressions and aliases are expected to be selected by caller
+		if rule.SQLRepr != "" || rule.Alias {
+			continue
+		}
+		columns := []string{rule.column()}
+		for _, key := range rule.Coalesce {
+			columns = append(columns, strcase.ToSnake(key))
+		}
+		for _, column := range columns {
+			if !isSelected(selects, table, column) {
+				return fmt.Errorf("%w: %s", ErrDistinctKey, rule.Key)
+			}
+		}
+	}
+	return nil
+}
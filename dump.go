@@ -0,0 +1,80 @@
+package paginator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DumpCursor returns human-readable form of cursor token, which lists paging
+// keys of the paginator with their values in the token, e.g.
+// `CreatedAt="2020-01-01T00:00:00Z", ID=3`. Values beyond configured keys
+// (e.g. tie-breaker) are listed by their positions.
+func (p *Paginator) DumpCursor(token string) string {
+	values, err := dumpValues(token)
+	if err != nil {
+		return fmt.Sprintf("invalid cursor: %s", err)
+	}
+	rules := p.rules
+	if len(p.distinctOn) > 0 {
+		rules = p.getDistinctOnRules()
+	} else if len(rules) == 0 {
+		rules = []Rule{{Key: "ID"}}
+	}
+	pairs := make([]string, len(values))
+	for i, v := range values {
+		key := fmt.Sprintf("[%d]", i)
+		if i < len(rules) {
+			key = rules[i].Key
+		}
+		pairs[i] = fmt.Sprintf("%s=%s", key, v)
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// RedactedValue replaces values of cursor tokens printed by String of Cursor
+const RedactedValue = "<redacted>"
+
+// String returns cursor tokens with each of their values replaced by
+// RedactedValue, since values of keys may be PII and cursors are passed to
+// hooks which may log them. Values are only printed by DumpCursor.
+func (c Cursor) String() string {
+	return fmt.Sprintf("after: %s, before: %s", dumpToken(c.After), dumpToken(c.Before))
+}
+
+func dumpToken(token *string) string {
+	if token == nil {
+		return "<nil>"
+	}
+	values, err := dumpValues(*token)
+	if err != nil {
+		return "<invalid>"
+	}
+	for i := range values {
+		values[i] = RedactedValue
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// dumpValues returns JSON representation of each value in token
+func dumpValues(token string) ([]string, error) {
+	b, err := decodeBase64(token)
+	if err != nil {
+		return nil, err
+	}
+	if b, err = decompress(b); err != nil {
+		return nil, err
+	}
+	if isCompact(b) {
+		return []string{fmt.Sprintf("0x%x", b[1:])}, nil
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		return nil, err
+	}
+	values := make([]string, len(raws))
+	for i, raw := range raws {
+		values[i] = string(raw)
+	}
+	return values, nil
+}
@@ -0,0 +1,181 @@
+package paginator
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrorQuery is a Query reporting error of its execution, which lets EachPage
+// retry failed queries
+type ErrorQuery interface {
+	Query
+	// Err returns error of executed query
+	Err() error
+}
+
+// RetryPolicy of page fetches in EachPage
+type RetryPolicy struct {
+	// MaxAttempts is max number of attempts to fetch a page, a page is not
+	// retried if it is less than 2
+	MaxAttempts int
+	// InitialBackoff is backoff before the first retry, which doubles for
+	// each retry, default is 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff caps backoff between retries, default is 5s
+	MaxBackoff time.Duration
+	// IsTransient reports whether error is transient and worth a retry,
+	// default is IsTransientError
+	IsTransient func(err error) bool
+}
+
+const (
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+)
+
+// transientMessages are fragments of error messages of transient database
+// errors, such as deadlocks and lost connections
+var transientMessages = []string{
+	"deadlock",
+	"lock wait timeout",
+	"connection reset",
+	"broken pipe",
+	"bad connection",
+	"connection refused",
+	"server closed",
+	"could not serialize access",
+}
+
+// IsTransientError reports whether err is a transient database error, such as
+// deadlock, serialization failure, connection reset or timeout
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRetryPolicy sets retry policy of page fetches in EachPage
+func (p *Paginator) SetRetryPolicy(policy RetryPolicy) {
+	p.retry = policy
+}
+
+// SetMaxTotalRows sets max number of rows walked by EachPage across all
+// pages, so that batch jobs bound their work per run. The walk then stops
+// with cursor of the paginator left at the rest of rows to be resumed (see
+// EncodeState), next cursor (see GetNextCursor) is nil if there is no rest.
+func (p *Paginator) SetMaxTotalRows(n int) {
+	p.maxTotalRows = n
+}
+
+// EachPage walks pages from configured cursor in its direction (forward if
+// there is no cursor) until the last page, and calls fn with each page.
+// Query builds a fresh query of each attempt, since a query is executed only
+// once. Failed fetches are retried by retry policy (see SetRetryPolicy),
+// error of executed query is detected if it implements ErrorQuery. An error
+// of fn stops the walk, cursor of the paginator is then left at the page
+// not processed yet so that the walk can be resumed (see EncodeState).
+func (p *Paginator) EachPage(query func() Query, fn func(page interface{}) error) error {
+	config := *p
+	walked := 0
+	for {
+		if p.maxTotalRows > 0 {
+			config.limit = p.boundedLimit(p.maxTotalRows - walked)
+		}
+		page, err := config.fetchPage(query)
+		if err != nil {
+			return err
+		}
+		if err := fn(page.GetPage()); err != nil {
+			return err
+		}
+		run := page.resolved()
+		walked += run.count
+		next := run.next
+		p.setResult(run)
+		// next pages read the same snapshot
+		config.snapshot = page.GetSnapshotTime()
+		cursor := next.After
+		if config.hasBeforeCursor() {
+			cursor = next.Before
+		}
+		if cursor == nil {
+			return nil
+		}
+		if config.hasBeforeCursor() {
+			config.cursor = Cursor{Before: cursor}
+		} else {
+			config.cursor = Cursor{After: cursor}
+		}
+		// snapshot is left along with cursor to resume the walk
+		p.cursor, p.snapshot = config.cursor, config.snapshot
+		// next pages are read by cursor
+		config.offset, p.offset = 0, 0
+		if p.maxTotalRows > 0 && walked >= p.maxTotalRows {
+			return nil
+		}
+	}
+}
+
+// boundedLimit returns limit of page fetching at most remaining rows
+func (p *Paginator) boundedLimit(remaining int) int {
+	limit := p.limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit == Unlimited || limit > remaining {
+		return remaining
+	}
+	return limit
+}
+
+// fetchPage paginates a page by copy of paginator with retries
+func (p *Paginator) fetchPage(query func() Query) (*Paginator, error) {
+	isTransient := p.retry.IsTransient
+	if isTransient == nil {
+		isTransient = IsTransientError
+	}
+	backoff := p.retry.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultInitialBackoff
+	}
+	maxBackoff := p.retry.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		page := *p
+		page.last = new(lastPage)
+		result, err := page.Paginate(query())
+		if eq, ok := result.(ErrorQuery); ok && err == nil {
+			err = eq.Err()
+		}
+		if err == nil {
+			return &page, nil
+		}
+		if attempt >= p.retry.MaxAttempts || !isTransient(err) {
+			return nil, err
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
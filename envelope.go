@@ -0,0 +1,77 @@
+package paginator
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// Paging is cursor representation of paginated page in JSON responses
+type Paging struct {
+	// Next is cursor of the next page, it is omitted on the last page
+	Next *string `json:"next,omitempty"`
+	// Prev is cursor of the previous page, it is omitted on the first page
+	Prev *string `json:"prev,omitempty"`
+	// HasNext indicates whether there is a next page
+	HasNext bool `json:"has_next"`
+}
+
+// GetPaging returns cursor representation of paginated page for JSON
+// responses
+func (p *Paginator) GetPaging() Paging {
+	p = p.resolved()
+	return Paging{
+		Next:    p.next.After,
+		Prev:    p.next.Before,
+		HasNext: p.next.After != nil,
+	}
+}
+
+// WriteJSON writes paginated page to w in the envelope of NewPage, i.e.
+// {"items": [...], "paging": {...}}, rows are encoded one by one into w so
+// that huge pages of export endpoints are not buffered as a whole
+func (p *Paginator) WriteJSON(w io.Writer) error {
+	p = p.resolved()
+	if _, err := io.WriteString(w, `{"items":[`); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	if elems := reflect.ValueOf(p.page); elems.Kind() == reflect.Ptr && elems.Elem().Kind() == reflect.Slice {
+		elems = elems.Elem()
+		for i := 0; i < elems.Len(); i++ {
+			if i > 0 {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			if err := enc.Encode(elems.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := io.WriteString(w, `],"paging":`); err != nil {
+		return err
+	}
+	if err := enc.Encode(p.GetPaging()); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+// GetCursors returns cursor of each row of paginated page in order of the
+// page, e.g. for edges of GraphQL connections
+func (p *Paginator) GetCursors() []string {
+	p = p.resolved()
+	elems := reflect.ValueOf(p.page)
+	if elems.Kind() != reflect.Ptr || elems.Elem().Kind() != reflect.Slice {
+		return nil
+	}
+	elems = elems.Elem()
+	encoder := p.newCursorEncoder()
+	cursors := make([]string, elems.Len())
+	for i := range cursors {
+		cursors[i] = encoder.Encode(elems.Index(i))
+	}
+	return cursors
+}
@@ -0,0 +1,62 @@
+package paginator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SetETagVersionKey sets field of model holding row version, e.g. UpdatedAt
+// or Version, which is included in ETag of pages (see GetETag)
+func (p *Paginator) SetETagVersionKey(key string) {
+	p.versionKey = key
+}
+
+// GetETag returns weak ETag of paginated page, which is derived from cursor
+// boundaries of the page and paging keys and version (see SetETagVersionKey)
+// of its rows. Without version key, changes to columns other than paging keys
+// do not change ETag.
+func (p *Paginator) GetETag() string {
+	p = p.resolved()
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	enc.Encode([]*string{p.next.After, p.next.Before})
+	if elems := reflect.ValueOf(p.page); elems.Kind() == reflect.Ptr && elems.Elem().Kind() == reflect.Slice {
+		elems = elems.Elem()
+		for i := 0; i < elems.Len(); i++ {
+			elem := reflect.Indirect(elems.Index(i))
+			values := make([]interface{}, 0, len(p.rules)+1)
+			for _, rule := range p.rules {
+				values = append(values, rule.encode(elem))
+			}
+			if p.versionKey != "" {
+				values = append(values, Rule{Key: p.versionKey}.encode(elem))
+			}
+			enc.Encode(values)
+		}
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// MatchETag reports whether If-None-Match header value matches etag by weak
+// comparison, so that handlers can respond 304 Not Modified
+func MatchETag(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// validateETagVersionKey validates ETag version key against model type rt
+func (p *Paginator) validateETagVersionKey(rt reflect.Type) error {
+	if p.versionKey == "" {
+		return nil
+	}
+	return validateRules(rt, []Rule{{Key: p.versionKey}})
+}
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
-	"gorm.io/gorm"
-	"gorm.io/driver/sqlite"
 	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 // Product for product model
@@ -54,11 +56,14 @@ func main() {
 
 	var p1Products []Product
 
-	result := p.Paginate(stmt, &p1Products)
+	result, err := p.Paginate(NewGormQuery(stmt, &p1Products))
 
+	if err != nil {
+		panic(err.Error())
+	}
 	// for gorm error handling you can refer to: https://gorm.io/docs/error_handling.html
-	if result.Error != nil {
-		panic(result.Error.Error())
+	if err := result.(*GormQuery).DB.Error; err != nil {
+		panic(err.Error())
 	}
 	p1Cursor := p.GetNextCursor()
 
@@ -74,10 +79,13 @@ func main() {
 
 	var p2Products []Product
 
-	result = p.Paginate(stmt, &p2Products)
+	result, err = p.Paginate(NewGormQuery(stmt, &p2Products))
 
-	if result.Error != nil {
-		panic(result.Error.Error())
+	if err != nil {
+		panic(err.Error())
+	}
+	if err := result.(*GormQuery).DB.Error; err != nil {
+		panic(err.Error())
 	}
 	p2Cursor := p.GetNextCursor()
 
@@ -93,10 +101,13 @@ func main() {
 
 	var p3Products []Product
 
-	result = p.Paginate(stmt, &p3Products)
+	result, err = p.Paginate(NewGormQuery(stmt, &p3Products))
 
-	if result.Error != nil {
-		panic(result.Error.Error())
+	if err != nil {
+		panic(err.Error())
+	}
+	if err := result.(*GormQuery).DB.Error; err != nil {
+		panic(err.Error())
 	}
 	p3Cursor := p.GetNextCursor()
 
@@ -141,6 +152,204 @@ func GetProductPaginator(q PagingQuery) *paginator.Paginator {
 	return p
 }
 
+// GormQuery adapts *gorm.DB to paginator.SelectQuery
+type GormQuery struct {
+	DB  *gorm.DB
+	Out interface{}
+}
+
+// NewGormQuery creates query for paginating stmt into out
+func NewGormQuery(db *gorm.DB, out interface{}) *GormQuery {
+	return &GormQuery{DB: db, Out: out}
+}
+
+// Model returns model reference for decoding cursor
+func (q *GormQuery) Model() interface{} {
+	return q.Out
+}
+
+// Value returns destination of query result
+func (q *GormQuery) Value() interface{} {
+	return q.Out
+}
+
+// Table returns table name of the model, which is qualified by schema if
+// the query selects from schema.table, e.g. Table("analytics.events")
+func (q *GormQuery) Table() string {
+	if q.DB.Statement.Table == "" {
+		q.DB.Statement.Parse(q.Out)
+	}
+	if expr := q.DB.Statement.TableExpr; expr != nil {
+		name := strings.NewReplacer("`", "", `"`, "").Replace(expr.SQL)
+		if parts := strings.Split(name, "."); len(parts) == 2 && parts[1] == q.DB.Statement.Table {
+			return name
+		}
+	}
+	return q.DB.Statement.Table
+}
+
+// Selects returns selected columns
+func (q *GormQuery) Selects() []string {
+	return q.DB.Statement.Selects
+}
+
+// AddSelects appends columns to the selection
+func (q *GormQuery) AddSelects(columns ...string) paginator.Query {
+	selects := append(append([]string(nil), q.DB.Statement.Selects...), columns...)
+	return &GormQuery{DB: q.DB.Select(selects), Out: q.Out}
+}
+
+// Wrap wraps query as subquery aliased by alias
+func (q *GormQuery) Wrap(alias string, selects ...string) paginator.Query {
+	columns := q.DB.Statement.Selects
+	if len(columns) == 0 {
+		columns = []string{q.Table() + ".*"}
+	}
+	inner := q.DB.Model(q.Out).Select(strings.Join(append(append([]string(nil), columns...), selects...), ", "))
+	outer := q.DB.Session(&gorm.Session{}).Table(fmt.Sprintf("(?) AS %s", alias), inner)
+	return &GormQuery{DB: outer, Out: q.Out}
+}
+
+// With selects all columns from query defined as common table expression,
+// which is placed in a derived table since GORM builds FROM as the first
+// customizable clause
+func (q *GormQuery) With(name string) paginator.Query {
+	inner := q.DB.Model(q.Out)
+	outer := q.DB.Session(&gorm.Session{}).Table(fmt.Sprintf("(WITH %s AS (?) SELECT * FROM %s) AS %s", name, name, name), inner)
+	return &GormQuery{DB: outer, Out: q.Out}
+}
+
+// Hint places table hint of query after its table, hint comments are not
+// supported by GORM yet
+func (q *GormQuery) Hint(hint paginator.Hint) paginator.Query {
+	table := q.Table()
+	db := q.DB.Table(fmt.Sprintf("%s %s", table, hint.Table))
+	db.Statement.Table = table
+	return &GormQuery{DB: db, Out: q.Out}
+}
+
+// UnionAll selects all columns from UNION ALL of queries as subquery
+func (q *GormQuery) UnionAll(alias string, others ...paginator.Query) paginator.Query {
+	parts := []interface{}{q.DB.Model(q.Out)}
+	for _, other := range others {
+		parts = append(parts, other.(*GormQuery).DB)
+	}
+	union := strings.TrimSuffix(strings.Repeat("? UNION ALL ", len(parts)), " UNION ALL ")
+	outer := q.DB.Session(&gorm.Session{}).Table(fmt.Sprintf("(%s) AS %s", union, alias), parts...)
+	return &GormQuery{DB: outer, Out: q.Out}
+}
+
+// SelectOnly replaces selected columns
+func (q *GormQuery) SelectOnly(columns ...string) paginator.Query {
+	return &GormQuery{DB: q.DB.Select(columns), Out: q.Out}
+}
+
+// Indexes lists indexes of the table from SQLite catalog
+func (q *GormQuery) Indexes() ([]paginator.Index, error) {
+	rows, err := q.DB.Session(&gorm.Session{}).Raw(paginator.SQLiteIndexesSQL, q.Table()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []paginator.Index
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, paginator.Index{Name: name})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, column)
+	}
+	return indexes, rows.Err()
+}
+
+// Explain returns query plan of the query
+func (q *GormQuery) Explain() (string, error) {
+	stmt := q.DB.Session(&gorm.Session{DryRun: true, WithConditions: true}).Find(q.Out).Statement
+	rows, err := q.DB.Session(&gorm.Session{}).Raw("EXPLAIN QUERY PLAN "+stmt.SQL.String(), stmt.Vars...).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+// Counter returns a function counting rows of the query on its own session
+func (q *GormQuery) Counter() func() (int64, error) {
+	db := q.DB.Session(&gorm.Session{WithConditions: true}).Model(q.Out)
+	db.Statement.TableExpr = q.DB.Statement.TableExpr
+	return func() (int64, error) {
+		var total int64
+		err := db.Count(&total).Error
+		return total, err
+	}
+}
+
+// Distinct selects only distinct rows of the selection
+func (q *GormQuery) Distinct() paginator.Query {
+	return &GormQuery{DB: q.DB.Distinct(), Out: q.Out}
+}
+
+// Exists returns whether the query has any row
+func (q *GormQuery) Err() error {
+	return q.DB.Error
+}
+
+func (q *GormQuery) Exists() (bool, error) {
+	var exists bool
+	sub := q.DB.Session(&gorm.Session{WithConditions: true}).Model(q.Out).Select("1")
+	err := q.DB.Session(&gorm.Session{}).Raw("SELECT EXISTS (?)", sub).Row().Scan(&exists)
+	return exists, err
+}
+
+// Having appends having condition
+func (q *GormQuery) Having(query string, args ...interface{}) paginator.Query {
+	return &GormQuery{DB: q.DB.Having(query, args...), Out: q.Out}
+}
+
+// Where appends where condition
+func (q *GormQuery) Where(query string, args ...interface{}) paginator.Query {
+	return &GormQuery{DB: q.DB.Where(query, args...), Out: q.Out}
+}
+
+// Limit sets limit
+func (q *GormQuery) Limit(limit int) paginator.Query {
+	return &GormQuery{DB: q.DB.Limit(limit), Out: q.Out}
+}
+
+// Order appends order
+func (q *GormQuery) Order(order string) paginator.Query {
+	return &GormQuery{DB: q.DB.Order(order), Out: q.Out}
+}
+
+// Select executes query
+func (q *GormQuery) Select() paginator.Query {
+	return &GormQuery{DB: q.DB.Find(q.Out), Out: q.Out}
+}
+
 func toJSON(v interface{}) string {
 	bytes, err := json.MarshalIndent(v, "", "\t")
 	if err != nil {
@@ -0,0 +1,42 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ExplainQuery is a Query able to explain itself, which is required by
+// explain mode
+type ExplainQuery interface {
+	Query
+	// Explain returns query plan of the query without executing it
+	Explain() (string, error)
+}
+
+// ErrExplainNotSupported is returned when explain mode is enabled but query
+// does not implement ExplainQuery
+var ErrExplainNotSupported = errors.New("query should implement ExplainQuery to capture query plan")
+
+// SetExplain sets whether to capture query plan (EXPLAIN) of the paginated
+// query before executing it, the plan is sent to the logger hook. It costs
+// an extra round trip and is meant for debugging.
+func (p *Paginator) SetExplain(enabled bool) {
+	p.explain = enabled
+}
+
+func (p *Paginator) explainQuery(query Query) error {
+	if !p.explain {
+		return nil
+	}
+	eq, ok := query.(ExplainQuery)
+	if !ok {
+		return ErrExplainNotSupported
+	}
+	plan, err := eq.Explain()
+	if err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("explain: %s", err))
+		return nil
+	}
+	p.plan = plan
+	return nil
+}
@@ -0,0 +1,169 @@
+// Package export writes all rows of a query as CSV or NDJSON by walking its
+// pages by cursor (see Paginator.EachPage), so that large exports neither
+// load all rows at once nor slow down by OFFSET
+package export
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Field maps a column of export to value of rows
+type Field struct {
+	// Name is header of CSV column or key of NDJSON object
+	Name string
+	// Key is struct field name of the value, it defaults to Name
+	Key string
+	// Value returns value of row (a struct or struct pointer) instead of
+	// struct field Key
+	Value func(row interface{}) interface{}
+}
+
+// value returns value of field from row
+func (f Field) value(row reflect.Value) interface{} {
+	if f.Value != nil {
+		return f.Value(row.Interface())
+	}
+	key := f.Key
+	if key == "" {
+		key = f.Name
+	}
+	v := reflect.Indirect(row)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if v = v.FieldByName(key); !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// CSV writes rows of all pages of query into w as CSV, with a header row of
+// field names. Fields default to exported fields of the row struct, values
+// are formatted by fmt except that NULL values are empty and times are in
+// RFC 3339. Query builds a fresh query of each page as in EachPage.
+func CSV(w io.Writer, p *paginator.Paginator, query func() paginator.Query, fields ...Field) error {
+	cw := csv.NewWriter(w)
+	header := false
+	err := eachRow(p, query, func(row reflect.Value) error {
+		if !header {
+			if len(fields) == 0 {
+				fields = structFields(row.Type())
+			}
+			if err := cw.Write(names(fields)); err != nil {
+				return err
+			}
+			header = true
+		}
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = format(f.value(row))
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	if !header && len(fields) > 0 {
+		if err := cw.Write(names(fields)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// NDJSON writes rows of all pages of query into w as newline-delimited JSON,
+// each row is a JSON object of fields, or the row itself encoded by
+// encoding/json if no fields are given. Query builds a fresh query of each
+// page as in EachPage.
+func NDJSON(w io.Writer, p *paginator.Paginator, query func() paginator.Query, fields ...Field) error {
+	enc := json.NewEncoder(w)
+	return eachRow(p, query, func(row reflect.Value) error {
+		if len(fields) == 0 {
+			return enc.Encode(row.Interface())
+		}
+		object := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			object[f.Name] = f.value(row)
+		}
+		return enc.Encode(object)
+	})
+}
+
+// eachRow calls fn with each row of all pages of query
+func eachRow(p *paginator.Paginator, query func() paginator.Query, fn func(row reflect.Value) error) error {
+	return p.EachPage(query, func(page interface{}) error {
+		rows := reflect.Indirect(reflect.ValueOf(page))
+		if rows.Kind() != reflect.Slice {
+			return paginator.ErrInvalidModel
+		}
+		for i := 0; i < rows.Len(); i++ {
+			if err := fn(rows.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// structFields returns fields of exported fields of struct type rt
+func structFields(rt reflect.Type) []Field {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	var fields []Field
+	if rt.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		if f := rt.Field(i); f.PkgPath == "" {
+			fields = append(fields, Field{Name: f.Name})
+		}
+	}
+	return fields
+}
+
+func names(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// format formats value of CSV column, NULL values (nil pointers and NULL
+// driver values) are empty
+func format(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return string(v)
+	case driver.Valuer:
+		value, err := v.Value()
+		if err != nil || value == nil {
+			return ""
+		}
+		return format(value)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+type order struct {
+	ID        int
+	Name      *string
+	CreatedAt time.Time
+	internal  bool
+}
+
+func newOrders() []order {
+	name := "a,b"
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []order{
+		{ID: 1, Name: &name, CreatedAt: createdAt},
+		{ID: 2, CreatedAt: createdAt},
+		{ID: 3, CreatedAt: createdAt},
+	}
+}
+
+func newPaginator() *paginator.Paginator {
+	p := paginator.New()
+	p.SetKeys("ID")
+	p.SetOrder(paginator.ASC)
+	p.SetLimit(2)
+	return p
+}
+
+func queryOf(rows []order) func() paginator.Query {
+	return func() paginator.Query {
+		var out []order
+		return paginatortest.NewQuery("orders", rows, &out)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	var buf strings.Builder
+	assert.Nil(t, CSV(&buf, newPaginator(), queryOf(newOrders())))
+	assert.Equal(t, strings.Join([]string{
+		"ID,Name,CreatedAt",
+		`1,"a,b",2020-01-02T03:04:05Z`,
+		"2,,2020-01-02T03:04:05Z",
+		"3,,2020-01-02T03:04:05Z",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestCSVFields(t *testing.T) {
+	fields := []Field{
+		{Name: "order_id", Key: "ID"},
+		{Name: "label", Value: func(row interface{}) interface{} {
+			return strings.Repeat("x", row.(order).ID)
+		}},
+	}
+	var buf strings.Builder
+	assert.Nil(t, CSV(&buf, newPaginator(), queryOf(newOrders()), fields...))
+	assert.Equal(t, "order_id,label\n1,x\n2,xx\n3,xxx\n", buf.String())
+
+	// header is written for empty exports
+	buf.Reset()
+	assert.Nil(t, CSV(&buf, newPaginator(), queryOf(nil), fields...))
+	assert.Equal(t, "order_id,label\n", buf.String())
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf strings.Builder
+	assert.Nil(t, NDJSON(&buf, newPaginator(), queryOf(newOrders()), Field{Name: "id", Key: "ID"}, Field{Name: "name", Key: "Name"}))
+	assert.Equal(t, `{"id":1,"name":"a,b"}`+"\n"+`{"id":2,"name":null}`+"\n"+`{"id":3,"name":null}`+"\n", buf.String())
+
+	buf.Reset()
+	assert.Nil(t, NDJSON(&buf, newPaginator(), queryOf(newOrders()[2:])))
+	assert.Equal(t, `{"ID":3,"Name":null,"CreatedAt":"2020-01-02T03:04:05Z"}`+"\n", buf.String())
+}
+
+func TestExportError(t *testing.T) {
+	p := newPaginator()
+	p.SetKeys("Unknown")
+	err := NDJSON(&strings.Builder{}, p, queryOf(newOrders()))
+	assert.True(t, errors.Is(err, paginator.ErrInvalidKey))
+}
@@ -0,0 +1,91 @@
+// Package fiber binds paging query parameters of gofiber requests to
+// paginator and attaches next and prev page links to responses
+package fiber
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/openapi"
+)
+
+// Errors for binding paging parameters
+var (
+	ErrInvalidLimit = paginator.ErrInvalidLimit
+	ErrInvalidOrder = paginator.ErrInvalidOrder
+)
+
+// Options of middleware
+type Options struct {
+	// New creates paginator of each request with keys and other options,
+	// default is paginator.New
+	New func(c *fiber.Ctx) *paginator.Paginator
+	// MaxLimit caps limit of requests, no cap if it is zero
+	MaxLimit int
+	// LinkHeader sets whether to write Link header with next and prev page
+	// links to responses
+	LinkHeader bool
+	// ErrorHandler handles invalid parameters, default responds 400 Bad
+	// Request with error message
+	ErrorHandler func(c *fiber.Ctx, err error) error
+}
+
+const localsKey = "paginator"
+
+// Middleware parses after, before, limit and order query parameters into a
+// paginator stored in locals of context (see FromContext)
+func Middleware(opts Options) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, err := Bind(c, opts)
+		if err != nil {
+			if opts.ErrorHandler != nil {
+				return opts.ErrorHandler(c, err)
+			}
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
+		c.Locals(localsKey, p)
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if opts.LinkHeader {
+			SetLinks(c, p)
+		}
+		return nil
+	}
+}
+
+// FromContext returns paginator stored by middleware, it is nil if there is
+// none
+func FromContext(c *fiber.Ctx) *paginator.Paginator {
+	p, _ := c.Locals(localsKey).(*paginator.Paginator)
+	return p
+}
+
+// Bind creates paginator by opts and binds paging query parameters of c to
+// it
+func Bind(c *fiber.Ctx, opts Options) (*paginator.Paginator, error) {
+	p := paginator.New()
+	if opts.New != nil {
+		p = opts.New(c)
+	}
+	if err := openapi.Bind(p, query(c), opts.MaxLimit); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetLinks sets Link header with next and prev page links of c by paginated
+// page of p
+func SetLinks(c *fiber.Ctx, p *paginator.Paginator) {
+	if links := openapi.Links(c.Path(), query(c), p); links != "" {
+		c.Set(fiber.HeaderLink, links)
+	}
+}
+
+// query returns query parameters of c
+func query(c *fiber.Ctx) url.Values {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	return query
+}
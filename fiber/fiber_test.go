@@ -0,0 +1,85 @@
+package fiber
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+type order struct {
+	ID int
+}
+
+type response struct {
+	Items  []order          `json:"items"`
+	Paging paginator.Paging `json:"paging"`
+}
+
+func serve(t *testing.T, opts Options, target string) (*http.Response, []int) {
+	rows := []order{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+	app := fiber.New()
+	app.Get("/orders", Middleware(opts), func(c *fiber.Ctx) error {
+		p := FromContext(c)
+		var out []order
+		if _, err := p.Paginate(paginatortest.NewQuery("orders", rows, &out)); err != nil {
+			return err
+		}
+		return c.JSON(response{Items: out, Paging: p.GetPaging()})
+	})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	var page response
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+	ids := make([]int, len(page.Items))
+	for i, o := range page.Items {
+		ids[i] = o.ID
+	}
+	return resp, ids
+}
+
+func TestMiddleware(t *testing.T) {
+	opts := Options{MaxLimit: 2, LinkHeader: true}
+	resp, ids := serve(t, opts, "/orders?limit=10&order=asc&status=paid")
+	assert.Equal(t, []int{1, 2}, ids)
+
+	link := resp.Header.Get("Link")
+	assert.True(t, strings.HasPrefix(link, "</orders?after="), link)
+	assert.Contains(t, link, `status=paid`)
+	assert.Contains(t, link, `rel="next"`)
+	assert.NotContains(t, link, `rel="prev"`)
+
+	next := strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+	resp, ids = serve(t, opts, next)
+	assert.Equal(t, []int{3, 4}, ids)
+	assert.Contains(t, resp.Header.Get("Link"), `rel="prev"`)
+}
+
+func TestMiddlewareShouldRejectInvalidParams(t *testing.T) {
+	resp, _ := serve(t, Options{}, "/orders?limit=abc")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), ErrInvalidLimit.Error())
+
+	var handled error
+	resp, _ = serve(t, Options{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		handled = err
+		return c.SendStatus(fiber.StatusUnprocessableEntity)
+	}}, "/orders?order=up")
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.True(t, errors.Is(handled, ErrInvalidOrder))
+}
@@ -0,0 +1,73 @@
+package paginator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCursorFingerprintMismatch is returned when cursor is issued for a query
+// with different keys, order or filter
+var ErrCursorFingerprintMismatch = errors.New("cursor does not match query")
+
+// SetCursorFingerprint sets whether to bind cursors to fingerprint of the
+// query, which is a hash of paging keys, order and filter hash (see
+// SetFilterHash). Cursors of other queries are rejected by
+// ErrCursorFingerprintMismatch. Enabling fingerprint invalidates cursors
+// issued without it, and vice versa.
+func (p *Paginator) SetCursorFingerprint(enabled bool) {
+	p.fingerprint = enabled
+}
+
+// SetFilterHash sets caller-supplied hash of query filters included in
+// cursor fingerprint, so that cursors cannot be replayed with other filters
+func (p *Paginator) SetFilterHash(hash string) {
+	p.filterHash = hash
+}
+
+// SetFilters registers active filters of the query, e.g.
+// {"status": "open", "owner": 42}, and enables cursor fingerprint, so that
+// cursors presented with different filters are rejected by
+// ErrCursorFingerprintMismatch. Filter values should be JSON marshalable,
+// and values of different types (e.g. 42 and "42") are different filters.
+func (p *Paginator) SetFilters(filters map[string]interface{}) {
+	p.filterHash = hashFilters(filters)
+	p.fingerprint = true
+}
+
+// hashFilters returns hash of filters regardless of order of map entries
+func hashFilters(filters map[string]interface{}) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	// keys of maps are marshaled in sorted order
+	b, err := json.Marshal(filters)
+	if err != nil {
+		b = []byte(fmt.Sprintf("%#v", filters))
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:8])
+}
+
+func (p *Paginator) getFingerprint() string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(p.tableKeys, ",")))
+	h.Write([]byte{0})
+	h.Write([]byte(p.order))
+	for _, rule := range p.rules {
+		// keys without their own order keep fingerprints of prior cursors
+		if rule.Order != "" {
+			h.Write([]byte("," + rule.Key + " " + string(rule.Order)))
+		}
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(p.filterHash))
+	if partition := p.getPartitionHash(); partition != "" {
+		h.Write([]byte{0})
+		h.Write([]byte(partition))
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
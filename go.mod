@@ -3,8 +3,12 @@ module github.com/savvi-ai/gorm-cursor-paginator
 go 1.14
 
 require (
+	github.com/gofiber/fiber/v2 v2.1.0
 	github.com/iancoleman/strcase v0.0.0-20180726023541-3605ed457bf7
+	github.com/jinzhu/inflection v1.0.0
+	github.com/prometheus/client_golang v1.7.1
 	github.com/stretchr/testify v1.5.1
+	google.golang.org/protobuf v1.23.0
 	gorm.io/driver/mysql v0.3.1
 	gorm.io/driver/sqlite v1.0.9
 	gorm.io/gorm v0.2.28
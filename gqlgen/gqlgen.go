@@ -0,0 +1,127 @@
+// Package gqlgen maps paginator to Relay connections of gqlgen, PageInfo can
+// be bound to PageInfo type of GraphQL schema in gqlgen.yml:
+//
+//	models:
+//	  PageInfo:
+//	    model: github.com/savvi-ai/gorm-cursor-paginator/gqlgen.PageInfo
+package gqlgen
+
+import (
+	"errors"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Errors of connection arguments
+var (
+	ErrMixedDirection = errors.New("first and after cannot be used with last or before")
+	ErrInvalidFirst   = errors.New("first should be positive")
+	ErrInvalidLast    = errors.New("last should be positive")
+	ErrLimitExceeded  = errors.New("first or last exceeds max limit")
+)
+
+// CodeBadUserInput is error code of invalid arguments and cursors
+const CodeBadUserInput = "BAD_USER_INPUT"
+
+// PageInfo is Relay PageInfo of paginated page
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// Args are Relay connection arguments
+type Args struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+}
+
+// Bind binds connection arguments to p, either forward pagination by first
+// and after, or backward pagination by last and before. Max limit caps first and
+// last if it is positive. Errors are mapped by MapError.
+func Bind(p *paginator.Paginator, args Args, maxLimit int) error {
+	forward, backward := args.First != nil || args.After != nil, args.Last != nil || args.Before != nil
+	if forward && backward {
+		return MapError(ErrMixedDirection)
+	}
+	limit := args.First
+	if backward {
+		// last without before paginates backward from the end
+		before := ""
+		if args.Before != nil {
+			before = *args.Before
+		}
+		p.SetBeforeCursor(before)
+		limit = args.Last
+	} else if args.After != nil {
+		p.SetAfterCursor(*args.After)
+	}
+	if limit != nil {
+		if *limit <= 0 {
+			if args.First != nil {
+				return MapError(ErrInvalidFirst)
+			}
+			return MapError(ErrInvalidLast)
+		}
+		if maxLimit > 0 && *limit > maxLimit {
+			return MapError(ErrLimitExceeded)
+		}
+		p.SetLimit(*limit)
+	}
+	return nil
+}
+
+// NewPageInfo returns PageInfo of paginated page of p
+func NewPageInfo(p *paginator.Paginator) PageInfo {
+	next := p.GetNextCursor()
+	info := PageInfo{
+		HasNextPage:     next.After != nil,
+		HasPreviousPage: next.Before != nil,
+	}
+	if cursors := p.GetCursors(); len(cursors) > 0 {
+		info.StartCursor, info.EndCursor = &cursors[0], &cursors[len(cursors)-1]
+	}
+	return info
+}
+
+// Error is a GraphQL error with extensions, which gqlgen presents by
+// graphql.ExtendedError
+type Error struct {
+	Err  error
+	Code string
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Extensions implements graphql.ExtendedError of gqlgen
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+// MapError maps errors of arguments and cursors to Error with code
+// BAD_USER_INPUT, other errors are returned as they are
+func MapError(err error) error {
+	for _, target := range []error{
+		ErrMixedDirection,
+		ErrInvalidFirst,
+		ErrInvalidLast,
+		ErrLimitExceeded,
+		paginator.ErrInvalidCursor,
+		paginator.ErrCursorExpired,
+		paginator.ErrCursorFingerprintMismatch,
+	} {
+		if errors.Is(err, target) {
+			return &Error{Err: err, Code: CodeBadUserInput}
+		}
+	}
+	return err
+}
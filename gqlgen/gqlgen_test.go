@@ -0,0 +1,82 @@
+package gqlgen
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+type order struct {
+	ID int
+}
+
+func paginate(t *testing.T, args Args) ([]int, PageInfo) {
+	rows := []order{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+	p := paginator.New()
+	p.SetOrder(paginator.ASC)
+	assert.NoError(t, Bind(p, args, 10))
+	var out []order
+	_, err := p.Paginate(paginatortest.NewQuery("orders", rows, &out))
+	assert.NoError(t, err)
+	ids := make([]int, len(out))
+	for i, o := range out {
+		ids[i] = o.ID
+	}
+	return ids, NewPageInfo(p)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestBind(t *testing.T) {
+	ids, info := paginate(t, Args{First: intPtr(2)})
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.True(t, info.HasNextPage)
+	assert.False(t, info.HasPreviousPage)
+
+	ids, info = paginate(t, Args{First: intPtr(2), After: info.EndCursor})
+	assert.Equal(t, []int{3, 4}, ids)
+	assert.True(t, info.HasNextPage)
+	assert.True(t, info.HasPreviousPage)
+
+	ids, info = paginate(t, Args{Last: intPtr(2), Before: info.StartCursor})
+	assert.Equal(t, []int{1, 2}, ids)
+	assert.False(t, info.HasPreviousPage)
+
+	ids, _ = paginate(t, Args{Last: intPtr(2)})
+	assert.Equal(t, []int{4, 5}, ids)
+}
+
+func TestBindShouldMapErrors(t *testing.T) {
+	cursor := "cursor"
+	for args, expected := range map[*Args]error{
+		{First: intPtr(1), Last: intPtr(1)}: ErrMixedDirection,
+		{First: intPtr(1), Before: &cursor}: ErrMixedDirection,
+		{First: intPtr(0)}:                  ErrInvalidFirst,
+		{Last: intPtr(-1)}:                  ErrInvalidLast,
+		{First: intPtr(11)}:                 ErrLimitExceeded,
+	} {
+		err := Bind(paginator.New(), *args, 10)
+		assert.True(t, errors.Is(err, expected), err)
+		var gqlErr *Error
+		assert.True(t, errors.As(err, &gqlErr))
+		assert.Equal(t, map[string]interface{}{"code": CodeBadUserInput}, gqlErr.Extensions())
+	}
+}
+
+func TestMapError(t *testing.T) {
+	p := paginator.New()
+	p.SetAfterCursor("invalid")
+	var out []order
+	_, err := p.Paginate(paginatortest.NewQuery("orders", []order{}, &out))
+	var gqlErr *Error
+	assert.True(t, errors.As(MapError(err), &gqlErr))
+
+	other := errors.New("other")
+	assert.Equal(t, other, MapError(other))
+}
@@ -0,0 +1,69 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Hint is an optimizer hint injected into paginated query, for cases where
+// planner picks a bad plan for the cursor predicate
+type Hint struct {
+	// Table is placed after table in FROM clause, e.g. USE INDEX (idx) of
+	// MySQL or INDEXED BY idx of SQLite
+	Table string
+	// Comment is placed as /*+ ... */ comment at the beginning of statement
+	// on Postgres (pg_hint_plan) and right after SELECT otherwise, e.g.
+	// IndexScan(orders idx)
+	Comment string
+}
+
+// HintQuery is a Query supporting optimizer hints, which is required to
+// inject hints
+type HintQuery interface {
+	Query
+	// Hint returns a new query with hint injected
+	Hint(hint Hint) Query
+}
+
+// ErrHintNotSupported is returned when query does not implement HintQuery
+// with hint set
+var ErrHintNotSupported = errors.New("query should implement HintQuery to inject optimizer hints")
+
+// SetHint sets optimizer hint injected into paginated query, see IndexHint
+// for hints to use an index
+func (p *Paginator) SetHint(hint Hint) {
+	p.hint = hint
+}
+
+// IndexHint returns hint to use index of table in dialect: USE INDEX on
+// MySQL, IndexScan of pg_hint_plan on Postgres, INDEXED BY on SQLite, table
+// hint INDEX on SQL Server and INDEX hint on Oracle
+func IndexHint(dialect Dialect, table, index string) Hint {
+	switch dialect {
+	case MySQL:
+		return Hint{Table: fmt.Sprintf("USE INDEX (%s)", dialect.quote(index))}
+	case Postgres:
+		return Hint{Comment: fmt.Sprintf("IndexScan(%s %s)", table, index)}
+	case SQLite:
+		return Hint{Table: fmt.Sprintf("INDEXED BY %s", dialect.quote(index))}
+	case SQLServer:
+		return Hint{Table: fmt.Sprintf("WITH (INDEX(%s))", dialect.quote(index))}
+	case Oracle:
+		return Hint{Comment: fmt.Sprintf("INDEX(%s %s)", table, index)}
+	default:
+		return Hint{}
+	}
+}
+
+func (p *Paginator) appendHint(query Query) (Query, error) {
+	p.snapshot = p.getSnapshotTime()
+	hint := p.getHint()
+	if hint == (Hint{}) {
+		return query, nil
+	}
+	hq, ok := query.(HintQuery)
+	if !ok {
+		return query, ErrHintNotSupported
+	}
+	return hq.Hint(hint), nil
+}
@@ -0,0 +1,62 @@
+package paginator
+
+// BeforePaginateHook is invoked with the assembled query right before it is
+// executed, the returned query is executed instead
+type BeforePaginateHook func(query Query) (Query, error)
+
+// AfterPaginateHook is invoked with the paginated result (the out of query)
+// and cursor for next pagination
+type AfterPaginateHook func(result interface{}, cursor Cursor) error
+
+// SetBeforePaginate appends hooks invoked before query is executed, hooks
+// are invoked in order and an error aborts pagination
+func (p *Paginator) SetBeforePaginate(hooks ...BeforePaginateHook) {
+	p.before = append(p.before, hooks...)
+}
+
+// SetAfterPaginate appends hooks invoked after query is executed, hooks are
+// invoked in order and an error is returned from Paginate
+func (p *Paginator) SetAfterPaginate(hooks ...AfterPaginateHook) {
+	p.after = append(p.after, hooks...)
+}
+
+func (p *Paginator) beforePaginate(query Query) (Query, error) {
+	var err error
+	for _, hook := range p.before {
+		if query, err = hook(query); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+func (p *Paginator) afterPaginate(result interface{}) error {
+	for _, hook := range p.after {
+		if err := hook(result, p.next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransformHook transforms or filters page (pointer to slice of model) in
+// place, e.g. permission-based redaction, before cursors are encoded from its
+// first and last rows
+type TransformHook func(page interface{}) error
+
+// SetTransform appends hooks transforming page before cursors are encoded,
+// so that cursors are consistent with rows clients receive. Cursors are
+// encoded from original boundary rows if all rows are removed. Hooks are
+// invoked in order and an error is returned from Paginate.
+func (p *Paginator) SetTransform(hooks ...TransformHook) {
+	p.transform = append(p.transform, hooks...)
+}
+
+func (p *Paginator) transformPage(page interface{}) error {
+	for _, hook := range p.transform {
+		if err := hook(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// IndexQuery is a Query able to list indexes of its table, which is required
+// by index advisor
+type IndexQuery interface {
+	Query
+	// Indexes returns indexes of the table
+	Indexes() ([]Index, error)
+}
+
+// Index is an index of table with its columns in index order
+type Index struct {
+	Name    string
+	Columns []string
+}
+
+// Catalog queries listing index name and column name of table (the only arg)
+// in index order, which can be used to implement IndexQuery
+const (
+	MySQLIndexesSQL = "SELECT index_name, column_name FROM information_schema.statistics " +
+		"WHERE table_schema = DATABASE() AND table_name = ? ORDER BY index_name, seq_in_index"
+	PostgresIndexesSQL = "SELECT i.relname, a.attname FROM pg_index x " +
+		"JOIN pg_class t ON t.oid = x.indrelid JOIN pg_class i ON i.oid = x.indexrelid " +
+		"JOIN LATERAL unnest(x.indkey) WITH ORDINALITY AS k(attnum, ord) ON true " +
+		"JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum " +
+		"WHERE t.relname = ? ORDER BY i.relname, k.ord"
+	SQLiteIndexesSQL = "SELECT il.name, ii.name FROM pragma_index_list(?) il, " +
+		"pragma_index_info(il.name) ii ORDER BY il.name, ii.seqno"
+)
+
+// ErrIndexAdvisorNotSupported is returned when index advisor is enabled but
+// query does not implement IndexQuery
+var ErrIndexAdvisorNotSupported = errors.New("query should implement IndexQuery to use index advisor")
+
+// SetIndexAdvisor sets whether to check that some index of table leads with
+// key columns, a warning is sent to the logger hook if there is none. Once an
+// index is found, table and keys are not checked again by the paginator and
+// its clones, tables without index are checked again by each page so that
+// indexes added later are found. Keys of expressions are not checked.
+func (p *Paginator) SetIndexAdvisor(enabled bool) {
+	p.indexAdvisor = enabled
+	if enabled && p.advised == nil {
+		p.advised = new(sync.Map)
+	}
+}
+
+func (p *Paginator) adviseIndex(query Query) error {
+	if !p.indexAdvisor {
+		return nil
+	}
+	iq, ok := query.(IndexQuery)
+	if !ok {
+		return ErrIndexAdvisorNotSupported
+	}
+	columns, ok := p.getKeyColumns()
+	if !ok {
+		return nil
+	}
+	table := query.Table()
+	key := table + ":" + strings.Join(columns, ",")
+	if _, ok := p.advised.Load(key); ok {
+		return nil
+	}
+	indexes, err := iq.Indexes()
+	if err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("index advisor: %s", err))
+		return nil
+	}
+	for _, index := range indexes {
+		if hasPrefixColumns(index.Columns, columns) {
+			p.advised.Store(key, true)
+			return nil
+		}
+	}
+	p.warnings = append(p.warnings, fmt.Sprintf(
+		"index advisor: no index of %s leads with paging keys (%s)", table, strings.Join(columns, ", "),
+	))
+	return nil
+}
+
+// getKeyColumns returns columns of keys, false if any key is an expression
+func (p *Paginator) getKeyColumns() ([]string, bool) {
+	columns := make([]string, len(p.rules))
+	for i, rule := range p.rules {
+		if rule.SQLRepr != "" || rule.CaseInsensitive || len(rule.Coalesce) > 0 || len(rule.JSONPath) > 0 {
+			return nil, false
+		}
+		columns[i] = rule.column()
+	}
+	return columns, true
+}
+
+func hasPrefixColumns(columns, prefix []string) bool {
+	if len(columns) < len(prefix) {
+		return false
+	}
+	for i, column := range prefix {
+		if !strings.EqualFold(columns[i], column) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package paginator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// LegacyDecoder converts cursor issued by an older version or fork of this
+// library into JSON array of key values, which are then decoded as values of
+// current cursors. An error indicates cursor is not of its format.
+type LegacyDecoder func(cursor string) ([]byte, error)
+
+// SetLegacyDecoders sets fallback decoders of cursors which cannot be decoded
+// by current format, they are tried in order during a migration window.
+// Legacy cursors are accepted without fingerprint and issued-at time.
+func (p *Paginator) SetLegacyDecoders(decoders ...LegacyDecoder) {
+	p.legacy = append(p.legacy, decoders...)
+}
+
+// NewSeparatedLegacyDecoder creates decoder of cursors which are key values
+// joined by sep and encoded by encoding. Values which are valid JSON (e.g.
+// numbers and booleans) are kept as they are, others are taken as strings.
+func NewSeparatedLegacyDecoder(encoding *base64.Encoding, sep string) LegacyDecoder {
+	return func(cursor string) ([]byte, error) {
+		b, err := encoding.DecodeString(cursor)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(string(b), sep)
+		values := make([]json.RawMessage, len(parts))
+		for i, part := range parts {
+			if json.Valid([]byte(part)) {
+				values[i] = json.RawMessage(part)
+			} else if values[i], err = json.Marshal(part); err != nil {
+				return nil, err
+			}
+		}
+		return json.Marshal(values)
+	}
+}
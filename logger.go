@@ -0,0 +1,48 @@
+package paginator
+
+import "time"
+
+// RedactedArg replaces args of LogEntry unless verbose mode is set
+const RedactedArg = "[REDACTED]"
+
+// Logger logs each Paginate call
+type Logger interface {
+	Log(entry LogEntry)
+}
+
+// LogEntry is the generated SQL and timing of a Paginate call
+type LogEntry struct {
+	// Where is the cursor predicate, empty if there is no cursor
+	Where string
+	// Args are args of the cursor predicate
+	Args     []interface{}
+	Order    string
+	Limit    int
+	Duration time.Duration
+	Err      error
+	// Warnings are warnings of pagination, e.g. from index advisor
+	Warnings []string
+	// Plan is query plan of the paginated query in explain mode
+	Plan string
+}
+
+// SetLogger sets logger logging each Paginate call
+func (p *Paginator) SetLogger(logger Logger) {
+	p.logger = logger
+}
+
+func (p *Paginator) log(start time.Time, err error) {
+	if p.logger == nil {
+		return
+	}
+	p.logger.Log(LogEntry{
+		Where:    p.where,
+		Args:     p.redactArgs(p.args),
+		Order:    p.orderBy,
+		Limit:    p.fetchLimit(),
+		Duration: time.Since(start),
+		Err:      err,
+		Warnings: p.warnings,
+		Plan:     p.plan,
+	})
+}
@@ -0,0 +1,99 @@
+package paginator
+
+import (
+	"errors"
+	"reflect"
+)
+
+// LookAhead mode to determine whether there are more rows after the page
+type LookAhead string
+
+// Look-ahead modes
+const (
+	// LookAheadRow fetches one extra row after the page
+	LookAheadRow LookAhead = "row"
+	// LookAheadExists fetches exactly limit rows, then checks more rows by a
+	// separate EXISTS query, which requires query to implement ExistsQuery
+	LookAheadExists LookAhead = "exists"
+	// LookAheadNone fetches exactly limit rows and assumes more rows if the
+	// page is full, the last page may then be empty
+	LookAheadNone LookAhead = "none"
+)
+
+// ExistsQuery is a Query supporting checking existence of rows, which is
+// required for LookAheadExists mode
+type ExistsQuery interface {
+	Query
+	// Exists returns whether the query has any row
+	Exists() (bool, error)
+}
+
+// ErrExistsNotSupported is returned when query does not implement ExistsQuery
+// in LookAheadExists mode
+var ErrExistsNotSupported = errors.New("query should implement ExistsQuery to look ahead by EXISTS query")
+
+// SetLookAhead sets mode to determine whether there are more rows after the
+// page, default is LookAheadRow
+func (p *Paginator) SetLookAhead(lookAhead LookAhead) {
+	p.lookAhead = lookAhead
+}
+
+// fetchLimit returns number of rows to fetch for the page, which is Unlimited
+// if rows are not limited
+func (p *Paginator) fetchLimit() int {
+	if p.unlimited() {
+		return Unlimited
+	}
+	if p.lookAhead == LookAheadExists || p.lookAhead == LookAheadNone {
+		return p.limit
+	}
+	return p.limit + 1
+}
+
+// lookAheadMore returns whether there are more rows after fetched rows of out
+// in paging order, base is the query without cursor predicate
+func (p *Paginator) lookAheadMore(base Query, out interface{}) (bool, error) {
+	if p.unlimited() {
+		return false, nil
+	}
+	n := pageLen(out)
+	switch p.lookAhead {
+	case LookAheadExists:
+		if n < p.limit {
+			return false, nil
+		}
+		return p.exists(base, reflect.ValueOf(out).Elem().Index(n-1))
+	case LookAheadNone:
+		return n == p.limit, nil
+	default:
+		return n > p.limit, nil
+	}
+}
+
+// exists checks rows after last row by base query
+func (p *Paginator) exists(base Query, last reflect.Value) (bool, error) {
+	cursor := p.newCursorEncoder().Encode(last)
+	fields, err := p.decodeToken(base, cursor)
+	if err != nil {
+		return false, err
+	}
+	probe := *p
+	// the last row itself is not a row after it
+	probe.inclusive = false
+	if !probe.hasAfterCursor() && !probe.hasBeforeCursor() {
+		probe.cursor.After = new(string)
+	}
+	probe.where, probe.args = probe.buildCursorQuery(fields)
+	query, err := probe.appendCursorQuery(base)
+	if err != nil {
+		return false, err
+	}
+	if query, err = p.route(query.Limit(1), QueryExists); err != nil {
+		return false, err
+	}
+	eq, ok := query.(ExistsQuery)
+	if !ok {
+		return false, ErrExistsNotSupported
+	}
+	return eq.Exists()
+}
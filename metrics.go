@@ -0,0 +1,36 @@
+package paginator
+
+import "time"
+
+// Metrics records metrics of Paginate calls, see subpackage prometheus for
+// a Prometheus implementation
+type Metrics interface {
+	// ObservePage observes a served page by its fill ratio (rows of the page
+	// divided by limit, 1 if rows are not limited) and duration of the
+	// query
+	ObservePage(fillRatio float64, duration time.Duration)
+	// IncInvalidCursors counts a rejected invalid cursor
+	IncInvalidCursors()
+}
+
+// SetMetrics sets metrics recording each Paginate call
+func (p *Paginator) SetMetrics(metrics Metrics) {
+	p.metrics = metrics
+}
+
+func (p *Paginator) observe(start time.Time, err error) {
+	if p.metrics == nil {
+		return
+	}
+	if p.invalidCursor {
+		p.metrics.IncInvalidCursors()
+	}
+	if err != nil {
+		return
+	}
+	fillRatio := 1.0
+	if !p.unlimited() {
+		fillRatio = float64(p.count) / float64(p.limit)
+	}
+	p.metrics.ObservePage(fillRatio, time.Since(start))
+}
@@ -0,0 +1,64 @@
+package paginator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+func (p *Paginator) hasNullsKey() bool {
+	for _, rule := range p.rules {
+		if rule.Nulls != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isNullsLast reports whether NULL values of rule come last in paging order,
+// which is reversed if order is flipped
+func (p *Paginator) isNullsLast(rule Rule, flipped bool) bool {
+	return (rule.Nulls == NullsLast) != flipped
+}
+
+// getNullsCursorQuery returns cursor predicate and its args with NULL values
+// placed by Nulls of keys, keys without Nulls are compared as is
+func (p *Paginator) getNullsCursorQuery(fields []interface{}) (string, []interface{}) {
+	flipped := p.hasBeforeCursor()
+	var qs []string
+	var args, compositeArgs []interface{}
+	composite := ""
+	for i, sqlKey := range p.tableKeys {
+		rule := p.rules[i]
+		arg := toQueryArg(fields[i])
+		isNull := rule.Nulls != "" && isNullField(fields[i])
+		nullsLast := p.isNullsLast(rule, flipped)
+		switch {
+		case isNull && nullsLast:
+			// no rows come after NULL values in this key
+		case isNull:
+			qs = append(qs, fmt.Sprintf("%s%s IS NOT NULL", composite, sqlKey))
+			args = append(args, compositeArgs...)
+		case rule.Nulls != "" && nullsLast:
+			qs = append(qs, fmt.Sprintf("%s(%s %s %s OR %s IS NULL)", composite, sqlKey, rule.operator(p.getOperator(rule)), rule.placeholder(), sqlKey))
+			args = append(append(args, compositeArgs...), arg)
+		default:
+			qs = append(qs, fmt.Sprintf("%s%s %s %s", composite, sqlKey, rule.operator(p.getOperator(rule)), rule.placeholder()))
+			args = append(append(args, compositeArgs...), arg)
+		}
+		if isNull {
+			composite = fmt.Sprintf("%s%s IS NULL AND ", composite, sqlKey)
+		} else {
+			composite = fmt.Sprintf("%s%s %s %s AND ", composite, sqlKey, rule.operator("="), rule.placeholder())
+			compositeArgs = append(compositeArgs, arg)
+		}
+	}
+	if len(qs) == 0 {
+		return "1 = 0", nil
+	}
+	return strings.Join(qs, " OR "), args
+}
+
+func isNullField(v interface{}) bool {
+	return v == nil || isNullValue(reflect.ValueOf(v))
+}
@@ -0,0 +1,60 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OffsetQuery is a Query supporting OFFSET clause, which is required to skip
+// rows by offset fallback
+type OffsetQuery interface {
+	Query
+	// Offset sets number of rows to skip
+	Offset(n int) Query
+}
+
+// Errors of offset fallback
+var (
+	ErrOffsetNotSupported = errors.New("query should implement OffsetQuery to skip rows by offset")
+	ErrOffsetTooLarge     = errors.New("offset exceeds offset fallback, paginate by cursor instead")
+)
+
+// SetOffsetFallback sets max offset skips are served by plain OFFSET, e.g.
+// 3 pages of rows for jumping a couple pages, so that APIs supporting both
+// page numbers and cursors use one paginator. Larger offsets are rejected by
+// ErrOffsetTooLarge since they scan skipped rows, cursors of pages are keyset
+// cursors regardless of offset.
+func (p *Paginator) SetOffsetFallback(maxOffset int) {
+	p.maxOffset = maxOffset
+}
+
+// SetOffset sets number of rows to skip in paging direction, e.g.
+// (page-1)*limit of page numbers, which are skipped from the cursor if any
+// (see SetOffsetFallback). Only the first page of EachPage is skipped.
+func (p *Paginator) SetOffset(offset int) {
+	p.offset = offset
+}
+
+// appendOffset appends OFFSET clause of offset to query
+func (p *Paginator) appendOffset(query Query) (Query, error) {
+	if p.offset <= 0 {
+		return query, nil
+	}
+	if p.offset > p.maxOffset {
+		return query, fmt.Errorf("%w: %d > %d", ErrOffsetTooLarge, p.offset, p.maxOffset)
+	}
+	oq, ok := query.(OffsetQuery)
+	if !ok {
+		return query, ErrOffsetNotSupported
+	}
+	return oq.Offset(p.offset), nil
+}
+
+// hasPrevious reports whether there are rows before page in paging order,
+// hasMore is whether there are more rows after page in query order
+func (p *Paginator) hasPrevious(hasMore bool) bool {
+	if p.hasBeforeCursor() {
+		return hasMore
+	}
+	return p.hasAfterCursor() || p.offset > 0
+}
@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Bind binds paging query parameters to p as FromValues of paginator does,
+// limit is capped by maxLimit unless it is zero. It is shared by binders of
+// web frameworks, e.g. subpackages chi and fiber.
+func Bind(p *paginator.Paginator, query url.Values, maxLimit int) error {
+	if s := query.Get(ParamLimit); maxLimit > 0 && s != "" {
+		if limit, err := strconv.Atoi(s); err == nil && limit > maxLimit {
+			capped := make(url.Values, len(query))
+			for k, v := range query {
+				capped[k] = v
+			}
+			capped.Set(ParamLimit, strconv.Itoa(maxLimit))
+			query = capped
+		}
+	}
+	return p.FromValues(query)
+}
+
+// Links returns Link header value with next and prev page links of paginated
+// page of p, which are path with query parameters and cursor of the page, it
+// is empty if there is neither
+func Links(path string, query url.Values, p *paginator.Paginator) string {
+	var links []string
+	if next := p.NextValues(query); next != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(path, next)))
+	}
+	if prev := p.PrevValues(query); prev != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(path, prev)))
+	}
+	return strings.Join(links, ", ")
+}
+
+func pageURL(path string, query url.Values) string {
+	u := url.URL{Path: path, RawQuery: query.Encode()}
+	return u.String()
+}
@@ -0,0 +1,136 @@
+// Package openapi emits OpenAPI 3 definitions of paging parameters and
+// responses, so that API specs stay in sync with the paginator, and binds
+// the parameters for binders of web frameworks
+package openapi
+
+import (
+	"fmt"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Names of paging query parameters
+const (
+	ParamAfter  = "after"
+	ParamBefore = "before"
+	ParamLimit  = "limit"
+	ParamOrder  = "order"
+)
+
+// Parameter is an OpenAPI 3 parameter object
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Schema is an OpenAPI 3 schema object, only with fields used by paging
+// definitions
+type Schema struct {
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	Minimum     *int               `json:"minimum,omitempty"`
+	Maximum     *int               `json:"maximum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+}
+
+// Options of paging parameters, zero values fall back to defaults of
+// paginator
+type Options struct {
+	// DefaultLimit is limit of page if limit is not given, default is 10
+	DefaultLimit int
+	// MaxLimit is the max accepted limit, no max if it is zero
+	MaxLimit int
+	// DefaultOrder is order of page if order is not given, default is DESC
+	DefaultOrder paginator.Order
+}
+
+const (
+	defaultLimit = 10
+	defaultOrder = paginator.DESC
+)
+
+// Parameters returns definitions of after, before, limit and order query
+// parameters
+func Parameters(opts Options) []Parameter {
+	if opts.DefaultLimit == 0 {
+		opts.DefaultLimit = defaultLimit
+	}
+	if opts.DefaultOrder == "" {
+		opts.DefaultOrder = defaultOrder
+	}
+	minLimit := 1
+	limit := &Schema{Type: "integer", Default: opts.DefaultLimit, Minimum: &minLimit}
+	if opts.MaxLimit > 0 {
+		limit.Maximum = &opts.MaxLimit
+	}
+	return []Parameter{
+		{
+			Name:        ParamAfter,
+			In:          "query",
+			Description: "Cursor of the page to paginate after, which is the next cursor of previous response",
+			Schema:      cursorSchema(),
+		},
+		{
+			Name:        ParamBefore,
+			In:          "query",
+			Description: "Cursor of the page to paginate before, which is the prev cursor of previous response",
+			Schema:      cursorSchema(),
+		},
+		{
+			Name:        ParamLimit,
+			In:          "query",
+			Description: fmt.Sprintf("Max number of items of the page, default is %d", opts.DefaultLimit),
+			Schema:      limit,
+		},
+		{
+			Name:        ParamOrder,
+			In:          "query",
+			Description: "Order of items by paging keys",
+			Schema: &Schema{
+				Type:    "string",
+				Enum:    []string{string(paginator.ASC), string(paginator.DESC)},
+				Default: string(opts.DefaultOrder),
+			},
+		},
+	}
+}
+
+// PagingSchema returns schema of paginator.Paging
+func PagingSchema() *Schema {
+	next, prev := cursorSchema(), cursorSchema()
+	next.Description = "Cursor of the next page, omitted on the last page"
+	prev.Description = "Cursor of the previous page, omitted on the first page"
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"next":     next,
+			"prev":     prev,
+			"has_next": {Type: "boolean", Description: "Whether there is a next page"},
+		},
+		Required: []string{"has_next"},
+	}
+}
+
+// PageSchema returns schema of paginator.Page of items
+func PageSchema(items *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"items":  {Type: "array", Items: items},
+			"paging": PagingSchema(),
+		},
+		Required: []string{"items", "paging"},
+	}
+}
+
+func cursorSchema() *Schema {
+	return &Schema{Type: "string", Description: "Opaque paging cursor"}
+}
@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+	"github.com/savvi-ai/gorm-cursor-paginator/paginatortest"
+)
+
+func TestParameters(t *testing.T) {
+	params := Parameters(Options{MaxLimit: 100, DefaultOrder: paginator.ASC})
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+		assert.Equal(t, "query", param.In)
+	}
+	assert.Equal(t, []string{"after", "before", "limit", "order"}, names)
+
+	b, err := json.Marshal(params[2].Schema)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"integer","default":10,"minimum":1,"maximum":100}`, string(b))
+
+	b, err = json.Marshal(params[3].Schema)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"string","enum":["ASC","DESC"],"default":"ASC"}`, string(b))
+}
+
+func TestPageSchema(t *testing.T) {
+	schema := PageSchema(&Schema{Type: "object"})
+	assert.Equal(t, []string{"items", "paging"}, schema.Required)
+	assert.Equal(t, "array", schema.Properties["items"].Type)
+
+	paging := schema.Properties["paging"]
+	assert.Equal(t, []string{"has_next"}, paging.Required)
+	assert.Equal(t, "boolean", paging.Properties["has_next"].Type)
+	assert.Equal(t, "string", paging.Properties["next"].Type)
+}
+
+type order struct {
+	ID int
+}
+
+func TestBindAndLinks(t *testing.T) {
+	rows := []order{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+	query := url.Values{"limit": {"10"}, "order": {"asc"}, "status": {"paid"}}
+	p := paginator.New()
+	assert.NoError(t, Bind(p, query, 2))
+	assert.Equal(t, "10", query.Get(ParamLimit))
+	var out []order
+	_, err := p.Paginate(paginatortest.NewQuery("orders", rows, &out))
+	assert.NoError(t, err)
+	assert.Equal(t, []order{{ID: 1}, {ID: 2}}, out)
+
+	links := Links("/orders", query, p)
+	assert.True(t, strings.HasPrefix(links, "</orders?after="), links)
+	assert.Contains(t, links, "status=paid")
+	assert.NotContains(t, links, `rel="prev"`)
+
+	next, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(links, "<"), `>; rel="next"`))
+	assert.NoError(t, err)
+	p = paginator.New()
+	assert.NoError(t, Bind(p, next.Query(), 2))
+	out = nil
+	_, err = p.Paginate(paginatortest.NewQuery("orders", rows, &out))
+	assert.NoError(t, err)
+	assert.Equal(t, []order{{ID: 3}, {ID: 4}}, out)
+	links = Links("/orders", next.Query(), p)
+	assert.Contains(t, links, `rel="next"`)
+	assert.Contains(t, links, `rel="prev"`)
+
+	err = Bind(paginator.New(), url.Values{"limit": {"0"}}, 2)
+	assert.True(t, errors.Is(err, paginator.ErrInvalidLimit))
+	err = Bind(paginator.New(), url.Values{"order": {"up"}}, 0)
+	assert.True(t, errors.Is(err, paginator.ErrInvalidOrder))
+}
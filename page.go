@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package paginator
+
+// Page is JSON response envelope of paginated page
+type Page[T any] struct {
+	Items  []T    `json:"items"`
+	Paging Paging `json:"paging"`
+}
+
+// NewPage returns envelope of paginated items, items are never encoded as
+// null
+func NewPage[T any](p *Paginator, items []T) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{Items: items, Paging: p.GetPaging()}
+}
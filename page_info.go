@@ -0,0 +1,46 @@
+package paginator
+
+// PageInfo information of paginated page
+type PageInfo struct {
+	// Total is total count of rows matching the query regardless of cursor,
+	// it is set in window mode only
+	Total *int64
+	// StartPosition and EndPosition are 1-based positions of the first and
+	// the last row of the page among all rows matching the query, they are
+	// set in window mode only
+	StartPosition *int64
+	EndPosition   *int64
+	// Resume is the incoming cursor of an empty page in its direction, e.g.
+	// when rows are deleted, so that pollers of live feeds can present it
+	// again for rows arriving later. It is empty unless page is empty.
+	Resume Cursor
+	// Limit, Order and Keys are limit, order and paging keys the page is
+	// paginated by after defaults are applied, e.g. for responses to echo
+	// them back to clients
+	Limit int
+	Order Order
+	Keys  []string
+}
+
+// GetPageInfo returns information of paginated page
+func (p *Paginator) GetPageInfo() PageInfo {
+	return p.resolved().pageInfo
+}
+
+// initApplied keeps options the page is paginated by in PageInfo
+func (p *Paginator) initApplied() {
+	p.pageInfo.Limit, p.pageInfo.Order = p.limit, p.order
+	p.pageInfo.Keys = append([]string(nil), p.keys...)
+}
+
+// initResumeCursor keeps the incoming cursor of empty page in PageInfo
+func (p *Paginator) initResumeCursor() {
+	if p.count > 0 {
+		return
+	}
+	if p.hasAfterCursor() {
+		p.pageInfo.Resume.After = p.cursor.After
+	} else if p.hasBeforeCursor() {
+		p.pageInfo.Resume.Before = p.cursor.Before
+	}
+}
@@ -0,0 +1,24 @@
+//go:build go1.18
+// +build go1.18
+
+package paginator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPage(t *testing.T) {
+	next := "next"
+	p := New()
+	p.next.After = &next
+	b, err := json.Marshal(NewPage(p, []int{1, 2}))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"items":[1,2],"paging":{"next":"next","has_next":true}}`, string(b))
+
+	b, err = json.Marshal(NewPage[int](New(), nil))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"items":[],"paging":{"has_next":false}}`, string(b))
+}
@@ -1,9 +1,13 @@
 package paginator
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/iancoleman/strcase"
 )
@@ -18,24 +22,163 @@ type Query interface {
 	Select() Query
 }
 
+// SelectQuery is a Query selecting a subset of columns, paginator adds key
+// columns missing from the selection so that cursor can be encoded
+type SelectQuery interface {
+	Query
+	// Selects returns selected columns, empty means all columns
+	Selects() []string
+	// AddSelects appends columns to the selection
+	AddSelects(columns ...string) Query
+}
+
+// DistinctOnQuery is a Query supporting DISTINCT ON clause (Postgres)
+type DistinctOnQuery interface {
+	Query
+	// DistinctOn sets DISTINCT ON expressions of the query
+	DistinctOn(expressions ...string) Query
+}
+
+// HavingQuery is a Query supporting HAVING clause, which is required for
+// paginating by aggregate keys
+type HavingQuery interface {
+	Query
+	// Having appends having condition
+	Having(query string, args ...interface{}) Query
+}
+
+// WrapQuery is a Query which can be wrapped as subquery, which is required by
+// modes placing cursor predicate on an outer query
+type WrapQuery interface {
+	Query
+	// Wrap returns a new query selecting all columns from this query as
+	// subquery aliased by alias, extra selects are appended to the selection
+	// of this query (which selects all columns of its table if not specified)
+	Wrap(alias string, selects ...string) Query
+}
+
+// Unlimited is the limit of pages fetching all rows after cursor, for batch
+// jobs which actually need all of them
+const Unlimited = -1
+
 const (
-	defaultLimit = 10
-	defaultOrder = DESC
+	defaultLimit  = 10
+	defaultOrder  = DESC
+	subqueryAlias = "paginator_subquery"
 )
 
 // New inits paginator
 func New() *Paginator {
-	return &Paginator{}
+	return &Paginator{last: new(lastPage)}
 }
 
 // Paginator a builder doing pagination
 type Paginator struct {
-	cursor    Cursor
-	next      Cursor
-	keys      []string
-	tableKeys []string
-	limit     int
-	order     Order
+	cursor       Cursor
+	next         Cursor
+	rules        []Rule
+	keys         []string
+	tableKeys    []string
+	limit        int
+	order        Order
+	tieBreaker   bool
+	distinctOn   []string
+	distinct     bool
+	union        []Query
+	totalCount   bool
+	retry        RetryPolicy
+	maxTotalRows int
+	offset       int
+	maxOffset    int
+	deferredJoin bool
+	queryTimeout time.Duration
+	// orderRules are rules only used in ORDER BY to pick row for each
+	// DISTINCT ON keys, they are neither flipped nor encoded into cursor
+	orderRules     []Rule
+	orderTableKeys []string
+	// wrapped indicates query is wrapped as subquery, keys are then
+	// referred by their columns of the subquery
+	wrapped             bool
+	windowTotalField    string
+	windowPositionField string
+	pageInfo            PageInfo
+	tracer              Tracer
+	metrics             Metrics
+	// invalidCursor indicates cursor is rejected since it cannot be decoded
+	invalidCursor bool
+	// where, args and orderBy are the built cursor predicate and ordering
+	where   string
+	args    []interface{}
+	orderBy string
+	logger  Logger
+	verbose bool
+	before  []BeforePaginateHook
+	after   []AfterPaginateHook
+	// transform are hooks transforming page before cursors are encoded
+	transform []TransformHook
+	// warnings are sent to logger with log entry
+	warnings     []string
+	indexAdvisor bool
+	explain      bool
+	plan         string
+	cursorTTL    time.Duration
+	fingerprint  bool
+	urlSafe      bool
+	compression  bool
+	compact      bool
+	filterHash   string
+	dialect      Dialect
+	predicate    Predicate
+	lookAhead    LookAhead
+	cache        Cache
+	cacheTTL     time.Duration
+	cacheKey     string
+	versionKey   string
+	legacy       []LegacyDecoder
+	encoder      CursorEncoder
+	decoder      CursorDecoder
+	skipFlip     bool
+	strict       bool
+	inclusive    bool
+	tail         bool
+	table        string
+	schema       string
+	cte          string
+	hint         Hint
+	asOf         time.Duration
+	snapshot     time.Time
+	router       RouteHook
+	// partitionKey is key pinned to partitionValue for the query
+	partitionKey   string
+	partitionValue interface{}
+	// alias is alias of the paginated side of self-joins
+	alias string
+	// sqliteVersion is version of SQLite, which decides features of SQLite
+	sqliteVersion string
+	// page is pointer to slice of paginated page, which is the out of query
+	// unless result is copied
+	page       interface{}
+	copyResult bool
+	// preallocate indicates page is pre-allocated to capacity of fetch limit
+	preallocate bool
+	// now returns current time, it is time.Now if not set
+	now func() time.Time
+	// count and hasMore are number of rows of the page and whether there
+	// are more rows in paging direction
+	count   int
+	hasMore bool
+	// advised records tables and keys found indexed by index advisor, it is
+	// shared by copies of paginator
+	advised *sync.Map
+	// last keeps the last page, it is shared by pointer so that pages of
+	// the paginator do not write paginator itself
+	last *lastPage
+}
+
+// lastPage is copy of paginator which paginated the last page
+type lastPage struct {
+	mu  sync.Mutex
+	run *Paginator
 }
 
 // SetAfterCursor sets paging after cursor
@@ -50,10 +193,16 @@ func (p *Paginator) SetBeforeCursor(beforeCursor string) {
 
 // SetKeys sets paging keys
 func (p *Paginator) SetKeys(keys ...string) {
-	p.keys = append(p.keys, keys...)
+	p.rules = append(p.rules, toRules(keys)...)
+}
+
+// SetRules sets paging keys with per key rules
+func (p *Paginator) SetRules(rules ...Rule) {
+	p.rules = append(p.rules, rules...)
 }
 
-// SetLimit sets paging limit
+// SetLimit sets paging limit, default is 10. Rows are not limited if limit is
+// Unlimited.
 func (p *Paginator) SetLimit(limit int) {
 	p.limit = limit
 }
@@ -63,30 +212,288 @@ func (p *Paginator) SetOrder(order Order) {
 	p.order = order
 }
 
+// SetTieBreaker sets whether to append primary key of the model as the last
+// paging key, so that ordering is always total even if keys are not unique
+func (p *Paginator) SetTieBreaker(enabled bool) {
+	p.tieBreaker = enabled
+}
+
+// SetDistinctOn sets DISTINCT ON keys (Postgres), which become the leading
+// paging keys and the only keys encoded into cursor. Other configured keys
+// are only used in ORDER BY to pick the row for each distinct keys.
+func (p *Paginator) SetDistinctOn(keys ...string) {
+	p.distinctOn = append(p.distinctOn, keys...)
+}
+
+// SetCopyResult sets whether to leave the out of query as it is scanned by
+// query, the page is then a fresh copy returned by GetPage
+func (p *Paginator) SetCopyResult(enabled bool) {
+	p.copyResult = enabled
+}
+
+// SetTable sets table or alias which keys are qualified by instead of
+// Table() of query, for queries selecting from views, CTEs or aliased
+// subqueries whose FROM alias differs from the table of model
+func (p *Paginator) SetTable(table string) {
+	p.table = table
+}
+
+// SetSkipFlip sets whether to leave page of before cursor in the order it is
+// queried, which is reversed to paging order. Cursors are encoded as usual.
+func (p *Paginator) SetSkipFlip(enabled bool) {
+	p.skipFlip = enabled
+}
+
+// SetURLSafeCursor sets whether to encode cursors by unpadded URL-safe base64
+// encoding, which is safe to be put into query strings without escaping.
+// Cursors of both encodings are accepted regardless.
+func (p *Paginator) SetURLSafeCursor(enabled bool) {
+	p.urlSafe = enabled
+}
+
+// GetPage returns pointer to slice of paginated page, which is the out of
+// query unless SetCopyResult is enabled
+func (p *Paginator) GetPage() interface{} {
+	return p.resolved().page
+}
+
 // GetNextCursor returns cursor for next pagination
 func (p *Paginator) GetNextCursor() Cursor {
-	return p.next
+	return p.resolved().next
+}
+
+// Validate validates paging keys against model, a *InvalidKeyError is
+// returned if any key is not a field of model
+func (p *Paginator) Validate(model interface{}) error {
+	rt, err := toModelType(model)
+	if err != nil {
+		return err
+	}
+	return validateRules(rt, append(p.getRules(rt), p.getOrderRules()...))
+}
+
+// Paginate paginates data, the returned query is the executed one. Query is
+// paginated by a copy of configuration of paginator, which is left as it is
+// so that paginator can be reused, and only result of the page is kept.
+// Pages of a paginator may be paginated concurrently, getters then return
+// result of the last finished one.
+func (p *Paginator) Paginate(query Query) (Query, error) {
+	_, result, err := p.paginateRun(query)
+	return result, err
 }
 
-// Paginate paginates data
-func (p *Paginator) Paginate(query Query) Query {
+// paginateRun paginates query by a copy of paginator, which is returned
+// holding result of the page
+func (p *Paginator) paginateRun(query Query) (*Paginator, Query, error) {
+	start := time.Now()
+	run := p.newRun()
+	span := run.startSpan()
+	result, err := run.paginate(query)
+	if err == nil {
+		run.initResumeCursor()
+		run.initTailCursor()
+		run.initApplied()
+	}
+	run.endSpan(span, err)
+	run.observe(start, err)
+	run.log(start, err)
+	p.setResult(run)
+	return run, result, err
+}
+
+// newRun returns copy of configuration of paginator to paginate a query,
+// state built up while paginating and result of the last page are reset
+func (p *Paginator) newRun() *Paginator {
+	run := *p
+	run.rules = append([]Rule(nil), p.rules...)
+	run.keys, run.tableKeys = nil, nil
+	run.orderRules, run.orderTableKeys = nil, nil
+	run.wrapped, run.invalidCursor = false, false
+	run.where, run.args, run.orderBy = "", nil, ""
+	run.warnings, run.plan, run.cacheKey = nil, "", ""
+	run.page, run.next, run.pageInfo = nil, Cursor{}, PageInfo{}
+	run.count, run.hasMore = 0, false
+	run.last = nil
+	return &run
+}
+
+// setResult keeps result of the page paginated by run, paginator not built
+// by New keeps it from its first page
+func (p *Paginator) setResult(run *Paginator) {
+	if p.last == nil {
+		p.last = new(lastPage)
+	}
+	p.last.mu.Lock()
+	p.last.run = run
+	p.last.mu.Unlock()
+}
+
+// resolved returns paginator resolved by the last paginate, whose paging
+// keys encode cursors of its page and which holds result of the page, it is
+// paginator itself if not paginated
+func (p *Paginator) resolved() *Paginator {
+	if p.last == nil {
+		return p
+	}
+	p.last.mu.Lock()
+	defer p.last.mu.Unlock()
+	if p.last.run != nil {
+		return p.last.run
+	}
+	return p
+}
+
+func (p *Paginator) paginate(query Query) (Query, error) {
+	rt, err := toModelType(query.Model())
+	if err != nil {
+		return query, err
+	}
+	if err := p.validateCursors(); err != nil {
+		return query, err
+	}
+	p.initModelOptions(rt)
 	p.initOptions()
+	p.initRules(rt)
+	if err := validateRules(rt, append(p.rules, p.orderRules...)); err != nil {
+		return query, err
+	}
+	if err := p.validateWindowFields(rt); err != nil {
+		return query, err
+	}
+	if err := p.validateETagVersionKey(rt); err != nil {
+		return query, err
+	}
+	if err := p.validatePartitionKey(rt); err != nil {
+		return query, err
+	}
 	p.initTableKeys(query)
-	p.appendPagingQuery(query).Select()
+	if p.loadCachedPage(query) {
+		return query, p.afterPaginate(p.page)
+	}
+	if err := p.adviseIndex(query); err != nil {
+		return query, err
+	}
+	query, cancel, err := p.withQueryTimeout(query)
+	defer cancel()
+	if err != nil {
+		return query, err
+	}
+	if query, err = p.appendHint(query); err != nil {
+		return query, err
+	}
+	query = p.appendPartition(query)
+	if query, err = p.appendDistinct(query); err != nil {
+		return query, err
+	}
+	query = p.appendAliasSelects(query)
+	query = p.appendSelects(query)
+	if query, err = p.appendSubqueryKeys(query); err != nil {
+		return query, err
+	}
+	if query, err = p.appendUnion(query); err != nil {
+		return query, err
+	}
+	if query, err = p.appendDistinctOn(query); err != nil {
+		return query, err
+	}
+	if query, err = p.appendWindow(query); err != nil {
+		return query, err
+	}
+	if query, err = p.appendCTE(query); err != nil {
+		return query, err
+	}
+	if query, err = p.appendAliasWrap(query); err != nil {
+		return query, err
+	}
+	base := query
+	wait, err := p.countTotal(base)
+	if err != nil {
+		return query, err
+	}
+	if query, err = p.appendPagingQuery(query); err != nil {
+		return query, err
+	}
+	if query, err = p.beforePaginate(query); err != nil {
+		return query, err
+	}
+	if query, err = p.route(query, QueryPage); err != nil {
+		return query, err
+	}
+	if err := p.explainQuery(query); err != nil {
+		return query, err
+	}
+	p.preallocatePage(query)
+	result, err := p.selectPage(base, query, rt)
+	if waitErr := wait(); err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		return result, err
+	}
 	// out must be a pointer or gorm will panic above
 	elems := reflect.ValueOf(query.Value()).Elem()
-	if elems.Kind() == reflect.Slice && elems.Len() > 0 {
-		p.postProcess(query.Value())
+	p.page = query.Value()
+	if elems.Kind() == reflect.Slice {
+		if p.copyResult {
+			p.page = copySlice(elems)
+		}
+		if elems.Len() > 0 {
+			hasMore, err := p.lookAheadMore(base, p.page)
+			if err != nil {
+				return result, err
+			}
+			if err := p.postProcess(p.page, hasMore); err != nil {
+				return result, err
+			}
+			p.initWindowPageInfo(reflect.ValueOf(p.page).Elem())
+		}
+	}
+	p.storeCachedPage()
+	if err := p.afterPaginate(p.page); err != nil {
+		return result, err
 	}
-	return query
+	return result, nil
+}
+
+// Errors for paginator
+var (
+	ErrInvalidModel           = errors.New("model should be struct")
+	ErrInvalidKey             = errors.New("invalid key")
+	ErrDistinctOnNotSupported = errors.New("query should implement DistinctOnQuery to paginate with DISTINCT ON")
+	ErrHavingNotSupported     = errors.New("query should implement HavingQuery to paginate by aggregate keys")
+)
+
+// InvalidKeyError is returned when paging key is not a field of model
+type InvalidKeyError struct {
+	Key   string
+	Model string
+}
+
+func (e *InvalidKeyError) Error() string {
+	return fmt.Sprintf("%s: %s is not a field of %s", ErrInvalidKey, e.Key, e.Model)
+}
+
+// Unwrap returns ErrInvalidKey
+func (e *InvalidKeyError) Unwrap() error {
+	return ErrInvalidKey
 }
 
 /* private */
 
-func (p *Paginator) initOptions() {
-	if len(p.keys) == 0 {
-		p.keys = append(p.keys, "ID")
+// initModelOptions applies paging keys and order declared by struct tags of
+// model type rt if they are not configured
+func (p *Paginator) initModelOptions(rt reflect.Type) {
+	if len(p.rules) > 0 || len(p.distinctOn) > 0 {
+		return
 	}
+	rules, order := taggedRules(rt)
+	p.rules = rules
+	if p.order == "" {
+		p.order = order
+	}
+}
+
+func (p *Paginator) initOptions() {
 	if p.limit == 0 {
 		p.limit = defaultLimit
 	}
@@ -95,29 +502,336 @@ func (p *Paginator) initOptions() {
 	}
 }
 
+func (p *Paginator) initRules(rt reflect.Type) {
+	p.orderRules = p.getOrderRules()
+	p.rules = p.getRules(rt)
+	for _, rule := range p.rules {
+		p.keys = append(p.keys, rule.Key)
+	}
+}
+
+// getRules returns configured rules with defaults applied for model type rt
+func (p *Paginator) getRules(rt reflect.Type) []Rule {
+	if len(p.distinctOn) > 0 {
+		return p.getDistinctOnRules()
+	}
+	rules := p.withoutPartitionKey(append([]Rule(nil), p.rules...))
+	if len(rules) == 0 {
+		// primary keys in schema order, e.g. of composite primary key
+		rules = p.withoutPartitionKey(toRules(primaryKeys(rt)))
+	}
+	if len(rules) == 0 {
+		rules = append(rules, Rule{Key: "ID"})
+	}
+	if p.tieBreaker {
+		for _, key := range primaryKeys(rt) {
+			if !hasKey(rules, key) && key != p.partitionKey {
+				rules = append(rules, Rule{Key: key})
+			}
+		}
+	}
+	return rules
+}
+
+// getDistinctOnRules returns rules of DISTINCT ON keys, configured rule of
+// the key is used if any
+func (p *Paginator) getDistinctOnRules() []Rule {
+	rules := make([]Rule, len(p.distinctOn))
+	for i, key := range p.distinctOn {
+		rules[i] = Rule{Key: key}
+		for _, rule := range p.rules {
+			if rule.Key == key {
+				rules[i] = rule
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// getOrderRules returns configured rules which are not DISTINCT ON keys
+func (p *Paginator) getOrderRules() (rules []Rule) {
+	if len(p.distinctOn) == 0 {
+		return nil
+	}
+	for _, rule := range p.rules {
+		if !containsKey(p.distinctOn, rule.Key) {
+			rules = append(rules, rule)
+		}
+	}
+	return
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(rules []Rule, key string) bool {
+	for _, rule := range rules {
+		if rule.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRules(rt reflect.Type, rules []Rule) error {
+	// entries of maps are not known until query is executed
+	if isMapType(rt) {
+		return nil
+	}
+	for _, rule := range rules {
+		for _, key := range append([]string{rule.Key}, rule.Coalesce...) {
+			if _, ok := rt.FieldByName(key); !ok {
+				return &InvalidKeyError{Key: key, Model: rt.Name()}
+			}
+		}
+	}
+	return nil
+}
+
 func (p *Paginator) initTableKeys(query Query) {
-	for _, key := range p.keys {
-		p.tableKeys = append(p.tableKeys, fmt.Sprintf("%s.%s", query.Table(), strcase.ToSnake(key)))
+	table := p.getTable(query)
+	p.tableKeys, p.orderTableKeys = nil, nil
+	for _, rule := range p.rules {
+		p.tableKeys = append(p.tableKeys, p.getSQLKey(rule, table))
+	}
+	for _, rule := range p.orderRules {
+		p.orderTableKeys = append(p.orderTableKeys, p.getSQLKey(rule, table))
+	}
+}
+
+// getTable returns table which keys are qualified by, which is the alias set
+// by SetAlias or the table set by SetTable unless query is wrapped as subquery, or the table of query in
+// schema set by SetSchema
+func (p *Paginator) getTable(query Query) string {
+	if p.alias != "" && !p.wrapped {
+		return p.alias
+	}
+	if p.table != "" && !p.wrapped {
+		return p.table
+	}
+	return p.qualifyTable(query.Table())
+}
+
+func (p *Paginator) getSQLKey(rule Rule, table string) string {
+	// expressions are selected as key columns of wrapped subquery
+	if p.wrapped {
+		rule.SQLRepr, rule.Subquery = "", ""
+	}
+	return rule.sqlKey(table, p.dialect)
+}
+
+// wrap wraps query as subquery aliased by alias, keys then refer to columns
+// of the subquery
+func (p *Paginator) wrap(query Query, alias string, selects ...string) (Query, error) {
+	wq, ok := query.(WrapQuery)
+	if !ok {
+		return query, ErrWrapNotSupported
+	}
+	query = wq.Wrap(alias, selects...)
+	p.wrapped = true
+	p.initTableKeys(query)
+	return query, nil
+}
+
+func (p *Paginator) appendDistinctOn(query Query) (Query, error) {
+	if len(p.distinctOn) == 0 {
+		return query, nil
+	}
+	dq, ok := query.(DistinctOnQuery)
+	if !ok {
+		return query, ErrDistinctOnNotSupported
+	}
+	// DISTINCT ON expressions must match the leftmost ORDER BY expressions
+	return dq.DistinctOn(p.tableKeys...), nil
+}
+
+func (p *Paginator) appendSelects(query Query) Query {
+	sq, ok := query.(SelectQuery)
+	// key columns must not change the distinct set or columns of union
+	if !ok || p.distinct || len(p.union) > 0 {
+		return query
+	}
+	selects := sq.Selects()
+	if len(selects) == 0 {
+		return query
+	}
+	table := p.getTable(query)
+	var missing []string
+	for _, rule := range p.rules {
+		// expressions and aliases are expected to be selected by caller
+		if rule.SQLRepr != "" || rule.Alias || rule.Subquery != "" {
+			continue
+		}
+		columns := []string{rule.column()}
+		for _, key := range rule.Coalesce {
+			columns = append(columns, strcase.ToSnake(key))
+		}
+		for _, column := range columns {
+			if !isSelected(selects, table, column) {
+				missing = append(missing, fmt.Sprintf("%s.%s", table, column))
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return query
+	}
+	return sq.AddSelects(missing...)
+}
+
+func (p *Paginator) appendPagingQuery(query Query) (Query, error) {
+	fields, err := p.decodeCursor(query)
+	if err != nil {
+		p.invalidCursor = true
+		return query, err
+	}
+	p.where, p.args = "", nil
+	if len(fields) > 0 {
+		p.where, p.args = p.buildCursorQuery(fields)
+		if query, err = p.appendCursorQuery(query); err != nil {
+			return query, err
+		}
+	}
+	p.orderBy = p.getOrder()
+	if !p.unlimited() {
+		query = query.Limit(p.fetchLimit())
+	}
+	if query, err = p.appendOffset(query); err != nil {
+		return query, err
+	}
+	query = query.Order(p.orderBy)
+	return query, nil
+}
+
+// newCursorEncoder creates encoder with cursor options of paginator, it is
+// the custom encoder if set
+func (p *Paginator) newCursorEncoder() CursorEncoder {
+	if p.encoder != nil {
+		return p.encoder
+	}
+	return p.newKeyEncoder()
+}
+
+// newKeyEncoder creates encoder of paging keys with cursor options of
+// paginator, which ignores custom encoder
+func (p *Paginator) newKeyEncoder() *cursorEncoder {
+	encoder := newCursorEncoder(p.rules...)
+	if p.fingerprint {
+		encoder.fingerprint = p.getFingerprint()
+	}
+	if p.cursorTTL > 0 {
+		encoder.issuedAt = p.getNow
+	}
+	if p.urlSafe {
+		encoder.encoding = base64.RawURLEncoding
+	}
+	encoder.compress = p.compression
+	encoder.compact = p.compact
+	return encoder
+}
+
+// newCursorDecoder creates decoder with cursor options of paginator
+func (p *Paginator) newCursorDecoder(query Query) (*cursorDecoder, error) {
+	decoder, err := newCursorDecoder(query.Model(), p.rules...)
+	if err != nil {
+		return nil, err
 	}
+	if p.fingerprint {
+		decoder.fingerprint = p.getFingerprint()
+	}
+	if p.cursorTTL > 0 {
+		decoder.ttl, decoder.now = p.cursorTTL, p.getNow
+	}
+	decoder.legacy, decoder.verbose = p.legacy, p.verbose
+	return decoder, nil
 }
 
-func (p *Paginator) appendPagingQuery(query Query) Query {
-	decoder, _ := NewCursorDecoder(query.Model(), p.keys...)
-	var fields []interface{}
+// decodeCursor decodes after or before cursor, nil is returned if there is
+// no cursor
+func (p *Paginator) decodeCursor(query Query) ([]interface{}, error) {
+	var cursor string
 	if p.hasAfterCursor() {
-		fields = decoder.Decode(*p.cursor.After)
+		cursor = *p.cursor.After
 	} else if p.hasBeforeCursor() {
-		fields = decoder.Decode(*p.cursor.Before)
+		cursor = *p.cursor.Before
 	}
-	if len(fields) > 0 {
-		query = query.Where(
-			p.getCursorQuery(),
-			p.getCursorQueryArgs(fields)...,
-		)
+	if cursor == "" {
+		return nil, nil
+	}
+	return p.decodeToken(query, cursor)
+}
+
+// decodeToken decodes cursor token by custom decoder if set, or by decoder
+// with cursor options of paginator
+func (p *Paginator) decodeToken(query Query, cursor string) ([]interface{}, error) {
+	if p.decoder != nil {
+		fields := p.decoder.Decode(cursor)
+		if fields == nil {
+			return nil, invalidCursorError("cursor cannot be decoded")
+		}
+		if len(fields) != len(p.rules) {
+			return nil, invalidCursorError("cursor has %d values for %d keys", len(fields), len(p.rules))
+		}
+		return fields, nil
+	}
+	decoder, err := p.newCursorDecoder(query)
+	if err != nil {
+		return nil, err
 	}
-	query = query.Limit(p.limit + 1)
-	query = query.Order(p.getOrder())
-	return query
+	return decoder.decode(cursor)
+}
+
+// appendCursorQuery appends cursor predicate to WHERE clause, or to HAVING
+// clause if any key is aggregate
+func (p *Paginator) appendCursorQuery(query Query) (Query, error) {
+	if !p.hasAggregateKey() {
+		return query.Where(p.where, p.args...), nil
+	}
+	hq, ok := query.(HavingQuery)
+	if !ok {
+		return query, ErrHavingNotSupported
+	}
+	return hq.Having(p.where, p.args...), nil
+}
+
+// appendAliasWrap wraps query as subquery if any key refers to alias
+func (p *Paginator) appendAliasWrap(query Query) (Query, error) {
+	if p.wrapped || !p.hasAliasKey() {
+		return query, nil
+	}
+	return p.wrap(query, subqueryAlias)
+}
+
+func (p *Paginator) hasAliasKey() bool {
+	for _, rule := range append(p.rules, p.orderRules...) {
+		if rule.Alias {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Paginator) hasAggregateKey() bool {
+	// aggregates are plain columns of wrapped subquery
+	if p.wrapped {
+		return false
+	}
+	for _, rule := range p.rules {
+		if rule.Aggregate {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Paginator) unlimited() bool {
+	return p.limit == Unlimited
 }
 
 func (p *Paginator) hasAfterCursor() bool {
@@ -130,28 +844,51 @@ func (p *Paginator) hasBeforeCursor() bool {
 
 func (p *Paginator) getCursorQuery() string {
 	qs := make([]string, len(p.tableKeys))
-	op := p.getOperator()
 	composite := ""
 	for i, sqlKey := range p.tableKeys {
-		qs[i] = fmt.Sprintf("%s%s %s ?", composite, sqlKey, op)
-		composite = fmt.Sprintf("%s%s = ? AND ", composite, sqlKey)
+		rule := p.rules[i]
+		qs[i] = fmt.Sprintf("%s%s %s %s", composite, sqlKey, rule.operator(p.getOperator(rule)), rule.placeholder())
+		composite = fmt.Sprintf("%s%s %s %s AND ", composite, sqlKey, rule.operator("="), rule.placeholder())
 	}
 	return strings.Join(qs, " OR ")
 }
 
 func (p *Paginator) getCursorQueryArgs(fields []interface{}) (args []interface{}) {
-	for i := 1; i <= len(fields); i++ {
-		args = append(args, fields[:i]...)
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i] = toQueryArg(field)
+	}
+	for i := 1; i <= len(values); i++ {
+		args = append(args, values[:i]...)
 	}
 	return
 }
 
-func (p *Paginator) getOperator() string {
-	if (p.hasAfterCursor() && p.order == ASC) ||
-		(p.hasBeforeCursor() && p.order == DESC) {
-		return ">"
+// getOperator returns comparison operator of key of rule in cursor predicate
+func (p *Paginator) getOperator(rule Rule) string {
+	order := p.getKeyOrder(rule)
+	if p.hasBeforeCursor() {
+		order = flip(order)
+	}
+	return rule.direction().Operator(order)
+}
+
+// getKeyOrder returns paging order of key of rule
+func (p *Paginator) getKeyOrder(rule Rule) Order {
+	if rule.Order != "" {
+		return rule.Order
 	}
-	return "<"
+	return p.order
+}
+
+// hasMixedOrder reports whether keys are ordered in different directions
+func (p *Paginator) hasMixedOrder() bool {
+	for _, rule := range p.rules {
+		if p.getKeyOrder(rule) != p.getKeyOrder(p.rules[0]) {
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Paginator) getOrder() string {
@@ -159,32 +896,108 @@ func (p *Paginator) getOrder() string {
 	if p.hasBeforeCursor() {
 		order = flip(p.order)
 	}
-	orders := make([]string, len(p.tableKeys))
+	return p.getOrderBy(order)
+}
+
+// getOrderBy returns ORDER BY expressions of keys in given order, keys with
+// their own order are flipped along with it
+func (p *Paginator) getOrderBy(order Order) string {
+	flipped := order != p.order
+	var orders []string
 	for index, sqlKey := range p.tableKeys {
-		orders[index] = fmt.Sprintf("%s %s", sqlKey, order)
+		keyOrder := p.getKeyOrder(p.rules[index])
+		if flipped {
+			keyOrder = flip(keyOrder)
+		}
+		orderBy := p.rules[index].direction().OrderBy(sqlKey, keyOrder)
+		if p.rules[index].Nulls != "" {
+			orderBy = p.dialect.nullsOrderBy(sqlKey, orderBy, p.isNullsLast(p.rules[index], flipped))
+		}
+		orders = append(orders, orderBy)
+	}
+	for _, sqlKey := range p.orderTableKeys {
+		orders = append(orders, fmt.Sprintf("%s %s", sqlKey, p.order))
 	}
 	return strings.Join(orders, ", ")
 }
 
-func (p *Paginator) postProcess(out interface{}) {
+// selectPage executes paged query, base is the query without cursor predicate,
+// ordering and limit
+func (p *Paginator) selectPage(base, paged Query, rt reflect.Type) (Query, error) {
+	if p.deferredJoin {
+		return p.selectDeferred(base, paged, rt)
+	}
+	return paged.Select(), nil
+}
+
+func (p *Paginator) postProcess(out interface{}, hasMore bool) error {
+	if c, ok := out.(Collection); ok && len(p.transform) == 0 && p.encoder == nil {
+		return p.postProcessCollection(c, hasMore)
+	}
 	elems := reflect.ValueOf(out).Elem()
-	hasMore := elems.Len() > p.limit
-	if hasMore {
+	if !p.unlimited() && elems.Len() > p.limit {
 		elems.Set(elems.Slice(0, elems.Len()-1))
 	}
-	if p.hasBeforeCursor() {
+	p.count, p.hasMore = elems.Len(), hasMore
+	flipped := p.hasBeforeCursor() && p.skipFlip
+	if p.hasBeforeCursor() && !p.skipFlip {
 		elems.Set(reverse(elems))
 	}
-	encoder := NewCursorEncoder(p.keys...)
-	if p.hasBeforeCursor() || hasMore {
-		cursor := encoder.Encode(elems.Index(elems.Len() - 1))
+	first, last := boundaries(elems, flipped)
+	if len(p.transform) > 0 {
+		// boundary rows are kept in case transform removes all rows
+		first, last = copyValue(first), copyValue(last)
+		if err := p.transformPage(out); err != nil {
+			return err
+		}
+		if elems.Len() > 0 {
+			first, last = boundaries(elems, flipped)
+		}
+	}
+	if err := p.checkZeroKeys(first, last); err != nil {
+		return err
+	}
+	encoder := p.newCursorEncoder()
+	if p.hasBeforeCursor() || hasMore || p.tail {
+		cursor := encoder.Encode(last)
 		p.next.After = &cursor
 	}
-	if p.hasAfterCursor() || (hasMore && p.hasBeforeCursor()) {
-		cursor := encoder.Encode(elems.Index(0))
+	if p.hasPrevious(hasMore) {
+		cursor := encoder.Encode(first)
 		p.next.Before = &cursor
 	}
-	return
+	return nil
+}
+
+// boundaries returns first and last row of page in paging order, elems are
+// in reversed order if flipped
+func boundaries(elems reflect.Value, flipped bool) (first, last reflect.Value) {
+	first, last = elems.Index(0), elems.Index(elems.Len()-1)
+	if flipped {
+		return last, first
+	}
+	return first, last
+}
+
+// copyValue returns a copy of v, which is not affected by changes to v or
+// the struct it points to
+func copyValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(v.Elem())
+		return c
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	return c
+}
+
+// copySlice returns pointer to a copy of slice v
+func copySlice(v reflect.Value) interface{} {
+	pv := reflect.New(v.Type())
+	pv.Elem().Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+	reflect.Copy(pv.Elem(), v)
+	return pv.Interface()
 }
 
 func reverse(v reflect.Value) reflect.Value {
@@ -1,7 +1,23 @@
 package paginator
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,13 +30,32 @@ func TestPaginator(t *testing.T) {
 	suite.Run(t, &paginatorSuite{})
 }
 
+func BenchmarkPaginatePreallocate(b *testing.B) {
+	for _, preallocate := range []bool{false, true} {
+		b.Run(fmt.Sprintf("preallocate=%v", preallocate), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := New()
+				p.SetLimit(1000)
+				p.SetPreallocate(preallocate)
+				q := newRecordQuery(&[]order{}, "orders")
+				q.rows = 1001
+				if _, err := p.Paginate(q); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 /* test model */
 
 type order struct {
-	ID        int       `gorm:"primary_key"`
-	Name      *string   `gorm:"type:varchar(30)"`
-	Items     []item    `gorm:"foreignkey:OrderID"`
-	CreatedAt time.Time `gorm:"type:timestamp;not null"`
+	ID          int        `gorm:"primary_key"`
+	Name        *string    `gorm:"type:varchar(30)"`
+	Items       []item     `gorm:"foreignkey:OrderID"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;not null"`
+	PublishedAt *time.Time `gorm:"type:timestamp NULL"`
 }
 
 type item struct {
@@ -29,6 +64,57 @@ type item struct {
 	Order   Order `gorm:"foreignkey:OrderID"`
 }
 
+// uuidV7 is a binary UUID stored as BINARY(16)
+type uuidV7 [16]byte
+
+func (u uuidV7) Value() (driver.Value, error) {
+	return u[:], nil
+}
+
+func (u *uuidV7) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok || len(b) != len(u) {
+		return fmt.Errorf("cannot scan %T into uuidV7", src)
+	}
+	copy(u[:], b)
+	return nil
+}
+
+type event struct {
+	ID  uuidV7 `gorm:"type:binary(16);primary_key"`
+	Seq int
+}
+
+// orderFeed is a domain wrapper of orders which is not a slice
+type orderFeed struct {
+	ids []int
+}
+
+func (f *orderFeed) Append(row interface{}) { f.ids = append(f.ids, row.(order).ID) }
+
+// orderList is a page of orders post-processed without reflection
+type orderList []order
+
+func (l *orderList) Len() int { return len(*l) }
+
+func (l *orderList) Truncate(n int) { *l = (*l)[:n] }
+
+func (l *orderList) Reverse() {
+	for i, j := 0, len(*l)-1; i < j; i, j = i+1, j-1 {
+		(*l)[i], (*l)[j] = (*l)[j], (*l)[i]
+	}
+}
+
+func (l *orderList) KeyOf(i int, key string) interface{} {
+	switch key {
+	case "ID":
+		return (*l)[i].ID
+	case "CreatedAt":
+		return (*l)[i].CreatedAt
+	}
+	return nil
+}
+
 /* suite */
 
 type paginatorSuite struct {
@@ -298,6 +384,65 @@ func (s *paginatorSuite) TestPaginateJoinQuery() {
 	s.Nil(cursor.Before)
 }
 
+func (s *paginatorSuite) TestPaginatePreload() {
+	var orders = s.givenOrders(3)
+	s.givenItems(orders[0].ID, 2)
+	s.givenItems(orders[1].ID, 1)
+	s.givenItems(orders[2].ID, 3)
+
+	var o1 []order
+	cursor := s.paginate(s.db.Preload("Items"), &o1, pq{
+		Limit: pqLimit(2),
+	})
+	s.Equal([]int{3, 2}, []int{o1[0].ID, o1[1].ID})
+	s.Equal([]int{3, 1}, []int{len(o1[0].Items), len(o1[1].Items)})
+
+	var o2 []order
+	s.paginate(s.db.Preload("Items"), &o2, pq{
+		After: cursor.After,
+	})
+	s.Len(o2, 1)
+	s.Equal(1, o2[0].ID)
+	s.Len(o2[0].Items, 2)
+}
+
+// itemWithOrder is item joined with its order
+type itemWithOrder struct {
+	ID      int `gorm:"primary_key"`
+	OrderID int
+	Order   *order
+}
+
+func (itemWithOrder) TableName() string {
+	return "items"
+}
+
+func (s *paginatorSuite) TestPaginateJoinsAssociation() {
+	var orders = s.givenOrders(2)
+	var items = s.givenItems(orders[0].ID, 2)
+	items = append(items, s.givenItems(orders[1].ID, 2)...)
+
+	newStmt := func() *gorm.DB {
+		return s.db.Joins("Order").Where("Order.id = ?", orders[1].ID)
+	}
+	var i1 []itemWithOrder
+	cursor := s.paginate(newStmt(), &i1, pq{
+		Limit: pqLimit(1),
+	})
+	s.Len(i1, 1)
+	s.Equal(items[3].ID, i1[0].ID)
+	s.Equal(orders[1].ID, i1[0].Order.ID)
+
+	var i2 []itemWithOrder
+	cursor = s.paginate(newStmt(), &i2, pq{
+		After: cursor.After,
+	})
+	s.Len(i2, 1)
+	s.Equal(items[2].ID, i2[0].ID)
+	s.Equal(orders[1].ID, i2[0].Order.ID)
+	s.Nil(cursor.After)
+}
+
 func (s *paginatorSuite) TestPaginateSpecialCharacter() {
 	s.givenCustomOrders([]order{
 		{Name: pqString("a,b,c")},
@@ -332,125 +477,4104 @@ func (s *paginatorSuite) TestPaginateSpecialCharacter() {
 	s.assertOnlyAfter(cursor)
 }
 
-/* util */
+func (s *paginatorSuite) TestPaginateCaseInsensitiveRule() {
+	var orders = s.givenCustomOrders([]order{
+		{Name: pqString("c")},
+		{Name: pqString("B")},
+		{Name: pqString("D")},
+		{Name: pqString("a")},
+	})
+	var rules = []Rule{{Key: "Name", CaseInsensitive: true}}
 
-func (s *paginatorSuite) paginate(stmt *gorm.DB, out interface{}, q pq) Cursor {
-	p := q.Paginator()
-	if err := p.Paginate(stmt, out).Error; err != nil {
-		s.FailNow(err.Error())
-	}
-	return p.GetNextCursor()
-}
+	var o1 []order
+	cursor := s.paginate(s.db, &o1, pq{
+		Rules: rules,
+		Limit: pqLimit(2),
+		Order: pqOrder(ASC),
+	})
+	s.assertOrders(orders, 3, 1, o1)
+	s.assertOnlyAfter(cursor)
 
-// pq stands for paging query
-type pq struct {
-	Keys   []string
-	After  *string
-	Before *string
-	Limit  *int
-	Order  *Order
+	var o2 []order
+	cursor = s.paginate(s.db, &o2, pq{
+		Rules: rules,
+		After: cursor.After,
+		Order: pqOrder(ASC),
+	})
+	s.assertOrders(orders, 0, 2, o2)
+	s.assertOnlyBefore(cursor)
+
+	var o3 []order
+	cursor = s.paginate(s.db, &o3, pq{
+		Rules:  rules,
+		Before: cursor.Before,
+		Order:  pqOrder(ASC),
+	})
+	s.Equal(o1, o3)
+	s.assertOnlyAfter(cursor)
 }
 
-func (q pq) Paginator() *Paginator {
-	p := New()
-	if q.Keys != nil {
-		p.SetKeys(q.Keys...)
-	}
-	if q.After != nil {
-		p.SetAfterCursor(*q.After)
-	}
-	if q.Before != nil {
-		p.SetBeforeCursor(*q.Before)
-	}
-	if q.Limit != nil {
-		p.SetLimit(*q.Limit)
-	}
-	if q.Order != nil {
-		p.SetOrder(*q.Order)
-	}
-	return p
+func (s *paginatorSuite) TestPaginateCoalesceRule() {
+	var now = time.Now()
+	var orders = s.givenCustomOrders([]order{
+		{CreatedAt: now, PublishedAt: pqTime(now.Add(3 * time.Hour))},
+		{CreatedAt: now.Add(1 * time.Hour)},
+		{CreatedAt: now, PublishedAt: pqTime(now.Add(-1 * time.Hour))},
+		{CreatedAt: now.Add(2 * time.Hour)},
+	})
+	var rules = []Rule{{Key: "PublishedAt", Coalesce: []string{"CreatedAt"}}}
+
+	var o1 []order
+	cursor := s.paginate(s.db, &o1, pq{
+		Rules: rules,
+		Limit: pqLimit(2),
+	})
+	s.assertOrders(orders, 0, 3, o1)
+	s.assertOnlyAfter(cursor)
+
+	var o2 []order
+	cursor = s.paginate(s.db, &o2, pq{
+		Rules: rules,
+		After: cursor.After,
+	})
+	s.assertOrders(orders, 1, 2, o2)
+	s.assertOnlyBefore(cursor)
+
+	var o3 []order
+	cursor = s.paginate(s.db, &o3, pq{
+		Rules:  rules,
+		Before: cursor.Before,
+	})
+	s.Equal(o1, o3)
+	s.assertOnlyAfter(cursor)
 }
 
-func pqString(str string) *string {
-	return &str
+func (s *paginatorSuite) TestPaginateTieBreaker() {
+	var now = time.Now()
+	var orders = s.givenCustomOrders([]order{
+		{CreatedAt: now},
+		{CreatedAt: now},
+		{CreatedAt: now},
+		{CreatedAt: now.Add(-1 * time.Hour)},
+	})
+	var keys = []string{"CreatedAt"}
+
+	var o1 []order
+	cursor := s.paginate(s.db, &o1, pq{
+		Keys:       keys,
+		Limit:      pqLimit(2),
+		TieBreaker: true,
+	})
+	s.assertOrders(orders, 2, 1, o1)
+	s.assertOnlyAfter(cursor)
+
+	var o2 []order
+	cursor = s.paginate(s.db, &o2, pq{
+		Keys:       keys,
+		After:      cursor.After,
+		TieBreaker: true,
+	})
+	s.assertOrders(orders, 0, 3, o2)
+	s.assertOnlyBefore(cursor)
+
+	var o3 []order
+	cursor = s.paginate(s.db, &o3, pq{
+		Keys:       keys,
+		Before:     cursor.Before,
+		TieBreaker: true,
+	})
+	s.Equal(o1, o3)
+	s.assertOnlyAfter(cursor)
 }
 
-func pqLimit(limit int) *int {
-	return &limit
+func (s *paginatorSuite) TestPaginateSelectQueryShouldSelectKeyColumns() {
+	var orders = s.givenCustomOrders([]order{
+		{CreatedAt: time.Now()},
+		{CreatedAt: time.Now().Add(1 * time.Hour)},
+		{CreatedAt: time.Now().Add(-1 * time.Hour)},
+	})
+	var keys = []string{"CreatedAt"}
+
+	var o1 []order
+	cursor := s.paginate(s.db.Select("id"), &o1, pq{
+		Keys:  keys,
+		Limit: pqLimit(1),
+	})
+	s.assertOrders(orders, 1, 1, o1)
+	s.False(o1[0].CreatedAt.IsZero())
+	s.assertOnlyAfter(cursor)
+
+	var o2 []order
+	cursor = s.paginate(s.db.Select("id"), &o2, pq{
+		Keys:  keys,
+		After: cursor.After,
+	})
+	s.assertOrders(orders, 0, 2, o2)
+	s.assertOnlyBefore(cursor)
 }
 
-func pqOrder(order Order) *Order {
-	return &order
+func (s *paginatorSuite) TestIsSelected() {
+	s.True(isSelected([]string{"*"}, "orders", "id"))
+	s.True(isSelected([]string{"orders.*"}, "orders", "id"))
+	s.True(isSelected([]string{"name", "id"}, "orders", "id"))
+	s.True(isSelected([]string{"`orders`.`id`"}, "orders", "id"))
+	s.True(isSelected([]string{"MAX(created_at) AS id"}, "orders", "id"))
+	s.False(isSelected([]string{"items.id"}, "orders", "id"))
+	s.False(isSelected([]string{"name"}, "orders", "id"))
 }
 
-/* order */
+func (s *paginatorSuite) TestPaginateAggregateRule() {
+	var orders = s.givenOrders(3)
+	s.givenItems(orders[0].ID, 3)
+	s.givenItems(orders[1].ID, 1)
+	s.givenItems(orders[2].ID, 2)
 
-func (s *paginatorSuite) givenOrders(n int) []order {
-	orders := make([]order, n)
-	for i := 0; i < n; i++ {
-		orders[i] = order{}
+	type orderItemCount struct {
+		OrderID   int
+		ItemCount int
 	}
-	return s.createOrders(orders)
-}
-
-func (s *paginatorSuite) givenPtrOrders(n int) []*order {
-	var result []*order
-	orders := s.givenOrders(n)
-	for i := 0; i < len(orders); i++ {
-		result = append(result, &orders[i])
+	var stmt = s.db.
+		Table("items").
+		Select("order_id, COUNT(*) AS item_count").
+		Group("order_id")
+	var rules = []Rule{
+		{Key: "ItemCount", SQLRepr: "COUNT(*)", Aggregate: true},
+		{Key: "OrderID"},
 	}
-	return result
+
+	var c1 []orderItemCount
+	cursor := s.paginate(stmt, &c1, pq{
+		Rules: rules,
+		Limit: pqLimit(1),
+	})
+	s.Equal([]orderItemCount{{orders[0].ID, 3}}, c1)
+	s.assertOnlyAfter(cursor)
+
+	var c2 []orderItemCount
+	cursor = s.paginate(stmt, &c2, pq{
+		Rules: rules,
+		After: cursor.After,
+	})
+	s.Equal([]orderItemCount{{orders[2].ID, 2}, {orders[1].ID, 1}}, c2)
+	s.assertOnlyBefore(cursor)
 }
 
-func (s *paginatorSuite) givenCustomOrders(orders []order) []order {
-	return s.createOrders(orders)
+func (s *paginatorSuite) TestPaginateAggregateRuleShouldReturnErrorWhenQueryIsNotSupported() {
+	after := NewCursorEncoder("ID").Encode(order{ID: 1})
+
+	p := New()
+	p.SetRules(Rule{Key: "ID", SQLRepr: "MAX(orders.id)", Aggregate: true})
+	p.SetAfterCursor(after)
+	_, err := p.Paginate(newRecordQuery(&[]order{}, "orders"))
+	s.Equal(ErrHavingNotSupported, err)
 }
 
-func (s *paginatorSuite) createOrders(orders []order) []order {
-	for i := 0; i < len(orders); i++ {
-		if err := s.db.Create(&orders[i]).Error; err != nil {
-			s.FailNow(err.Error())
-		}
-	}
-	return orders
+func (s *paginatorSuite) TestPaginateDeferredJoin() {
+	var orders = s.givenCustomOrders([]order{
+		{Name: pqString("a"), CreatedAt: time.Now()},
+		{Name: pqString("b"), CreatedAt: time.Now().Add(1 * time.Hour)},
+		{Name: pqString("c"), CreatedAt: time.Now().Add(-1 * time.Hour)},
+	})
+	var keys = []string{"CreatedAt"}
+
+	var o1 []order
+	cursor := s.paginate(s.db, &o1, pq{
+		Keys:         keys,
+		Limit:        pqLimit(2),
+		DeferredJoin: true,
+	})
+	s.assertOrders(orders, 1, 0, o1)
+	s.Equal("b", *o1[0].Name)
+	s.assertOnlyAfter(cursor)
+
+	var o2 []order
+	cursor = s.paginate(s.db, &o2, pq{
+		Keys:         keys,
+		After:        cursor.After,
+		DeferredJoin: true,
+	})
+	s.assertOrders(orders, 2, 2, o2)
+	s.Equal("c", *o2[0].Name)
+	s.assertOnlyBefore(cursor)
+
+	var o3 []order
+	cursor = s.paginate(s.db, &o3, pq{
+		Keys:         keys,
+		Before:       cursor.Before,
+		DeferredJoin: true,
+	})
+	s.Equal(o1, o3)
+	s.assertOnlyAfter(cursor)
 }
 
-/* item */
+func (s *paginatorSuite) TestPaginateDeferredJoinShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetDeferredJoin(true)
+	_, err := p.Paginate(newRecordQuery(&[]order{}, "orders"))
+	s.Equal(ErrDeferredJoinNotSupported, err)
+}
 
-func (s *paginatorSuite) givenItems(orderID int, n int) []item {
-	items := make([]item, n)
-	for i := 0; i < n; i++ {
-		items[i] = item{
-			OrderID: orderID,
-		}
+func (s *paginatorSuite) TestPaginateJSONPathRule() {
+	type event struct {
+		ID   int
+		Data json.RawMessage
 	}
-	return s.createItems(items)
+	after := newCursorEncoder(Rule{Key: "Data", JSONPath: []string{"score"}}).Encode(event{
+		Data: json.RawMessage(`{"score":42}`),
+	})
+
+	p := New()
+	p.SetRules(Rule{Key: "Data", JSONPath: []string{"score"}, SQLRepr: "(events.data->>'score')::numeric"})
+	p.SetAfterCursor(after)
+	q := newRecordQuery(&[]event{}, "events")
+	_, err := p.Paginate(q)
+	s.NoError(err)
+	s.Equal([]string{"(events.data->>'score')::numeric < ?"}, q.wheres)
+	s.Equal([][]interface{}{{int64(42)}}, q.args)
+	s.Equal([]string{"(events.data->>'score')::numeric DESC"}, q.orders)
+
+	p = New()
+	p.SetRules(Rule{Key: "Data", JSONPath: []string{"meta", "name"}})
+	q = newRecordQuery(&[]event{}, "events")
+	_, err = p.Paginate(q)
+	s.NoError(err)
+	s.Equal([]string{"events.data #>> '{meta,name}' DESC"}, q.orders)
 }
 
-func (s *paginatorSuite) createItems(items []item) []item {
-	for i := 0; i < len(items); i++ {
-		if err := s.db.Create(&items[i]).Error; err != nil {
-			s.FailNow(err.Error())
-		}
+func (s *paginatorSuite) TestPaginateWindow() {
+	s.givenOrders(5)
+
+	type orderWindow struct {
+		ID       int
+		Total    int64
+		Position int64
 	}
-	return items
-}
+	var stmt = s.db.Table("orders")
 
-/* assert */
+	var w1 []orderWindow
+	p := pq{Limit: pqLimit(2)}.Paginator()
+	p.SetWindow("Total", "Position")
+	cursor := s.paginateBy(p, stmt, &w1)
+	s.Equal([]orderWindow{{5, 5, 1}, {4, 5, 2}}, w1)
+	s.assertPageInfo(5, 1, 2, p.GetPageInfo())
+	s.assertOnlyAfter(cursor)
 
-func (s *paginatorSuite) assertOnlyAfter(cursor Cursor) {
-	s.NotNil(cursor.After)
-	s.Nil(cursor.Before)
+	var w2 []orderWindow
+	p = pq{After: cursor.After, Limit: pqLimit(2)}.Paginator()
+	p.SetWindow("Total", "Position")
+	cursor = s.paginateBy(p, stmt, &w2)
+	s.Equal([]orderWindow{{3, 5, 3}, {2, 5, 4}}, w2)
+	s.assertPageInfo(5, 3, 4, p.GetPageInfo())
+	s.assertBoth(cursor)
+
+	var w3 []orderWindow
+	p = pq{Before: cursor.Before}.Paginator()
+	p.SetWindow("Total", "")
+	cursor = s.paginateBy(p, stmt, &w3)
+	s.Equal([]orderWindow{{5, 5, 0}, {4, 5, 0}}, w3)
+	s.Equal(int64(5), *p.GetPageInfo().Total)
+	s.Nil(p.GetPageInfo().StartPosition)
+	s.assertOnlyAfter(cursor)
 }
 
-func (s *paginatorSuite) assertOnlyBefore(cursor Cursor) {
-	s.Nil(cursor.After)
-	s.NotNil(cursor.Before)
+func (s *paginatorSuite) TestPaginateWindowShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetWindow("ID", "")
+	_, err := p.Paginate(newRecordQuery(&[]order{}, "orders"))
+	s.Equal(ErrWrapNotSupported, err)
 }
 
-func (s *paginatorSuite) assertBoth(cursor Cursor) {
-	s.NotNil(cursor.After)
-	s.NotNil(cursor.Before)
+func (s *paginatorSuite) TestPaginateAliasRule() {
+	s.givenOrders(3)
+
+	type orderRank struct {
+		ID   int
+		Rank int
+	}
+	var stmt = s.db.
+		Table("orders").
+		Select([]string{"orders.id", "MOD(orders.id, 3) AS `rank`"})
+	var rules = []Rule{{Key: "Rank", Alias: true}}
+
+	var r1 []orderRank
+	cursor := s.paginate(stmt, &r1, pq{
+		Rules: rules,
+		Limit: pqLimit(1),
+		Order: pqOrder(ASC),
+	})
+	s.Equal([]orderRank{{3, 0}}, r1)
+	s.assertOnlyAfter(cursor)
+
+	var r2 []orderRank
+	cursor = s.paginate(stmt, &r2, pq{
+		Rules: rules,
+		After: cursor.After,
+		Order: pqOrder(ASC),
+	})
+	s.Equal([]orderRank{{1, 1}, {2, 2}}, r2)
+	s.assertOnlyBefore(cursor)
+}
+
+func (s *paginatorSuite) TestPaginateSubqueryRule() {
+	orders := s.givenOrders(4)
+	for _, i := range []int{2, 0, 3, 1} {
+		s.givenItems(orders[i].ID, 1)
+	}
+
+	type orderActivity struct {
+		ID         int
+		LastItemID int
+	}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{
+			Key:      "LastItemID",
+			Subquery: "SELECT MAX(items.id) FROM items WHERE items.order_id = orders.id",
+		}, Rule{Key: "ID"})
+		p.SetLimit(2)
+		return p
+	}
+	var a1 []orderActivity
+	cursor := s.paginateBy(newPaginator(), s.db.Table("orders"), &a1)
+	s.Equal([]orderActivity{{2, 4}, {4, 3}}, a1)
+
+	var a2 []orderActivity
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db.Table("orders"), &a2)
+	s.Equal([]orderActivity{{1, 2}, {3, 1}}, a2)
+
+	var a3 []orderActivity
+	p = newPaginator()
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, s.db.Table("orders"), &a3)
+	s.Equal(a1, a3)
+
+	_, err := newPaginator().Paginate(newRecordQuery(&[]orderActivity{}, "orders"))
+	s.Equal(ErrSubqueryKeyNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateDistinctOn() {
+	name := "a"
+	after := NewCursorEncoder("Name").Encode(order{Name: &name})
+
+	p := New()
+	p.SetKeys("CreatedAt")
+	p.SetDistinctOn("Name")
+	p.SetAfterCursor(after)
+	q := newRecordQuery(&[]order{}, "orders")
+	_, err := p.Paginate(q)
+	s.NoError(err)
+	s.Equal([]string{"orders.name"}, q.distinctOn)
+	s.Equal([]string{"orders.name < ?"}, q.wheres)
+	s.Equal([][]interface{}{{&name}}, q.args)
+	s.Equal([]string{"orders.name DESC, orders.created_at DESC"}, q.orders)
+
+	p = New()
+	p.SetKeys("CreatedAt")
+	p.SetDistinctOn("Name")
+	p.SetBeforeCursor(after)
+	q = newRecordQuery(&[]order{}, "orders")
+	_, err = p.Paginate(q)
+	s.NoError(err)
+	s.Equal([]string{"orders.name > ?"}, q.wheres)
+	s.Equal([]string{"orders.name ASC, orders.created_at DESC"}, q.orders)
+}
+
+func (s *paginatorSuite) TestPaginateDistinctOnShouldReturnErrorWhenQueryIsNotSupported() {
+	var o1 []order
+	p := New()
+	p.SetDistinctOn("Name")
+	_, err := p.Paginate(newGormQuery(s.db, &o1))
+	s.Equal(ErrDistinctOnNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateShouldReturnErrorWhenKeyIsNotField() {
+	s.givenOrders(1)
+
+	var o1 []order
+	_, err := pq{Keys: []string{"Unknown"}}.Paginator().Paginate(newGormQuery(s.db, &o1))
+	s.True(errors.Is(err, ErrInvalidKey))
+	s.Equal(&InvalidKeyError{Key: "Unknown", Model: "order"}, err)
+	s.Nil(o1)
+
+	var o2 []order
+	_, err = pq{Rules: []Rule{{Key: "PublishedAt", Coalesce: []string{"Unknown"}}}}.Paginator().Paginate(newGormQuery(s.db, &o2))
+	s.Equal(&InvalidKeyError{Key: "Unknown", Model: "order"}, err)
+}
+
+func (s *paginatorSuite) TestPaginateShouldReturnErrorWhenCursorIsInvalid() {
+	for _, cursor := range []string{"invalid cursor", "WzEsMl0=" /* [1,2] */, "WyJhIl0=" /* ["a"] */} {
+		p := New()
+		p.SetBeforeCursor(cursor)
+		var out []order
+		_, err := p.Paginate(newRecordQuery(&out, "orders"))
+		s.True(errors.Is(err, ErrInvalidCursor), cursor)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateCursorTTL() {
+	s.givenOrders(3)
+
+	now := time.Now().Truncate(time.Second)
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetLimit(1)
+		p.SetCursorTTL(time.Hour)
+		p.now = func() time.Time { return now }
+		return p
+	}
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+
+	var o2 []order
+	now = now.Add(time.Hour)
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal(2, o2[0].ID)
+
+	var o3 []order
+	now = now.Add(time.Second)
+	p = newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	_, err := p.Paginate(newGormQuery(s.db, &o3))
+	s.Equal(ErrCursorExpired, err)
+
+	// cursor without issued-at time
+	var o4 []order
+	p = newPaginator()
+	p.SetAfterCursor(*s.paginate(s.db, &o4, pq{Limit: pqLimit(1)}).After)
+	_, err = p.Paginate(newGormQuery(s.db, &o4))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateCursorFingerprint() {
+	s.givenOrders(3)
+
+	newPaginator := func(order Order, filterHash string) *Paginator {
+		p := New()
+		p.SetLimit(1)
+		p.SetOrder(order)
+		p.SetCursorFingerprint(true)
+		p.SetFilterHash(filterHash)
+		return p
+	}
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(DESC, "a"), s.db, &o1)
+
+	var o2 []order
+	p := newPaginator(DESC, "a")
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal(2, o2[0].ID)
+
+	for _, p := range []*Paginator{newPaginator(ASC, "a"), newPaginator(DESC, "b")} {
+		var out []order
+		p.SetAfterCursor(*cursor.After)
+		_, err := p.Paginate(newGormQuery(s.db, &out))
+		s.Equal(ErrCursorFingerprintMismatch, err)
+	}
+
+	// cursor without fingerprint
+	var o3 []order
+	p = newPaginator(DESC, "a")
+	p.SetAfterCursor(*s.paginate(s.db, &o3, pq{Limit: pqLimit(1)}).After)
+	_, err := p.Paginate(newGormQuery(s.db, &o3))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateCursorFilters() {
+	s.givenOrders(3)
+
+	newPaginator := func(filters map[string]interface{}) *Paginator {
+		p := New()
+		p.SetLimit(1)
+		p.SetFilters(filters)
+		return p
+	}
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(map[string]interface{}{"status": "open", "owner": 42}), s.db, &o1)
+
+	var o2 []order
+	p := newPaginator(map[string]interface{}{"owner": 42, "status": "open"})
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal(2, o2[0].ID)
+
+	for _, filters := range []map[string]interface{}{
+		{"status": "closed", "owner": 42},
+		{"status": "open", "owner": "42"},
+		{"status": "open"},
+		nil,
+	} {
+		var out []order
+		p := newPaginator(filters)
+		p.SetAfterCursor(*cursor.After)
+		_, err := p.Paginate(newGormQuery(s.db, &out))
+		s.Equal(ErrCursorFingerprintMismatch, err, filters)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateLegacyCursor() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("a")},
+		{Name: pqString("b")},
+		{Name: pqString("c")},
+		{Name: pqString("d")},
+	})
+
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetKeys("Name", "ID")
+		p.SetCursorFingerprint(true)
+		p.SetLegacyDecoders(
+			NewSeparatedLegacyDecoder(base64.RawURLEncoding, "_"),
+			NewSeparatedLegacyDecoder(base64.StdEncoding, "|"),
+		)
+		return p
+	}
+	var o1 []order
+	p := newPaginator()
+	p.SetAfterCursor(base64.StdEncoding.EncodeToString([]byte("c|3")))
+	s.paginateBy(p, s.db, &o1)
+	s.Equal([]int{2, 1}, []int{o1[0].ID, o1[1].ID})
+
+	// current cursors are still decoded before legacy decoders
+	var o2 []order
+	p = newPaginator()
+	p.SetLimit(1)
+	cursor := s.paginateBy(p, s.db, &o2)
+	var o3 []order
+	p = newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]int{3, 2, 1}, []int{o3[0].ID, o3[1].ID, o3[2].ID})
+
+	var o4 []order
+	p = newPaginator()
+	p.SetAfterCursor(base64.StdEncoding.EncodeToString([]byte("c;3")))
+	_, err := p.Paginate(newGormQuery(s.db, &o4))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateCursorCodec() {
+	s.givenOrders(3)
+
+	decoder, err := NewCursorDecoder(&order{}, "ID")
+	s.Nil(err)
+	codec := prefixCodec{encoder: NewCursorEncoder("ID"), decoder: decoder}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetLimit(2)
+		p.SetCursorCodec(codec, codec)
+		return p
+	}
+
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+	s.True(strings.HasPrefix(*cursor.After, "cur_"))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{1}, orderIDs(o2))
+	s.True(strings.HasPrefix(*cursor.Before, "cur_"))
+
+	for _, invalid := range []string{"invalid", "cur_" + NewCursorEncoder("ID", "ID").Encode(o1[0])} {
+		var out []order
+		p := newPaginator()
+		p.SetAfterCursor(invalid)
+		_, err := p.Paginate(newGormQuery(s.db, &out))
+		s.True(errors.Is(err, ErrInvalidCursor), invalid)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateRuleValueTransform() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("low")},
+		{Name: pqString("high")},
+		{Name: pqString("mid")},
+		{Name: pqString("low")},
+	})
+
+	levels := []string{"high", "low", "mid"}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{
+			Key: "Name",
+			EncodeValue: func(v interface{}) interface{} {
+				for i, level := range levels {
+					if *v.(*string) == level {
+						return i
+					}
+				}
+				return -1
+			},
+			DecodeValue: func(v interface{}) (interface{}, error) {
+				i, err := v.(json.Number).Int64()
+				if err != nil || i < 0 || int(i) >= len(levels) {
+					return nil, errors.New("unknown level")
+				}
+				return levels[i], nil
+			},
+		}, Rule{Key: "ID"})
+		p.SetLimit(2)
+		p.SetOrder(ASC)
+		return p
+	}
+
+	var o1 []order
+	p := newPaginator()
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Equal([]string{"high", "low"}, orderNames(o1))
+	s.Equal("Name=1, ID=1", p.DumpCursor(*cursor.After))
+
+	var o2 []order
+	p = newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]string{"low", "mid"}, orderNames(o2))
+	s.Equal([]int{4, 3}, orderIDs(o2))
+
+	var o3 []order
+	p = newPaginator()
+	p.SetAfterCursor(base64.StdEncoding.EncodeToString([]byte("[7,1]")))
+	_, err := p.Paginate(newGormQuery(s.db, &o3))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateZeroReject() {
+	s.givenCustomOrders([]order{{Name: pqString("")}, {Name: pqString("a")}})
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{Key: "Name", Zero: ZeroReject}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		p.SetLimit(1)
+		return p
+	}
+	var o1 []order
+	_, err := newPaginator().Paginate(newGormQuery(s.db, &o1))
+	s.True(errors.Is(err, ErrZeroKeyValue))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(NewCursorEncoder("Name", "ID").Encode(order{ID: 1, Name: pqString("")}))
+	_, err = p.Paginate(newGormQuery(s.db, &o2))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateZeroAsNull() {
+	type note struct {
+		ID   int `gorm:"primary_key"`
+		Body *string
+	}
+	// NULL values are scanned into zero values of plain fields
+	type plainNote struct {
+		ID   int
+		Body string
+	}
+	notes := func() *gorm.DB { return s.db.Table("notes") }
+	s.Nil(notes().AutoMigrate(&note{}))
+	defer notes().Migrator().DropTable(&note{})
+	rows := []note{{Body: pqString("b")}, {}, {Body: pqString("a")}, {}}
+	s.Nil(notes().Create(&rows).Error)
+	noteIDs := func(notes []plainNote) (ids []int) {
+		for _, n := range notes {
+			ids = append(ids, n.ID)
+		}
+		return
+	}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{Key: "Body", Zero: ZeroAsNull, Nulls: NullsLast}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		p.SetLimit(2)
+		return p
+	}
+	var n1 []plainNote
+	_, err := newPaginator().Paginate(newGormQuery(notes(), &n1))
+	s.Nil(err)
+	s.Equal([]int{3, 1}, noteIDs(n1))
+
+	var n2 []plainNote
+	p := newPaginator()
+	p.SetAfterCursor(NewCursorEncoder("Body", "ID").Encode(n1[1]))
+	_, err = p.Paginate(newGormQuery(notes(), &n2))
+	s.Nil(err)
+	s.Equal([]int{2, 4}, noteIDs(n2))
+
+	var n3 []plainNote
+	before := p.GetNextCursor().Before
+	s.NotNil(before)
+	p = newPaginator()
+	p.SetBeforeCursor(*before)
+	_, err = p.Paginate(newGormQuery(notes(), &n3))
+	s.Nil(err)
+	s.Equal([]int{3, 1}, noteIDs(n3))
+}
+
+func (s *paginatorSuite) TestPaginatePartitionKey() {
+	type entry struct {
+		ListID int `gorm:"primaryKey;autoIncrement:false"`
+		ID     int `gorm:"primaryKey"`
+	}
+	entries := func() *gorm.DB { return s.db.Table("entries") }
+	s.Nil(entries().AutoMigrate(&entry{}))
+	defer entries().Migrator().DropTable(&entry{})
+
+	rows := []entry{{1, 1}, {2, 2}, {1, 3}, {2, 4}, {1, 5}, {1, 6}}
+	s.Nil(entries().Create(&rows).Error)
+	entryIDs := func(entries []entry) (ids []int) {
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return
+	}
+	newPaginator := func(listID int) *Paginator {
+		p := New()
+		p.SetPartitionKey("ListID", listID)
+		p.SetLimit(2)
+		return p
+	}
+
+	// partition key of composite primary key is not ordered by
+	_, _, orderBy, _ := newPaginator(1).BuildSQL(&entry{})
+	s.Equal("entries.id DESC", orderBy)
+
+	var e1 []entry
+	cursor := s.paginateBy(newPaginator(1), entries(), &e1)
+	s.Equal([]int{6, 5}, entryIDs(e1))
+
+	var e2 []entry
+	p := newPaginator(1)
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, entries(), &e2)
+	s.Equal([]int{3, 1}, entryIDs(e2))
+
+	// cursors are bound to their partition
+	var e3 []entry
+	p = newPaginator(2)
+	p.SetAfterCursor(*cursor.After)
+	_, err := p.Paginate(newGormQuery(entries(), &e3))
+	s.True(errors.Is(err, ErrCursorFingerprintMismatch))
+
+	p = New()
+	p.SetPartitionKey("Unknown", 1)
+	_, err = p.Paginate(newGormQuery(entries(), &e3))
+	s.IsType(&InvalidKeyError{}, err)
+}
+
+func (s *paginatorSuite) TestPaginateBytesKey() {
+	type digest struct {
+		ID   int    `gorm:"primary_key"`
+		Hash []byte `gorm:"type:varbinary(32)"`
+	}
+	digests := func() *gorm.DB { return s.db.Table("digests") }
+	s.Nil(digests().AutoMigrate(&digest{}))
+	defer digests().Migrator().DropTable(&digest{})
+
+	rows := []digest{{Hash: []byte{0xff}}, {Hash: []byte{0x80, 0x00}}, {Hash: []byte{0x01}}, {Hash: []byte{0x7f, 0xff}}}
+	s.Nil(digests().Create(&rows).Error)
+	hashIDs := func(digests []digest) (ids []int) {
+		for _, d := range digests {
+			ids = append(ids, d.ID)
+		}
+		return
+	}
+
+	for _, compact := range []bool{false, true} {
+		newPaginator := func() *Paginator {
+			p := New()
+			if compact {
+				p.SetTimeOrderedKey("Hash")
+			} else {
+				p.SetKeys("Hash")
+			}
+			p.SetOrder(ASC)
+			p.SetLimit(2)
+			return p
+		}
+		// bytes are compared byte-wise
+		var d1 []digest
+		cursor := s.paginateBy(newPaginator(), digests(), &d1)
+		s.Equal([]int{3, 4}, hashIDs(d1))
+
+		var d2 []digest
+		p := newPaginator()
+		p.SetAfterCursor(*cursor.After)
+		cursor = s.paginateBy(p, digests(), &d2)
+		s.Equal([]int{2, 1}, hashIDs(d2))
+
+		var d3 []digest
+		p = newPaginator()
+		p.SetBeforeCursor(*cursor.Before)
+		s.paginateBy(p, digests(), &d3)
+		s.Equal([]int{3, 4}, hashIDs(d3))
+	}
+}
+
+func (s *paginatorSuite) TestPaginateBoolKey() {
+	type post struct {
+		ID     int `gorm:"primary_key"`
+		Pinned bool
+	}
+	posts := func() *gorm.DB { return s.db.Table("posts") }
+	s.Nil(posts().AutoMigrate(&post{}))
+	defer posts().Migrator().DropTable(&post{})
+
+	rows := []post{{Pinned: false}, {Pinned: true}, {Pinned: false}, {Pinned: true}, {Pinned: false}}
+	s.Nil(posts().Create(&rows).Error)
+	postIDs := func(posts []post) (ids []int) {
+		for _, p := range posts {
+			ids = append(ids, p.ID)
+		}
+		return
+	}
+
+	for _, predicate := range []Predicate{PredicateOR, PredicateTuple, PredicateRange} {
+		for _, c := range []struct {
+			order Order
+			pages [][]int
+		}{
+			{DESC, [][]int{{4, 2}, {5, 3}, {1}}},
+			{ASC, [][]int{{1, 3}, {5, 2}, {4}}},
+		} {
+			newPaginator := func() *Paginator {
+				p := New()
+				p.SetKeys("Pinned", "ID")
+				p.SetPredicate(predicate)
+				p.SetOrder(c.order)
+				p.SetLimit(2)
+				return p
+			}
+			var cursor Cursor
+			for i, page := range c.pages {
+				var out []post
+				p := newPaginator()
+				if i > 0 {
+					p.SetAfterCursor(*cursor.After)
+				}
+				cursor = s.paginateBy(p, posts(), &out)
+				s.Equal(page, postIDs(out), "%s %s page %d", predicate, c.order, i)
+			}
+			var out []post
+			p := newPaginator()
+			p.SetBeforeCursor(*cursor.Before)
+			s.paginateBy(p, posts(), &out)
+			s.Equal(c.pages[1], postIDs(out), "%s %s before", predicate, c.order)
+		}
+	}
+}
+
+func (s *paginatorSuite) TestPaginateTimeOrderedKey() {
+	events := func() *gorm.DB { return s.db.Table("events") }
+	s.Nil(events().AutoMigrate(&event{}))
+	defer events().Migrator().DropTable(&event{})
+
+	var rows []event
+	for i := 1; i <= 5; i++ {
+		// UUIDv7 leads by 48-bit milliseconds since epoch
+		var id uuidV7
+		binary.BigEndian.PutUint64(id[:8], uint64(1600000000000+i)<<16|0x7000)
+		id[15] = byte(i)
+		rows = append(rows, event{ID: id, Seq: i})
+	}
+	s.Nil(events().Create(&rows).Error)
+
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetTimeOrderedKey("ID")
+		p.SetLimit(2)
+		return p
+	}
+	var e1 []event
+	cursor := s.paginateBy(newPaginator(), events(), &e1)
+	s.Equal([]int{5, 4}, eventSeqs(e1))
+	// 16 bytes ID with marker encoded without JSON
+	s.Len(*cursor.After, 24)
+
+	var e2 []event
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, events(), &e2)
+	s.Equal([]int{3, 2}, eventSeqs(e2))
+
+	var e3 []event
+	p = newPaginator()
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, events(), &e3)
+	s.Equal([]int{5, 4}, eventSeqs(e3))
+
+	// compact cursors are rejected once fingerprint is enabled
+	var e4 []event
+	p = newPaginator()
+	p.SetCursorFingerprint(true)
+	p.SetAfterCursor(*cursor.After)
+	_, err := p.Paginate(newGormQuery(events(), &e4))
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginatePreallocate() {
+	for _, c := range []struct {
+		lookAhead LookAhead
+		capacity  int
+	}{
+		{LookAheadRow, 51},
+		{LookAheadNone, 50},
+	} {
+		var orders []order
+		q := newRecordQuery(&orders, "orders")
+		q.rows = 100
+		p := New()
+		p.SetLimit(50)
+		p.SetLookAhead(c.lookAhead)
+		p.SetPreallocate(true)
+		_, err := p.Paginate(q)
+		s.Nil(err)
+		s.Len(orders, 50)
+		s.Equal(c.capacity, cap(orders), c.lookAhead)
+	}
+
+	// rows of unlimited pages are unknown
+	var orders []order
+	q := newRecordQuery(&orders, "orders")
+	q.rows = 3
+	p := New()
+	p.SetLimit(Unlimited)
+	p.SetPreallocate(true)
+	_, err := p.Paginate(q)
+	s.Nil(err)
+	s.Equal([]int{3, 2, 1}, orderIDs(orders))
+}
+
+func (s *paginatorSuite) TestPaginateCopyResult() {
+	var orders = s.givenOrders(6)
+
+	p := New()
+	p.SetLimit(2)
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.Equal(&o1, p.GetPage())
+
+	p = New()
+	p.SetLimit(2)
+	p.SetCopyResult(true)
+	p.SetBeforeCursor(NewCursorEncoder("ID").Encode(orders[1]))
+	var o2 []order
+	cursor := s.paginateBy(p, s.db, &o2)
+	// out is left as scanned, in flipped order with extra row
+	s.Len(o2, 3)
+	s.assertOrders(orders, 2, 4, o2)
+	s.assertOrders(orders, 3, 2, *p.GetPage().(*[]order))
+	s.assertBoth(cursor)
+}
+
+func (s *paginatorSuite) TestWriteJSON() {
+	s.givenOrders(3)
+	p := New()
+	p.SetLimit(2)
+	var orders []order
+	s.paginateBy(p, s.db, &orders)
+
+	var buf strings.Builder
+	s.Nil(p.WriteJSON(&buf))
+	expected, err := json.Marshal(map[string]interface{}{"items": orders, "paging": p.GetPaging()})
+	s.Nil(err)
+	s.JSONEq(string(expected), buf.String())
+
+	// empty pages are written as []
+	buf.Reset()
+	s.Nil(New().WriteJSON(&buf))
+	s.JSONEq(`{"items":[],"paging":{"has_next":false}}`, buf.String())
+
+	s.Equal(io.ErrShortWrite, p.WriteJSON(failingWriter{}))
+}
+
+func (s *paginatorSuite) TestPaginateClone() {
+	s.givenOrders(5)
+	template := New()
+	template.SetKeys("CreatedAt")
+	template.SetLimit(2)
+	template.SetOrder(ASC)
+
+	var o1 []order
+	p := template.Clone()
+	p.SetKeys("ID")
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Equal([]int{1, 2}, orderIDs(o1))
+
+	// keys of clone are not appended to template
+	s.Equal([]Rule{{Key: "CreatedAt"}}, template.rules)
+
+	var o2 []order
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{3, 4}, orderIDs(o2))
+
+	// cursor and page of clone are not carried to clones of clone
+	c := p.Clone()
+	s.Equal(Cursor{}, c.cursor)
+	s.Nil(c.GetPage())
+	s.Equal(Cursor{}, c.GetNextCursor())
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p := template.Clone()
+			p.SetKeys("ID")
+			var orders []order
+			_, errs[i] = p.Paginate(newGormQuery(s.db, &orders))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		s.Nil(err)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateBatch() {
+	s.givenOrders(5)
+
+	newPaginator := func(limit int, order Order) *Paginator {
+		p := New()
+		p.SetLimit(limit)
+		p.SetOrder(order)
+		return p
+	}
+	latest := newPaginator(2, DESC)
+	earliest := newPaginator(3, ASC)
+	invalid := newPaginator(2, DESC)
+	invalid.SetAfterCursor("invalid")
+	all := newPaginator(10, DESC)
+	var o1, o2, o3, o4, o5 []order
+	results := PaginateBatch(2,
+		BatchItem{Paginator: latest, Query: newGormQuery(s.db, &o1)},
+		BatchItem{Paginator: earliest, Query: newGormQuery(s.db, &o2)},
+		BatchItem{Paginator: invalid, Query: newGormQuery(s.db, &o3)},
+		BatchItem{Paginator: all, Query: newGormQuery(s.db, &o4)},
+		BatchItem{Paginator: latest, Query: newGormQuery(s.db, &o5)},
+	)
+	s.Len(results, 5)
+
+	s.Nil(results[0].Err)
+	s.Nil(results[0].Query.(*gormQuery).db.Error)
+	s.Equal([]int{5, 4}, orderIDs(o1))
+	s.NotNil(results[0].Cursor.After)
+
+	s.Nil(results[1].Err)
+	s.Equal([]int{1, 2, 3}, orderIDs(o2))
+	s.Equal(earliest.GetNextCursor(), results[1].Cursor)
+
+	s.True(errors.Is(results[2].Err, ErrInvalidCursor))
+
+	s.Nil(results[3].Err)
+	s.Equal([]int{5, 4, 3, 2, 1}, orderIDs(o4))
+	s.Nil(results[3].Cursor.After)
+
+	// paginator of prior item is not shared
+	s.Equal(ErrBatchPaginatorShared, results[4].Err)
+	s.Empty(o5)
+}
+
+func (s *paginatorSuite) TestPaginateBatchShouldReturnErrorOfInvalidItem() {
+	s.givenOrders(3)
+	var out []order
+	results := PaginateBatch(0,
+		BatchItem{Query: newGormQuery(s.db, &[]order{})},
+		BatchItem{Paginator: New()},
+		BatchItem{Paginator: New(), Query: (*gormQuery)(nil)},
+		BatchItem{Paginator: New(), Query: newGormQuery(s.db, &out)},
+	)
+	s.Len(results, 4)
+	for _, result := range results[:3] {
+		s.Equal(ErrBatchItemInvalid, result.Err)
+	}
+	s.Nil(results[3].Err)
+	s.Equal([]int{3, 2, 1}, orderIDs(out))
+}
+
+func (s *paginatorSuite) TestPaginateReuse() {
+	var orders = s.givenOrders(5)
+	p := New()
+	p.SetKeys("CreatedAt")
+	p.SetTieBreaker(true)
+	p.SetLimit(2)
+
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 4, 3, o1)
+	s.Equal([]Rule{{Key: "CreatedAt"}}, p.rules)
+	s.Empty(p.keys)
+
+	// configuration is left as it is, so that the same paginator pages on
+	p.SetAfterCursor(*cursor.After)
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 2, 1, o2)
+
+	var o3 []order
+	s.paginateBy(p, s.db, &o3)
+	s.assertOrders(orders, 2, 1, o3)
+	s.Len(p.GetCursors(), 2)
+}
+
+func (s *paginatorSuite) TestPaginateConcurrently() {
+	p := New()
+	p.SetKeys("ID")
+	p.SetLimit(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q := newRecordQuery(&[]order{}, "orders")
+			q.rows = 5
+			_, err := p.Paginate(q)
+			s.Nil(err)
+			s.NotNil(p.GetNextCursor().After)
+			s.Len(p.GetCursors(), 2)
+		}()
+	}
+	wg.Wait()
+	s.Equal([]int{5, 4}, orderIDs(*p.GetPage().(*[]order)))
+	s.Equal(2, p.GetPageInfo().Limit)
+	s.Equal([]Rule{{Key: "ID"}}, p.rules)
+}
+
+func (s *paginatorSuite) TestPaginateInto() {
+	s.givenOrders(5)
+	p := New()
+	p.SetLimit(2)
+	var feed orderFeed
+	result, err := p.PaginateInto(newGormQuery(s.db, &[]order{}), &feed)
+	s.Nil(err)
+	s.Nil(result.(*gormQuery).db.Error)
+	s.Equal([]int{5, 4}, feed.ids)
+
+	cursor := p.GetNextCursor()
+	p = New()
+	p.SetLimit(2)
+	p.SetAfterCursor(*cursor.After)
+	_, err = p.PaginateInto(newGormQuery(s.db, &[]order{}), &feed)
+	s.Nil(err)
+	s.Equal([]int{5, 4, 3, 2}, feed.ids)
+}
+
+func (s *paginatorSuite) TestPaginateInclusive() {
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	var orders []order
+	for i := 0; i < 5; i++ {
+		orders = append(orders, order{CreatedAt: createdAt.Add(time.Duration(i) * time.Hour)})
+	}
+	s.givenCustomOrders(orders)
+	encoder := NewCursorEncoder("CreatedAt", "ID")
+
+	for _, predicate := range []Predicate{PredicateOR, PredicateTuple, PredicateRange} {
+		newPaginator := func() *Paginator {
+			p := New()
+			p.SetKeys("CreatedAt", "ID")
+			p.SetPredicate(predicate)
+			p.SetInclusive(true)
+			p.SetLimit(2)
+			return p
+		}
+		var o1 []order
+		p := newPaginator()
+		p.SetAfterCursor(encoder.Encode(orders[3]))
+		cursor := s.paginateBy(p, s.db, &o1)
+		s.assertOrders(orders, 3, 2, o1)
+		s.assertBoth(cursor)
+
+		var o2 []order
+		p = newPaginator()
+		p.SetBeforeCursor(encoder.Encode(orders[1]))
+		cursor = s.paginateBy(p, s.db, &o2)
+		s.assertOrders(orders, 2, 1, o2)
+		s.assertBoth(cursor)
+
+		var o3 []order
+		p = newPaginator()
+		p.SetAfterCursor(encoder.Encode(orders[1]))
+		cursor = s.paginateBy(p, s.db, &o3)
+		s.assertOrders(orders, 1, 0, o3)
+		s.assertOnlyBefore(cursor)
+	}
+}
+
+func (s *paginatorSuite) TestPageInfoApplied() {
+	s.givenOrders(3)
+	var o1 []order
+	p := New()
+	s.paginateBy(p, s.db, &o1)
+	info := p.GetPageInfo()
+	s.Equal(defaultLimit, info.Limit)
+	s.Equal(DESC, info.Order)
+	s.Equal([]string{"ID"}, info.Keys)
+
+	var o2 []order
+	p = New()
+	p.SetKeys("CreatedAt")
+	p.SetTieBreaker(true)
+	p.SetLimit(2)
+	p.SetOrder(ASC)
+	s.paginateBy(p, s.db, &o2)
+	info = p.GetPageInfo()
+	s.Equal(2, info.Limit)
+	s.Equal(ASC, info.Order)
+	s.Equal([]string{"CreatedAt", "ID"}, info.Keys)
+}
+
+func (s *paginatorSuite) TestPaginateResumeCursor() {
+	var orders = s.givenOrders(2)
+	after := NewCursorEncoder("ID").Encode(orders[1])
+
+	var o1 []order
+	p := New()
+	p.SetOrder(ASC)
+	p.SetAfterCursor(after)
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Empty(o1)
+	s.Equal(Cursor{}, cursor)
+	resume := p.GetPageInfo().Resume
+	s.Equal(Cursor{After: &after}, resume)
+
+	newOrders := s.givenOrders(1)
+	var o2 []order
+	p = New()
+	p.SetOrder(ASC)
+	p.SetAfterCursor(*resume.After)
+	s.paginateBy(p, s.db, &o2)
+	s.assertOrders(newOrders, 0, 0, o2)
+	s.Empty(p.GetPageInfo().Resume)
+}
+
+func (s *paginatorSuite) TestPaginateTailAfter() {
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	orders := s.givenCustomOrders([]order{
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+	})
+	tail := func(cursor string, out *[]order) Cursor {
+		p := New()
+		p.SetKeys("CreatedAt")
+		p.SetLimit(2)
+		p.TailAfter(cursor)
+		return s.paginateBy(p, s.db, out)
+	}
+	var o1 []order
+	cursor := tail("", &o1)
+	s.assertOrders(orders, 0, 1, o1)
+	s.NotNil(cursor.After)
+
+	var o2 []order
+	cursor = tail(*cursor.After, &o2)
+	s.assertOrders(orders, 2, 2, o2)
+	s.NotNil(cursor.After)
+
+	var o3 []order
+	next := tail(*cursor.After, &o3)
+	s.Empty(o3)
+	s.Equal(cursor.After, next.After)
+
+	newOrders := s.givenCustomOrders([]order{{CreatedAt: createdAt}})
+	var o4 []order
+	tail(*next.After, &o4)
+	s.assertOrders(newOrders, 0, 0, o4)
+}
+
+func (s *paginatorSuite) TestPaginateCollection() {
+	s.givenOrders(5)
+	for _, skipFlip := range []bool{false, true} {
+		newPaginator := func() *Paginator {
+			p := New()
+			p.SetKeys("CreatedAt", "ID")
+			p.SetSkipFlip(skipFlip)
+			p.SetLimit(2)
+			return p
+		}
+		var o1 orderList
+		cursor := s.paginateBy(newPaginator(), s.db, &o1)
+		s.Equal([]int{5, 4}, orderIDs(o1))
+
+		var o2 orderList
+		p := newPaginator()
+		p.SetAfterCursor(*cursor.After)
+		cursor = s.paginateBy(p, s.db, &o2)
+		s.Equal([]int{3, 2}, orderIDs(o2))
+
+		// cursors are the same as of reflection
+		var ref []order
+		p = newPaginator()
+		p.SetAfterCursor(*s.paginateBy(newPaginator(), s.db, &ref).After)
+		s.Equal(cursor, s.paginateBy(p, s.db, &ref))
+
+		var o3 orderList
+		p = newPaginator()
+		p.SetBeforeCursor(*cursor.Before)
+		s.paginateBy(p, s.db, &o3)
+		if skipFlip {
+			s.Equal([]int{4, 5}, orderIDs(o3))
+		} else {
+			s.Equal([]int{5, 4}, orderIDs(o3))
+		}
+	}
+}
+
+func (s *paginatorSuite) TestPaginateSkipFlip() {
+	var orders = s.givenOrders(6)
+	before := NewCursorEncoder("ID").Encode(orders[1])
+
+	p := New()
+	p.SetLimit(2)
+	p.SetBeforeCursor(before)
+	var o1 []order
+	expected := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 3, 2, o1)
+
+	p = New()
+	p.SetLimit(2)
+	p.SetSkipFlip(true)
+	p.SetBeforeCursor(before)
+	var o2 []order
+	cursor := s.paginateBy(p, s.db, &o2)
+	// page is left in queried order, cursors are as if it is flipped
+	s.assertOrders(orders, 2, 3, o2)
+	s.Equal(expected, cursor)
+}
+
+func (s *paginatorSuite) TestPaginateStrictCursors() {
+	var orders = s.givenOrders(4)
+	after := NewCursorEncoder("ID").Encode(orders[3])
+	before := NewCursorEncoder("ID").Encode(orders[0])
+
+	// before cursor is ignored by default
+	p := New()
+	p.SetAfterCursor(after)
+	p.SetBeforeCursor(before)
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 2, 0, o1)
+
+	p = New()
+	p.SetStrictCursors(true)
+	p.SetAfterCursor(after)
+	p.SetBeforeCursor(before)
+	var o2 []order
+	_, err := p.Paginate(newGormQuery(s.db, &o2))
+	s.Equal(ErrCursorConflict, err)
+
+	p = New()
+	p.SetStrictCursors(true)
+	p.SetBeforeCursor(before)
+	var o3 []order
+	s.paginateBy(p, s.db, &o3)
+	s.assertOrders(orders, 3, 1, o3)
+}
+
+func (s *paginatorSuite) TestPaginateSort() {
+	createdAt := time.Now().Truncate(time.Second)
+	s.givenCustomOrders([]order{
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt.Add(time.Hour)},
+		{CreatedAt: createdAt.Add(time.Hour)},
+		{CreatedAt: createdAt.Add(-time.Hour)},
+	})
+	fields := map[string]string{"created_at": "CreatedAt", "id": "ID"}
+
+	for _, predicate := range []Predicate{PredicateOR, PredicateTuple, PredicateRange} {
+		newPaginator := func() *Paginator {
+			p := New()
+			s.Nil(p.SetSort("-created_at, id", fields))
+			p.SetPredicate(predicate)
+			p.SetLimit(2)
+			return p
+		}
+		var o1 []order
+		cursor := s.paginateBy(newPaginator(), s.db, &o1)
+		s.Equal([]int{3, 4}, orderIDs(o1))
+
+		var o2 []order
+		p := newPaginator()
+		p.SetAfterCursor(*cursor.After)
+		cursor = s.paginateBy(p, s.db, &o2)
+		s.Equal([]int{1, 2}, orderIDs(o2))
+
+		var o3 []order
+		p = newPaginator()
+		p.SetAfterCursor(*cursor.After)
+		s.paginateBy(p, s.db, &o3)
+		s.Equal([]int{5}, orderIDs(o3))
+
+		var o4 []order
+		p = newPaginator()
+		p.SetBeforeCursor(*cursor.Before)
+		s.paginateBy(p, s.db, &o4)
+		s.Equal([]int{3, 4}, orderIDs(o4))
+	}
+}
+
+func (s *paginatorSuite) TestParseSort() {
+	fields := map[string]string{"created_at": "CreatedAt", "id": "ID"}
+	rules, err := ParseSort("-created_at,+id", fields)
+	s.Nil(err)
+	s.Equal([]Rule{{Key: "CreatedAt", Order: DESC}, {Key: "ID", Order: ASC}}, rules)
+
+	rules, err = ParseSort("", fields)
+	s.Nil(err)
+	s.Empty(rules)
+
+	for _, sort := range []string{"-name", "id,-id", "-"} {
+		_, err = ParseSort(sort, fields)
+		s.True(errors.Is(err, ErrInvalidSort), sort)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateTable() {
+	var orders = s.givenOrders(5)
+	newStmt := func() *gorm.DB {
+		return s.db.Table("(?) AS recent", s.db.Model(&order{}).Where("id > ?", orders[0].ID))
+	}
+
+	p := New()
+	p.SetTable("recent")
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, newStmt(), &o1)
+	s.assertOrders(orders, 4, 3, o1)
+
+	p = New()
+	p.SetTable("recent")
+	p.SetAfterCursor(*cursor.After)
+	var o2 []order
+	s.paginateBy(p, newStmt(), &o2)
+	s.assertOrders(orders, 2, 1, o2)
+
+	p = New()
+	p.SetTable("recent")
+	p.SetAfterCursor(*cursor.After)
+	where, _, orderBy, _ := p.BuildSQL(&order{})
+	s.Equal("recent.id < ?", where)
+	s.Equal("recent.id DESC", orderBy)
+}
+
+func (s *paginatorSuite) TestPaginateCTE() {
+	var orders = s.givenOrders(5)
+	newStmt := func() *gorm.DB {
+		return s.db.Where("id > ?", orders[0].ID)
+	}
+
+	p := New()
+	p.SetCTE("recent")
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, newStmt(), &o1)
+	s.assertOrders(orders, 4, 3, o1)
+
+	p = New()
+	p.SetCTE("recent")
+	p.SetAfterCursor(*cursor.After)
+	var o2 []order
+	cursor = s.paginateBy(p, newStmt(), &o2)
+	s.assertOrders(orders, 2, 1, o2)
+	s.assertOnlyBefore(cursor)
+
+	p = New()
+	p.SetCTE("recent")
+	p.SetAfterCursor(*cursor.Before)
+	where, _, orderBy, _ := p.BuildSQL(&order{})
+	s.Equal("recent.id < ?", where)
+	s.Equal("recent.id DESC", orderBy)
+
+	var out []order
+	p = New()
+	p.SetCTE("recent")
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrCTENotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateHint() {
+	var orders = s.givenOrders(4)
+
+	p := New()
+	p.SetHint(IndexHint(MySQL, "orders", "PRIMARY"))
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 3, 2, o1)
+
+	p = New()
+	p.SetHint(IndexHint(MySQL, "orders", "PRIMARY"))
+	p.SetAfterCursor(*cursor.After)
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 1, 0, o2)
+
+	for _, c := range []struct {
+		dialect   Dialect
+		statement string
+	}{
+		{MySQL, "SELECT * FROM `orders` USE INDEX (`idx`) ORDER BY `orders`.`id` DESC LIMIT 11"},
+		{Postgres, `/*+ IndexScan(orders idx) */ SELECT * FROM "orders" ORDER BY "orders"."id" DESC LIMIT 11`},
+		{SQLite, `SELECT * FROM "orders" INDEXED BY "idx" ORDER BY "orders"."id" DESC LIMIT 11`},
+		{Oracle, `SELECT /*+ INDEX(orders idx) */ * FROM "ORDERS" ORDER BY "ORDERS"."ID" DESC OFFSET 0 ROWS FETCH NEXT 11 ROWS ONLY`},
+	} {
+		p := New()
+		p.SetDialect(c.dialect)
+		p.SetHint(IndexHint(c.dialect, "orders", "idx"))
+		statement, _ := p.BuildStatement(&order{})
+		s.Equal(c.statement, statement)
+	}
+
+	var out []order
+	p = New()
+	p.SetHint(Hint{Table: "USE INDEX (PRIMARY)"})
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrHintNotSupported, err)
+}
+
+func (s *paginatorSuite) TestAsOfSystemTime() {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := New()
+	p.now = func() time.Time { return now }
+	p.SetAsOfSystemTime(-5 * time.Second)
+	p.SetHint(Hint{Table: "@idx"})
+	statement, _ := p.BuildStatement(&order{})
+	s.Equal("SELECT * FROM orders @idx AS OF SYSTEM TIME '2020-10-01 11:59:55' ORDER BY orders.id DESC LIMIT 11", statement)
+
+	// snapshot of first page is kept by next pages
+	var out []order
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrHintNotSupported, err)
+	s.Equal(now.Add(-5*time.Second), p.GetSnapshotTime())
+	resumed, err := NewFromState(p.EncodeState())
+	s.Nil(err)
+	resumed.now = func() time.Time { return now.Add(time.Minute) }
+	s.Equal(p.GetSnapshotTime(), resumed.getSnapshotTime())
+
+	// fresh listing by the same paginator reads a new snapshot
+	p.now = func() time.Time { return now.Add(time.Minute) }
+	_, err = p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrHintNotSupported, err)
+	s.Equal(now.Add(55*time.Second), p.GetSnapshotTime())
+
+	p = New()
+	p.SetSnapshotTime(now)
+	statement, _ = p.BuildStatement(&order{})
+	s.Equal("SELECT * FROM orders AS OF SYSTEM TIME '2020-10-01 12:00:00' ORDER BY orders.id DESC LIMIT 11", statement)
+}
+
+func (s *paginatorSuite) TestEachPageAsOfSystemTime() {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	p := New()
+	p.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	p.SetAsOfSystemTime(5 * time.Second)
+	p.SetLimit(2)
+	p.SetMaxTotalRows(4)
+	var hints []Hint
+	err := p.EachPage(func() Query {
+		q := newRecordQuery(&[]order{}, "orders")
+		q.rows = 3
+		return &hintRecordQuery{recordQuery: q, hints: &hints}
+	}, func(page interface{}) error { return nil })
+	s.Nil(err)
+	// pages of the walk read the snapshot of the first page
+	s.Len(hints, 2)
+	s.Equal(hints[0], hints[1])
+	s.Equal("AS OF SYSTEM TIME '2020-10-01 12:00:55'", hints[0].Table)
+}
+
+func (s *paginatorSuite) TestPaginateMapDestination() {
+	s.givenOrders(3)
+
+	var stmt = s.db.Table("orders")
+	var m1 []map[string]interface{}
+	cursor := s.paginate(stmt, &m1, pq{
+		Keys:  []string{"CreatedAt", "ID"},
+		Limit: pqLimit(2),
+	})
+	s.Equal([]int{3, 2}, mapIDs(m1))
+	s.assertOnlyAfter(cursor)
+
+	var m2 []map[string]interface{}
+	cursor = s.paginate(s.db.Table("orders"), &m2, pq{
+		Keys:  []string{"CreatedAt", "ID"},
+		After: cursor.After,
+	})
+	s.Equal([]int{1}, mapIDs(m2))
+	s.assertOnlyBefore(cursor)
+
+	var m3 []map[string]interface{}
+	cursor = s.paginate(s.db.Table("orders"), &m3, pq{
+		Keys:   []string{"CreatedAt", "ID"},
+		Before: cursor.Before,
+	})
+	s.Equal(mapIDs(m1), mapIDs(m3))
+	s.assertOnlyAfter(cursor)
+}
+
+func (s *paginatorSuite) TestPaginateMapDestinationByTimeOrderedKey() {
+	now := time.Now()
+	s.givenCustomOrders([]order{
+		{ID: 1, Name: pqString("01HX1"), CreatedAt: now},
+		{ID: 2, Name: pqString("01HX2"), CreatedAt: now},
+		{ID: 3, Name: pqString("01HX3"), CreatedAt: now},
+	})
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetTimeOrderedKey("Name")
+		p.SetLimit(2)
+		return p
+	}
+	var m1 []map[string]interface{}
+	cursor := s.paginateBy(newPaginator(), s.db.Table("orders"), &m1)
+	s.Equal([]int{3, 2}, mapIDs(m1))
+	// marker and name encoded without JSON
+	s.Len(*cursor.After, 8)
+
+	var m2 []map[string]interface{}
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db.Table("orders"), &m2)
+	s.Equal([]int{1}, mapIDs(m2))
+
+	var m3 []map[string]interface{}
+	p = newPaginator()
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, s.db.Table("orders"), &m3)
+	s.Equal([]int{3, 2}, mapIDs(m3))
+}
+
+// mapIDs returns ids of map rows, which are scanned as either int64 or raw
+// bytes depending on protocol of the driver
+func mapIDs(rows []map[string]interface{}) []int {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		switch id := row["id"].(type) {
+		case int64:
+			ids[i] = int(id)
+		case []byte:
+			ids[i], _ = strconv.Atoi(string(id))
+		}
+	}
+	return ids
+}
+
+type taggedOrder struct {
+	ID          int        `paginator:"column:id"`
+	Name        *string    `paginator:"-"`
+	PublishedAt *time.Time `paginator:"order:asc;null:last"`
+}
+
+func (taggedOrder) TableName() string {
+	return "orders"
+}
+
+func (s *paginatorSuite) TestPaginateTaggedModel() {
+	rules, defaultOrder := taggedRules(reflect.TypeOf(taggedOrder{}))
+	s.Equal([]Rule{
+		{Key: "ID", Column: "id"},
+		{Key: "PublishedAt", Nulls: NullsLast},
+	}, rules)
+	s.Equal(ASC, defaultOrder)
+
+	now := time.Now()
+	s.givenCustomOrders([]order{
+		{ID: 1, CreatedAt: now},
+		{ID: 2, CreatedAt: now, PublishedAt: pqTime(now)},
+		{ID: 3, CreatedAt: now},
+	})
+	var t1 []taggedOrder
+	cursor := s.paginate(s.db, &t1, pq{Limit: pqLimit(2)})
+	s.Equal([]int{1, 2}, []int{t1[0].ID, t1[1].ID})
+	s.assertOnlyAfter(cursor)
+}
+
+func (s *paginatorSuite) TestPaginateNullsRule() {
+	now := time.Now().Truncate(time.Second)
+	s.givenCustomOrders([]order{
+		{ID: 1, CreatedAt: now, PublishedAt: pqTime(now.Add(2 * time.Hour))},
+		{ID: 2, CreatedAt: now},
+		{ID: 3, CreatedAt: now, PublishedAt: pqTime(now.Add(time.Hour))},
+		{ID: 4, CreatedAt: now},
+		{ID: 5, CreatedAt: now, PublishedAt: pqTime(now.Add(time.Hour))},
+	})
+	ids := func(orders []order) (ids []int) {
+		for _, o := range orders {
+			ids = append(ids, o.ID)
+		}
+		return
+	}
+	for _, c := range []struct {
+		nulls Nulls
+		order Order
+		pages [][]int
+	}{
+		{NullsLast, ASC, [][]int{{3, 5}, {1, 2}, {4}}},
+		{NullsFirst, ASC, [][]int{{2, 4}, {3, 5}, {1}}},
+		{NullsLast, DESC, [][]int{{1, 5}, {3, 4}, {2}}},
+		{NullsFirst, DESC, [][]int{{4, 2}, {1, 5}, {3}}},
+	} {
+		rules := []Rule{{Key: "PublishedAt", Nulls: c.nulls}, {Key: "ID"}}
+		var pages [][]int
+		var cursors []Cursor
+		cursor := Cursor{}
+		for i := 0; i < 3; i++ {
+			var out []order
+			cursor = s.paginate(s.db, &out, pq{Rules: rules, Order: pqOrder(c.order), Limit: pqLimit(2), After: cursor.After})
+			pages = append(pages, ids(out))
+			cursors = append(cursors, cursor)
+		}
+		s.Equal(c.pages, pages, c)
+		s.Nil(cursors[2].After)
+
+		for i := 2; i > 0; i-- {
+			var out []order
+			s.paginate(s.db, &out, pq{Rules: rules, Order: pqOrder(c.order), Limit: pqLimit(2), Before: cursors[i].Before})
+			s.Equal(c.pages[i-1], ids(out), c)
+		}
+	}
+}
+
+func (s *paginatorSuite) TestPaginateShouldIgnoreEmptyCursor() {
+	p := New()
+	p.SetAfterCursor("")
+	var out []order
+	q := newRecordQuery(&out, "orders")
+	_, err := p.Paginate(q)
+	s.NoError(err)
+	s.Empty(q.wheres)
+}
+
+func (s *paginatorSuite) TestPaginateShouldReturnErrorWhenModelIsNotStruct() {
+	var out []int
+	_, err := New().Paginate(newGormQuery(s.db, &out))
+	s.Equal(ErrInvalidModel, err)
+}
+
+func (s *paginatorSuite) TestPaginateTracer() {
+	s.givenOrders(3)
+
+	tracer := &recordTracer{}
+	p := New()
+	p.SetTracer(tracer)
+	p.SetLimit(2)
+	p.SetOrder(ASC)
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+
+	s.Len(tracer.spans, 1)
+	span := tracer.spans[0]
+	s.Equal(spanName, span.name)
+	s.True(span.ended)
+	s.Nil(span.err)
+	s.Equal(map[string]interface{}{
+		AttributeKeys:        []string{"ID"},
+		AttributeLimit:       2,
+		AttributeOrder:       "ASC",
+		AttributeDirection:   "none",
+		AttributeResultCount: 2,
+		AttributeHasMore:     true,
+	}, span.attributes)
+
+	p = New()
+	p.SetTracer(tracer)
+	p.SetAfterCursor(*cursor.After)
+	p.SetOrder(ASC)
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.Equal("after", tracer.spans[1].attributes[AttributeDirection])
+	s.Equal(1, tracer.spans[1].attributes[AttributeResultCount])
+	s.Equal(false, tracer.spans[1].attributes[AttributeHasMore])
+}
+
+func (s *paginatorSuite) TestPaginateTracerShouldRecordError() {
+	tracer := &recordTracer{}
+	p := New()
+	p.SetTracer(tracer)
+	var out []int
+	_, err := p.Paginate(newGormQuery(s.db, &out))
+	s.Equal(ErrInvalidModel, err)
+	s.Equal(ErrInvalidModel, tracer.spans[0].err)
+	s.True(tracer.spans[0].ended)
+}
+
+func (s *paginatorSuite) TestPaginateMetrics() {
+	s.givenOrders(3)
+
+	metrics := &recordMetrics{}
+	p := New()
+	p.SetMetrics(metrics)
+	p.SetLimit(2)
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.Equal([]float64{1}, metrics.fillRatios)
+	s.Equal(0, metrics.invalidCursors)
+
+	p = New()
+	p.SetMetrics(metrics)
+	p.SetLimit(4)
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]float64{1, 0.75}, metrics.fillRatios)
+
+	p = New()
+	p.SetMetrics(metrics)
+	p.SetAfterCursor("invalid cursor")
+	var o3 []order
+	_, err := p.Paginate(newGormQuery(s.db, &o3))
+	s.True(errors.Is(err, ErrInvalidCursor))
+	s.Equal([]float64{1, 0.75}, metrics.fillRatios)
+	s.Equal(1, metrics.invalidCursors)
+}
+
+func (s *paginatorSuite) TestPaginateLogger() {
+	s.givenOrders(3)
+
+	logger := &recordLogger{}
+	p := New()
+	p.SetLogger(logger)
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Len(logger.entries, 1)
+	s.Equal("", logger.entries[0].Where)
+	s.Equal("orders.id DESC", logger.entries[0].Order)
+	s.Equal(3, logger.entries[0].Limit)
+
+	p = New()
+	p.SetLogger(logger)
+	p.SetVerbose(true)
+	p.SetAfterCursor(*cursor.After)
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.Equal("orders.id < ?", logger.entries[1].Where)
+	s.Equal([]interface{}{2}, logger.entries[1].Args)
+	s.Nil(logger.entries[1].Err)
+
+	// args are redacted by default
+	p = New()
+	p.SetLogger(logger)
+	p.SetAfterCursor(*cursor.After)
+	var o3 []order
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]interface{}{RedactedArg}, logger.entries[2].Args)
+}
+
+func (s *paginatorSuite) TestPaginateHooks() {
+	s.givenOrders(3)
+
+	var calls []string
+	p := New()
+	p.SetLimit(2)
+	p.SetBeforePaginate(func(query Query) (Query, error) {
+		calls = append(calls, "before")
+		return query.Where("orders.id > ?", 1), nil
+	})
+	p.SetAfterPaginate(func(result interface{}, cursor Cursor) error {
+		calls = append(calls, "after")
+		s.Len(*result.(*[]order), 2)
+		s.Nil(cursor.After)
+		return nil
+	})
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.Equal([]string{"before", "after"}, calls)
+	s.Equal([]int{3, 2}, []int{o1[0].ID, o1[1].ID})
+}
+
+func (s *paginatorSuite) TestPaginateTransform() {
+	s.givenOrders(7)
+
+	newPaginator := func(keep func(o order) bool) *Paginator {
+		p := New()
+		p.SetLimit(3)
+		p.SetTransform(func(page interface{}) error {
+			orders := page.(*[]order)
+			var kept []order
+			for _, o := range *orders {
+				if keep(o) {
+					kept = append(kept, o)
+				}
+			}
+			*orders = kept
+			return nil
+		})
+		return p
+	}
+	even := func(o order) bool { return o.ID%2 == 0 }
+
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(even), s.db, &o1)
+	s.Equal([]int{6}, orderIDs(o1))
+
+	// next page starts after the last row received
+	var o2 []order
+	p := newPaginator(even)
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{4}, orderIDs(o2))
+
+	var o3 []order
+	p = newPaginator(even)
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]int{6}, orderIDs(o3))
+
+	// cursors are encoded from fetched rows if all rows are removed
+	var o4 []order
+	cursor = s.paginateBy(newPaginator(func(order) bool { return false }), s.db, &o4)
+	s.Len(o4, 0)
+	var o5 []order
+	p = New()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o5)
+	s.Equal([]int{4, 3, 2, 1}, orderIDs(o5))
+}
+
+func (s *paginatorSuite) TestPaginateTransformShouldReturnError() {
+	s.givenOrders(2)
+
+	transformErr := errors.New("transform error")
+	p := New()
+	p.SetTransform(func(page interface{}) error {
+		return transformErr
+	})
+	var orders []order
+	_, err := p.Paginate(newGormQuery(s.db, &orders))
+	s.Equal(transformErr, err)
+}
+
+func (s *paginatorSuite) TestPaginateHooksShouldReturnError() {
+	hookErr := errors.New("hook error")
+
+	p := New()
+	p.SetBeforePaginate(func(query Query) (Query, error) {
+		return query, hookErr
+	})
+	var o1 []order
+	_, err := p.Paginate(newRecordQuery(&o1, "orders"))
+	s.Equal(hookErr, err)
+
+	p = New()
+	p.SetAfterPaginate(func(result interface{}, cursor Cursor) error {
+		return hookErr
+	})
+	var o2 []order
+	_, err = p.Paginate(newRecordQuery(&o2, "orders"))
+	s.Equal(hookErr, err)
+}
+
+func (s *paginatorSuite) TestEachPage() {
+	s.givenOrders(5)
+
+	calls := 0
+	p := New()
+	p.SetLimit(2)
+	p.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	p.SetBeforePaginate(func(query Query) (Query, error) {
+		if calls++; calls == 2 {
+			return query, driver.ErrBadConn
+		}
+		return query, nil
+	})
+	var ids []int
+	err := p.EachPage(func() Query {
+		var orders []order
+		return newGormQuery(s.db, &orders)
+	}, func(page interface{}) error {
+		for _, o := range *page.(*[]order) {
+			ids = append(ids, o.ID)
+		}
+		return nil
+	})
+	s.Nil(err)
+	s.Equal([]int{5, 4, 3, 2, 1}, ids)
+	s.Equal(4, calls)
+}
+
+func (s *paginatorSuite) TestEachPageShouldStopAtError() {
+	s.givenOrders(5)
+
+	p := New()
+	p.SetLimit(2)
+	p.SetBeforePaginate(func(query Query) (Query, error) {
+		return query, driver.ErrBadConn
+	})
+	err := p.EachPage(func() Query {
+		var orders []order
+		return newGormQuery(s.db, &orders)
+	}, func(page interface{}) error {
+		return nil
+	})
+	s.Equal(driver.ErrBadConn, err)
+
+	fnErr := errors.New("fn error")
+	p = New()
+	p.SetLimit(2)
+	pages := 0
+	err = p.EachPage(func() Query {
+		var orders []order
+		return newGormQuery(s.db, &orders)
+	}, func(page interface{}) error {
+		if pages++; pages == 2 {
+			return fnErr
+		}
+		return nil
+	})
+	s.Equal(fnErr, err)
+	// cursor is left at the failed page to resume
+	var orders []order
+	s.paginateBy(p, s.db, &orders)
+	s.Equal([]int{3, 2}, []int{orders[0].ID, orders[1].ID})
+}
+
+func (s *paginatorSuite) TestPaginateQueryTimeout() {
+	s.givenOrders(3)
+	var o1 []order
+	p := New()
+	p.SetQueryTimeout(time.Minute)
+	s.paginateBy(p, s.db, &o1)
+	s.Equal([]int{3, 2, 1}, orderIDs(o1))
+
+	var o2 []order
+	p = New()
+	p.SetQueryTimeout(time.Nanosecond)
+	result, err := p.Paginate(newGormQuery(s.db, &o2))
+	s.Nil(err)
+	s.True(errors.Is(result.(*gormQuery).db.Error, context.DeadlineExceeded))
+
+	_, err = p.Paginate(newRecordQuery(&[]order{}, "orders"))
+	s.Equal(ErrContextNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateOffsetFallback() {
+	s.givenOrders(10)
+	newPaginator := func(offset int) *Paginator {
+		p := New()
+		p.SetOffsetFallback(4)
+		p.SetOffset(offset)
+		p.SetLimit(2)
+		return p
+	}
+
+	// jump to the second page by offset
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(2), s.db, &o1)
+	s.Equal([]int{8, 7}, orderIDs(o1))
+	s.NotNil(cursor.Before)
+
+	// pages go on by keyset cursor
+	var o2 []order
+	p := newPaginator(0)
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{6, 5}, orderIDs(o2))
+
+	// skips are from cursor
+	var o3 []order
+	p = newPaginator(2)
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]int{2, 1}, orderIDs(o3))
+
+	var o4 []order
+	p = newPaginator(2)
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, s.db, &o4)
+	s.Equal([]int{10, 9}, orderIDs(o4))
+
+	var o5 []order
+	_, err := newPaginator(6).Paginate(newGormQuery(s.db, &o5))
+	s.True(errors.Is(err, ErrOffsetTooLarge))
+}
+
+func (s *paginatorSuite) TestEachPageInTx() {
+	s.givenOrders(5)
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetOrder(ASC)
+		p.SetLimit(2)
+		return p
+	}
+	query := func(tx Tx) Query {
+		var orders []order
+		return newGormQuery(tx.(gormTx).db, &orders)
+	}
+	// each page adds an item of its first order within the transaction
+	addItem := func(tx Tx, page interface{}) error {
+		orders := *page.(*[]order)
+		return tx.(gormTx).db.Create(&item{OrderID: orders[0].ID}).Error
+	}
+	itemOrderIDs := func() (ids []int) {
+		var items []item
+		s.Nil(s.db.Order("id").Find(&items).Error)
+		for _, i := range items {
+			ids = append(ids, i.OrderID)
+		}
+		return
+	}
+
+	var walked []int
+	err := newPaginator().EachPageInTx(context.Background(), gormTxBeginner{s.db}, TxOptions{}, query, func(tx Tx, page interface{}) error {
+		walked = append(walked, orderIDs(*page.(*[]order))...)
+		return addItem(tx, page)
+	})
+	s.Nil(err)
+	s.Equal([]int{1, 2, 3, 4, 5}, walked)
+	s.Equal([]int{1, 3, 5}, itemOrderIDs())
+	s.Nil(s.db.Where("1 = 1").Delete(&item{}).Error)
+
+	// work of the failed page is rolled back to its savepoint, savepoints are
+	// recorded since they are not supported by the test database
+	errFn := errors.New("fn")
+	failAt3 := func(tx Tx, page interface{}) error {
+		if orderIDs(*page.(*[]order))[0] == 3 {
+			return errFn
+		}
+		return nil
+	}
+	rec := &savepointTxBeginner{TxBeginner: gormTxBeginner{s.db}}
+	p := newPaginator()
+	err = p.EachPageInTx(context.Background(), rec, TxOptions{Savepoints: true}, func(tx Tx) Query {
+		return query(tx.(*savepointTx).Tx)
+	}, failAt3)
+	s.Equal(errFn, err)
+	s.Equal([]string{
+		"SAVEPOINT paginator_page_1",
+		"RELEASE SAVEPOINT paginator_page_1",
+		"SAVEPOINT paginator_page_2",
+		"ROLLBACK TO SAVEPOINT paginator_page_2",
+	}, rec.statements)
+	s.True(rec.committed)
+
+	// the whole walk is rolled back without savepoints
+	p = newPaginator()
+	err = p.EachPageInTx(context.Background(), gormTxBeginner{s.db}, TxOptions{}, query, func(tx Tx, page interface{}) error {
+		if err := addItem(tx, page); err != nil {
+			return err
+		}
+		return failAt3(tx, page)
+	})
+	s.Equal(errFn, err)
+	s.Empty(itemOrderIDs())
+}
+
+func (s *paginatorSuite) TestEachPageInTxIsolation() {
+	for _, c := range []struct {
+		dialect   Dialect
+		opts      TxOptions
+		isolation sql.IsolationLevel
+	}{
+		{"", TxOptions{}, sql.LevelRepeatableRead},
+		{Postgres, TxOptions{}, sql.LevelRepeatableRead},
+		{Oracle, TxOptions{}, sql.LevelSerializable},
+		{Oracle, TxOptions{Isolation: sql.LevelReadCommitted}, sql.LevelReadCommitted},
+	} {
+		db := &isolationTxBeginner{}
+		p := New()
+		p.SetDialect(c.dialect)
+		err := p.EachPageInTx(context.Background(), db, c.opts, nil, nil)
+		s.Equal(errTxBegin, err)
+		s.Equal(c.isolation, db.isolation, c.dialect)
+	}
+}
+
+func (s *paginatorSuite) TestEachPageMaxTotalRows() {
+	s.givenOrders(5)
+	walk := func(p *Paginator) (ids []int) {
+		s.Nil(p.EachPage(func() Query {
+			var orders []order
+			return newGormQuery(s.db, &orders)
+		}, func(page interface{}) error {
+			for _, o := range *page.(*[]order) {
+				ids = append(ids, o.ID)
+			}
+			return nil
+		}))
+		return
+	}
+	p := New()
+	p.SetLimit(2)
+	p.SetMaxTotalRows(3)
+	s.Equal([]int{5, 4, 3}, walk(p))
+	s.NotNil(p.GetNextCursor().After)
+
+	// the next run resumes from the rest of rows
+	s.Equal([]int{2, 1}, walk(p))
+	s.Nil(p.GetNextCursor().After)
+}
+
+func (s *paginatorSuite) TestIsTransientError() {
+	s.True(IsTransientError(driver.ErrBadConn))
+	s.True(IsTransientError(fmt.Errorf("query: %w", io.ErrUnexpectedEOF)))
+	s.True(IsTransientError(errors.New("Error 1213: Deadlock found when trying to get lock")))
+	s.True(IsTransientError(errors.New("read tcp: connection reset by peer")))
+	s.False(IsTransientError(errors.New("Error 1146: Table 'orders' doesn't exist")))
+	s.False(IsTransientError(nil))
+}
+
+func (s *paginatorSuite) TestPaginateCache() {
+	s.givenOrders(5)
+
+	cache := &memoryCache{values: map[string][]byte{}}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetLimit(2)
+		p.SetCache(cache, time.Minute)
+		return p
+	}
+
+	var o1 []order
+	c1 := s.paginateBy(newPaginator(), s.db, &o1)
+	s.Len(cache.values, 1)
+	s.Equal(time.Minute, cache.ttl)
+
+	// record query is never executed, so the page is from cache
+	var o2 []order
+	p := newPaginator()
+	_, err := p.Paginate(newRecordQuery(&o2, "orders"))
+	s.Nil(err)
+	c2 := p.GetNextCursor()
+	s.Equal(c1, c2)
+	s.Equal([]int{5, 4}, []int{o2[0].ID, o2[1].ID})
+
+	// next page is not cached yet
+	var o3 []order
+	p = newPaginator()
+	p.SetAfterCursor(*c2.After)
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]int{3, 2}, []int{o3[0].ID, o3[1].ID})
+	s.Len(cache.values, 2)
+}
+
+func (s *paginatorSuite) TestPaginateCacheKeyShouldCoverOptions() {
+	p := New()
+	key := p.getCacheKey(newRecordQuery(&[]order{}, "orders"))
+	for name, configure := range map[string]func(p *Paginator){
+		"inclusive":     func(p *Paginator) { p.SetInclusive(true) },
+		"skip flip":     func(p *Paginator) { p.SetSkipFlip(true) },
+		"look ahead":    func(p *Paginator) { p.SetLookAhead(LookAheadNone) },
+		"distinct":      func(p *Paginator) { p.SetDistinct(true) },
+		"distinct on":   func(p *Paginator) { p.SetDistinctOn("CreatedAt") },
+		"as of":         func(p *Paginator) { p.SetAsOfSystemTime(5 * time.Second) },
+		"snapshot time": func(p *Paginator) { p.SetSnapshotTime(time.Now()) },
+	} {
+		p := New()
+		configure(p)
+		s.NotEqual(key, p.getCacheKey(newRecordQuery(&[]order{}, "orders")), name)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateCacheShouldMissByInclusive() {
+	s.givenOrders(3)
+
+	cache := &memoryCache{values: map[string][]byte{}}
+	cursor := NewCursorEncoder("ID").Encode(order{ID: 3})
+	newPaginator := func(inclusive bool) *Paginator {
+		p := New()
+		p.SetLimit(2)
+		p.SetCache(cache, time.Minute)
+		p.SetInclusive(inclusive)
+		p.SetAfterCursor(cursor)
+		return p
+	}
+	var o1 []order
+	s.paginateBy(newPaginator(false), s.db, &o1)
+	s.Equal([]int{2, 1}, orderIDs(o1))
+
+	var o2 []order
+	s.paginateBy(newPaginator(true), s.db, &o2)
+	s.Equal([]int{3, 2}, orderIDs(o2))
+	s.Len(cache.values, 2)
+}
+
+func (s *paginatorSuite) TestPaginateCacheShouldSkipBuildSQL() {
+	s.givenOrders(3)
+
+	cache := &memoryCache{values: map[string][]byte{}}
+	p := New()
+	p.SetLimit(2)
+	p.SetCache(cache, time.Minute)
+	_, _, orderBy, _ := p.BuildSQL(&order{})
+	s.Equal("orders.id DESC", orderBy)
+	s.Empty(cache.values)
+
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Equal([]int{3, 2}, orderIDs(o1))
+	s.NotNil(cursor.After)
+}
+
+func (s *paginatorSuite) TestPaginateCacheErrorShouldBeMiss() {
+	s.givenOrders(3)
+
+	logger := &recordLogger{}
+	p := New()
+	p.SetLogger(logger)
+	p.SetCache(&memoryCache{err: errors.New("cache down")}, 0)
+	var orders []order
+	s.paginateBy(p, s.db, &orders)
+	s.Len(orders, 3)
+	s.Equal([]string{"cache: cache down", "cache: cache down"}, logger.entries[0].Warnings)
+}
+
+func (s *paginatorSuite) TestPaginateETag() {
+	s.givenOrders(3)
+
+	etag := func(versionKey string) string {
+		p := New()
+		p.SetLimit(2)
+		p.SetETagVersionKey(versionKey)
+		var orders []order
+		s.paginateBy(p, s.db, &orders)
+		return p.GetETag()
+	}
+	e1 := etag("Name")
+	s.True(strings.HasPrefix(e1, `W/"`))
+	s.Equal(e1, etag("Name"))
+	s.True(MatchETag(e1, e1))
+	s.True(MatchETag(`"other", `+strings.TrimPrefix(e1, "W/"), e1))
+	s.True(MatchETag("*", e1))
+	s.False(MatchETag(`"other"`, e1))
+
+	withoutVersion := etag("")
+	s.db.Exec("UPDATE orders SET name = ? WHERE id = ?", "renamed", 3)
+	s.NotEqual(e1, etag("Name"))
+	s.Equal(withoutVersion, etag(""))
+}
+
+func (s *paginatorSuite) TestPaginateETagInvalidVersionKey() {
+	p := New()
+	p.SetETagVersionKey("Unknown")
+	var orders []order
+	_, err := p.Paginate(newGormQuery(s.db, &orders))
+	s.Equal(&InvalidKeyError{Key: "Unknown", Model: "order"}, err)
+}
+
+func (s *paginatorSuite) TestBuildSQL() {
+	p := New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetLimit(5)
+	where, args, orderBy, limit := p.BuildSQL(&order{})
+	s.Equal("", where)
+	s.Nil(args)
+	s.Equal("orders.created_at DESC, orders.id DESC", orderBy)
+	s.Equal(6, limit)
+
+	createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := NewCursorEncoder("CreatedAt", "ID").Encode(reflect.ValueOf(order{ID: 3, CreatedAt: createdAt}))
+	p = New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetBeforeCursor(cursor)
+	p.SetOrder(ASC)
+	where, args, orderBy, limit = p.BuildSQL(&order{})
+	s.Equal("orders.created_at < ? OR orders.created_at = ? AND orders.id < ?", where)
+	s.Equal([]interface{}{createdAt, createdAt, 3}, args)
+	s.Equal("orders.created_at DESC, orders.id DESC", orderBy)
+	s.Equal(11, limit)
+}
+
+func (s *paginatorSuite) TestBuildSQLShouldSkipQueryOptions() {
+	cursor := NewCursorEncoder("ID").Encode(order{ID: 3})
+	for name, configure := range map[string]func(p *Paginator){
+		"total count":   func(p *Paginator) { p.SetTotalCount(true) },
+		"index advisor": func(p *Paginator) { p.SetIndexAdvisor(true) },
+		"explain":       func(p *Paginator) { p.SetExplain(true) },
+		"route": func(p *Paginator) {
+			p.SetRoute(func(query Query, kind QueryKind) (Query, error) {
+				return nil, errors.New("route")
+			})
+		},
+		"query timeout": func(p *Paginator) { p.SetQueryTimeout(time.Second) },
+	} {
+		p := New()
+		p.SetAfterCursor(cursor)
+		configure(p)
+		where, args, orderBy, limit := p.BuildSQL(&order{})
+		s.Equal("orders.id < ?", where, name)
+		s.Equal([]interface{}{3}, args, name)
+		s.Equal("orders.id DESC", orderBy, name)
+		s.Equal(11, limit, name)
+
+		where, _, err := p.BuildCursorWhere(&order{}, []interface{}{3})
+		s.Nil(err, name)
+		s.Equal("orders.id < ?", where, name)
+
+		orderBy, err = p.BuildOrderBy(&order{})
+		s.Nil(err, name)
+		s.Equal("orders.id DESC", orderBy, name)
+	}
+}
+
+func (s *paginatorSuite) TestBuildSQLForCompositePrimaryKey() {
+	type translation struct {
+		Language string `gorm:"primaryKey"`
+		ID       int    `gorm:"primaryKey"`
+		Title    string
+	}
+	cursor := NewCursorEncoder("Language", "ID").Encode(translation{Language: "en", ID: 3})
+	p := New()
+	p.SetAfterCursor(cursor)
+	where, args, orderBy, _ := p.BuildSQL(&translation{})
+	s.Equal("translations.language < ? OR translations.language = ? AND translations.id < ?", where)
+	s.Equal([]interface{}{"en", "en", 3}, args)
+	s.Equal("translations.language DESC, translations.id DESC", orderBy)
+
+	// tie-breaker appends primary keys missing from keys in schema order
+	p = New()
+	p.SetKeys("Title")
+	p.SetTieBreaker(true)
+	_, _, orderBy, _ = p.BuildSQL(&translation{})
+	s.Equal("translations.title DESC, translations.language DESC, translations.id DESC", orderBy)
+}
+
+func (s *paginatorSuite) TestBuildCursorWhere() {
+	var orders = s.givenOrders(5)
+	fields := []interface{}{orders[1].ID}
+
+	p := New()
+	p.SetOrder(ASC)
+	where, args, err := p.BuildCursorWhere(&order{}, fields)
+	s.Nil(err)
+	s.Equal("orders.id > ?", where)
+	s.Equal(fields, args)
+
+	var out []order
+	s.Nil(s.db.Where(where, args...).Order("id").Find(&out).Error)
+	s.assertOrders(orders, 2, 4, out)
+
+	p.SetBeforeCursor("")
+	where, _, err = p.BuildCursorWhere(&order{}, fields)
+	s.Nil(err)
+	s.Equal("orders.id < ?", where)
+
+	_, _, err = p.BuildCursorWhere(&order{}, []interface{}{1, 2})
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateRuleOperators() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("a")},
+		{Name: pqString("b")},
+		{Name: pqString("b")},
+		{Name: pqString("c")},
+	})
+
+	binary := Operators{Less: "< BINARY", Greater: "> BINARY", Equal: "= BINARY"}
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{Key: "Name", Operators: binary}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		p.SetLimit(2)
+		return p
+	}
+	where, _, err := newPaginator().BuildCursorWhere(&order{}, []interface{}{"b", 2})
+	s.Nil(err)
+	s.Equal("orders.name > BINARY ? OR orders.name = BINARY ? AND orders.id > ?", where)
+
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+	s.Equal([]int{1, 2}, orderIDs(o1))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{3, 4}, orderIDs(o2))
+}
+
+func (s *paginatorSuite) TestPaginateRuleDirection() {
+	s.givenOrders(5)
+
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{Key: "ID", Direction: negatedDirection{}})
+		p.SetLimit(2)
+		return p
+	}
+	orderBy, err := newPaginator().BuildOrderBy(&order{})
+	s.Nil(err)
+	s.Equal("-orders.id ASC", orderBy)
+
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+	s.Equal([]int{5, 4}, orderIDs(o1))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{3, 2}, orderIDs(o2))
+	s.Equal("orders.id < ?", p.resolved().where)
+	s.Equal("-orders.id ASC", p.resolved().orderBy)
+
+	var o3 []order
+	p = newPaginator()
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, s.db, &o3)
+	s.Equal([]int{5, 4}, orderIDs(o3))
+	s.Equal("orders.id > ?", p.resolved().where)
+	s.Equal("-orders.id DESC", p.resolved().orderBy)
+}
+
+func (s *paginatorSuite) TestPaginateRuleCollation() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("a")},
+		{Name: pqString("B")},
+		{Name: pqString("c")},
+		{Name: pqString("D")},
+	})
+
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetRules(Rule{Key: "Name", Collation: "utf8mb4_0900_ai_ci"}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		p.SetLimit(2)
+		return p
+	}
+	where, _, err := newPaginator().BuildCursorWhere(&order{}, []interface{}{"B", 2})
+	s.Nil(err)
+	s.Equal("orders.name COLLATE utf8mb4_0900_ai_ci > ? OR orders.name COLLATE utf8mb4_0900_ai_ci = ? AND orders.id > ?", where)
+
+	// by binary ordering B and D would come before a
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+	s.Equal([]int{1, 2}, orderIDs(o1))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{3, 4}, orderIDs(o2))
+
+	p = New()
+	p.SetDialect(Postgres)
+	p.SetRules(Rule{Key: "Name", Collation: "C", ArgType: "citext"}, Rule{Key: "ID"})
+	p.SetPredicate(PredicateTuple)
+	where, _, err = p.BuildCursorWhere(&order{}, []interface{}{"B", 2})
+	s.Nil(err)
+	s.Equal(`("orders"."name" COLLATE "C", "orders"."id") < (CAST(? AS citext), ?)`, where)
+}
+
+func (s *paginatorSuite) TestBuildOrderBy() {
+	p := New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetOrder(ASC)
+	orderBy, err := p.BuildOrderBy(&order{})
+	s.Nil(err)
+	s.Equal("orders.created_at ASC, orders.id ASC", orderBy)
+
+	p.SetBeforeCursor("")
+	orderBy, err = p.BuildOrderBy(&order{})
+	s.Nil(err)
+	s.Equal("orders.created_at DESC, orders.id DESC", orderBy)
+
+	p.SetKeys("Unknown")
+	_, err = p.BuildOrderBy(&order{})
+	s.NotNil(err)
+}
+
+func (s *paginatorSuite) TestPaginateIndexAdvisor() {
+	s.givenOrders(1)
+
+	logger := &recordLogger{}
+	p := New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetIndexAdvisor(true)
+	p.SetLogger(logger)
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.Equal([]string{
+		"index advisor: no index of orders leads with paging keys (created_at, id)",
+	}, logger.entries[0].Warnings)
+
+	// table without index is checked again
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.Equal(logger.entries[0].Warnings, logger.entries[1].Warnings)
+
+	p = New()
+	p.SetKeys("ID")
+	p.SetIndexAdvisor(true)
+	p.SetLogger(logger)
+	var o3 []order
+	s.paginateBy(p, s.db, &o3)
+	s.Empty(logger.entries[2].Warnings)
+}
+
+func (s *paginatorSuite) TestPaginateIndexAdvisorShouldCheckUntilIndexIsFound() {
+	calls := 0
+	newQuery := func(indexes []Index, err error) Query {
+		return &indexRecordQuery{recordQuery: newRecordQuery(&[]order{}, "orders"), indexes: indexes, err: err, calls: &calls}
+	}
+	logger := &recordLogger{}
+	p := New()
+	p.SetIndexAdvisor(true)
+	p.SetLogger(logger)
+
+	_, err := p.Paginate(newQuery(nil, errors.New("timeout")))
+	s.Nil(err)
+	s.Equal([]string{"index advisor: timeout"}, logger.entries[0].Warnings)
+
+	// failed check is not recorded
+	primary := []Index{{Name: "PRIMARY", Columns: []string{"id"}}}
+	for i := 1; i <= 2; i++ {
+		_, err = p.Paginate(newQuery(primary, nil))
+		s.Nil(err)
+		s.Empty(logger.entries[i].Warnings)
+	}
+	s.Equal(2, calls)
+
+	// checks are recorded by paginator
+	p = New()
+	p.SetIndexAdvisor(true)
+	_, err = p.Paginate(newQuery(primary, nil))
+	s.Nil(err)
+	s.Equal(3, calls)
+}
+
+func (s *paginatorSuite) TestPaginateIndexAdvisorShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetIndexAdvisor(true)
+	var out []order
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrIndexAdvisorNotSupported, err)
+}
+
+func (s *paginatorSuite) TestCheck() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("a")},
+		{Name: pqString("a")},
+		{},
+	})
+	db, err := s.db.DB()
+	s.Nil(err)
+	p := New()
+	p.SetDialect(MySQL)
+
+	report, err := p.Check(db, &order{})
+	s.Nil(err)
+	s.True(report.OK(), report.Problems)
+	s.Equal("orders", report.Table)
+	s.True(report.IndexChecked)
+	s.True(report.Indexed)
+	s.Equal(3, report.SampledRows)
+	s.Equal([]KeyCheck{{Key: "ID", Column: "id", Exists: true, Rows: 3}}, report.Keys)
+
+	report, err = p.Check(db, &order{}, "Name")
+	s.Nil(err)
+	s.Equal(1, report.Duplicates)
+	s.Equal(int64(1), report.Keys[0].Nulls)
+	s.Equal([]string{
+		"no index of orders leads with paging keys (name)",
+		"paging keys (name) are not unique, 1 of 3 sampled rows are duplicates",
+		"key Name has 1 NULL values of 3 rows, which are skipped unless Nulls of the key is set",
+	}, report.Problems)
+
+	// other diagnostics are skipped when columns do not exist
+	type missingOrder struct {
+		ID      int
+		Missing string
+	}
+	report, err = p.Check(db, &missingOrder{}, "Missing", "ID")
+	s.Nil(err)
+	s.False(report.OK())
+	s.Equal("missing_orders", report.Table)
+	s.False(report.Keys[0].Exists)
+	s.False(report.IndexChecked)
+
+	_, err = p.Check(db, &order{}, "Unknown")
+	s.True(errors.Is(err, ErrInvalidKey))
+}
+
+func (s *paginatorSuite) TestPaginateExplain() {
+	s.givenOrders(3)
+
+	logger := &recordLogger{}
+	p := New()
+	p.SetExplain(true)
+	p.SetLogger(logger)
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.Len(o1, 3)
+	s.NotEmpty(logger.entries[0].Plan)
+	s.Empty(logger.entries[0].Warnings)
+}
+
+func (s *paginatorSuite) TestPaginateExplainShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetExplain(true)
+	var out []order
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrExplainNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateLookAhead() {
+	var orders = s.givenOrders(4)
+
+	for _, lookAhead := range []LookAhead{LookAheadRow, LookAheadExists, LookAheadNone} {
+		logger := &recordLogger{}
+		p := New()
+		p.SetLimit(2)
+		p.SetLookAhead(lookAhead)
+		p.SetLogger(logger)
+		var o1 []order
+		c := s.paginateBy(p, s.db, &o1)
+		s.Len(o1, 2)
+		s.assertOrders(orders, 3, 2, o1)
+		s.NotNil(c.After)
+		if lookAhead == LookAheadRow {
+			s.Equal(3, logger.entries[0].Limit)
+		} else {
+			s.Equal(2, logger.entries[0].Limit)
+		}
+
+		var o2 []order
+		p = New()
+		p.SetLimit(2)
+		p.SetLookAhead(lookAhead)
+		p.SetAfterCursor(*c.After)
+		c = s.paginateBy(p, s.db, &o2)
+		s.Len(o2, 2)
+		s.assertOrders(orders, 1, 0, o2)
+		// page is full without knowing whether there are more rows
+		s.Equal(lookAhead == LookAheadNone, c.After != nil)
+	}
+}
+
+func (s *paginatorSuite) TestPaginateUnlimited() {
+	var orders = s.givenOrders(15)
+
+	for _, lookAhead := range []LookAhead{LookAheadRow, LookAheadExists, LookAheadNone} {
+		p := New()
+		p.SetLimit(Unlimited)
+		p.SetLookAhead(lookAhead)
+		p.SetAfterCursor(NewCursorEncoder("ID").Encode(orders[13]))
+		var o []order
+		c := s.paginateBy(p, s.db, &o)
+		s.Len(o, 13)
+		s.assertOrders(orders, 12, 0, o)
+		s.assertOnlyBefore(c)
+	}
+
+	p := New()
+	p.SetLimit(Unlimited)
+	_, _, _, limit := p.BuildSQL(&order{})
+	s.Equal(Unlimited, limit)
+	statement, _ := p.BuildStatement(&order{})
+	s.Equal("SELECT * FROM orders ORDER BY orders.id DESC", statement)
+}
+
+func (s *paginatorSuite) TestPaginateLookAheadShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetLimit(1)
+	p.SetLookAhead(LookAheadExists)
+	// out is left as given since recordQuery never executes
+	out := []order{{ID: 1}}
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrExistsNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateDistinct() {
+	s.givenCustomOrders([]order{
+		{Name: pqString("a")},
+		{Name: pqString("b")},
+		{Name: pqString("a")},
+		{Name: pqString("c")},
+		{Name: pqString("b")},
+	})
+	stmt := s.db.Select("name")
+
+	var o1 []order
+	p := New()
+	p.SetKeys("Name")
+	p.SetOrder(ASC)
+	p.SetLimit(2)
+	p.SetDistinct(true)
+	c := s.paginateBy(p, stmt, &o1)
+	s.Equal([]string{"a", "b"}, orderNames(o1))
+	s.NotNil(c.After)
+
+	var o2 []order
+	p = New()
+	p.SetKeys("Name")
+	p.SetOrder(ASC)
+	p.SetLimit(2)
+	p.SetDistinct(true)
+	p.SetAfterCursor(*c.After)
+	c = s.paginateBy(p, stmt, &o2)
+	s.Equal([]string{"c"}, orderNames(o2))
+	s.Nil(c.After)
+}
+
+func (s *paginatorSuite) TestPaginateDistinctShouldReturnErrorWhenKeyIsNotSelected() {
+	p := New()
+	p.SetKeys("Name", "ID")
+	p.SetDistinct(true)
+	var out []order
+	_, err := p.Paginate(newGormQuery(s.db.Select("name"), &out))
+	s.True(errors.Is(err, ErrDistinctKey))
+
+	_, err = p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrDistinctNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateURLSafeCursor() {
+	var orders = s.givenCustomOrders([]order{
+		{Name: pqString("??>>??>>")},
+		{Name: pqString("??>>??>>")},
+		{Name: pqString("??>>??>>")},
+	})
+
+	var o1 []order
+	p := New()
+	p.SetKeys("Name", "ID")
+	p.SetLimit(1)
+	p.SetURLSafeCursor(true)
+	c := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 2, 2, o1)
+	s.NotContains(*c.After, "+")
+	s.NotContains(*c.After, "/")
+	s.NotContains(*c.After, "=")
+
+	var o2 []order
+	p = New()
+	p.SetKeys("Name", "ID")
+	p.SetLimit(1)
+	p.SetAfterCursor(*c.After)
+	c = s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 1, 1, o2)
+
+	// standard encoding is still accepted
+	var o3 []order
+	p = New()
+	p.SetKeys("Name", "ID")
+	p.SetLimit(1)
+	p.SetURLSafeCursor(true)
+	p.SetAfterCursor(*c.After)
+	s.paginateBy(p, s.db, &o3)
+	s.assertOrders(orders, 0, 0, o3)
+}
+
+func (s *paginatorSuite) TestPaginateCursorCompression() {
+	name := strings.Repeat("name", 7)
+	var orders = s.givenCustomOrders([]order{
+		{Name: pqString(name)},
+		{Name: pqString(name)},
+	})
+
+	var o1 []order
+	p := New()
+	p.SetKeys("Name", "ID")
+	p.SetLimit(1)
+	p.SetCursorCompression(true)
+	c := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 1, 1, o1)
+	s.Contains(p.DumpCursor(*c.After), name)
+	s.Less(len(*c.After), len(NewCursorEncoder("Name", "ID").Encode(o1[0])))
+
+	var o2 []order
+	p = New()
+	p.SetKeys("Name", "ID")
+	p.SetLimit(1)
+	p.SetAfterCursor(*c.After)
+	s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 0, 0, o2)
+}
+
+func (s *paginatorSuite) TestPaginateLargeIDs() {
+	// neighbors beyond 2^53 collapse if they pass through float64
+	var orders = s.givenCustomOrders([]order{
+		{ID: 1<<53 + 1},
+		{ID: 1<<53 + 2},
+		{ID: 1<<53 + 3},
+	})
+
+	var o1 []order
+	p := New()
+	p.SetOrder(ASC)
+	p.SetLimit(1)
+	c := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 0, 0, o1)
+
+	var o2 []order
+	p = New()
+	p.SetOrder(ASC)
+	p.SetLimit(1)
+	p.SetAfterCursor(*c.After)
+	c = s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 1, 1, o2)
+
+	var o3 []map[string]interface{}
+	p = New()
+	p.SetOrder(ASC)
+	p.SetAfterCursor(*c.After)
+	s.paginateBy(p, s.db.Table("orders"), &o3)
+	s.Equal([]int{orders[2].ID}, mapIDs(o3))
+}
+
+func (s *paginatorSuite) TestPaginateUnionAll() {
+	var orders = s.givenOrders(6)
+	head := s.db.Where("id <= ?", orders[1].ID)
+	tail := newGormQuery(s.db.Model(&order{}).Where("id > ?", orders[3].ID), nil)
+
+	var o1 []order
+	p := New()
+	p.SetLimit(3)
+	p.SetUnionAll(tail)
+	c := s.paginateBy(p, head, &o1)
+	s.Len(o1, 3)
+	s.assertOrders(orders, 5, 1, o1)
+
+	var o2 []order
+	p = New()
+	p.SetLimit(3)
+	p.SetUnionAll(tail)
+	p.SetAfterCursor(*c.After)
+	c = s.paginateBy(p, head, &o2)
+	s.Len(o2, 1)
+	s.assertOrders(orders, 0, 0, o2)
+	s.Nil(c.After)
+
+	where, _, orderBy, _ := p.BuildSQL(&order{})
+	s.Equal("paginator_union.id < ?", where)
+	s.Equal("paginator_union.id DESC", orderBy)
+}
+
+func (s *paginatorSuite) TestPaginateUnionAllShouldReturnErrorWhenQueryIsNotSupported() {
+	p := New()
+	p.SetUnionAll(newRecordQuery(nil, "orders"))
+	var out []order
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrUnionNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateShards() {
+	var orders = s.givenOrders(7)
+	newShards := func() ([]Query, *[]order, *[]order) {
+		var even, odd []order
+		return []Query{
+			newGormQuery(s.db.Where("id % 2 = 0"), &even),
+			newGormQuery(s.db.Where("id % 2 = 1"), &odd),
+		}, &even, &odd
+	}
+
+	var pages [][]order
+	var cursor *string
+	for i := 0; i < 4; i++ {
+		p := New()
+		p.SetLimit(3)
+		if cursor != nil {
+			p.SetAfterCursor(*cursor)
+		}
+		shards, _, _ := newShards()
+		var out []order
+		s.Nil(p.PaginateShards(&out, shards...))
+		pages = append(pages, out)
+		if cursor = p.GetNextCursor().After; cursor == nil {
+			break
+		}
+	}
+	s.Len(pages, 3)
+	s.assertOrders(orders, 6, 4, pages[0])
+	s.assertOrders(orders, 3, 1, pages[1])
+	s.assertOrders(orders, 0, 0, pages[2])
+	s.Len(pages[1], 3)
+}
+
+func (s *paginatorSuite) TestCompareShardRowsWithNaN() {
+	p := New()
+	p.SetKeys("Score")
+	p.SetOrder(ASC)
+	scores := []float64{1, math.NaN(), math.Inf(-1), math.Inf(1), math.NaN(), 0}
+	sort.SliceStable(scores, func(i, j int) bool {
+		c, err := p.compareShardRows([]interface{}{scores[i]}, []interface{}{scores[j]})
+		s.Nil(err)
+		return c < 0
+	})
+	s.Equal([]float64{math.Inf(-1), 0, 1, math.Inf(1)}, scores[:4])
+	s.True(math.IsNaN(scores[4]) && math.IsNaN(scores[5]))
+
+	_, err := p.compareShardRows([]interface{}{math.NaN()}, []interface{}{"a"})
+	s.True(errors.Is(err, ErrShardKeyType))
+}
+
+func (s *paginatorSuite) TestPaginateShardsShouldReturnErrorWhenCursorIsInvalid() {
+	shard := func() Query {
+		var out []order
+		return newGormQuery(s.db, &out)
+	}
+	var out []order
+	p := New()
+	p.SetBeforeCursor("")
+	s.Equal(ErrShardBeforeCursor, p.PaginateShards(&out, shard()))
+
+	p = New()
+	p.SetAfterCursor(base64.StdEncoding.EncodeToString([]byte(`[null]`)))
+	err := p.PaginateShards(&out, shard(), shard())
+	s.True(errors.Is(err, ErrInvalidCursor))
+}
+
+func (s *paginatorSuite) TestPaginateSequence() {
+	s.givenOrders(6)
+	// pinned orders come first
+	newQueries := func() []Query {
+		var pinned, rest []order
+		return []Query{
+			newGormQuery(s.db.Where("id IN ?", []int{2, 4}), &pinned),
+			newGormQuery(s.db.Where("id NOT IN ?", []int{2, 4}), &rest),
+		}
+	}
+	paginate := func(limit int, cursor Cursor) ([]int, Cursor) {
+		p := New()
+		p.SetLimit(limit)
+		if cursor.After != nil {
+			p.SetAfterCursor(*cursor.After)
+		}
+		if cursor.Before != nil {
+			p.SetBeforeCursor(*cursor.Before)
+		}
+		var out []order
+		s.Nil(p.PaginateSequence(&out, newQueries()...))
+		return orderIDs(out), p.GetNextCursor()
+	}
+
+	ids, c1 := paginate(3, Cursor{})
+	s.Equal([]int{4, 2, 6}, ids)
+	s.assertOnlyAfter(c1)
+	ids, c2 := paginate(3, Cursor{After: c1.After})
+	s.Equal([]int{5, 3, 1}, ids)
+	s.assertOnlyBefore(c2)
+	ids, c3 := paginate(3, Cursor{Before: c2.Before})
+	s.Equal([]int{4, 2, 6}, ids)
+	s.assertOnlyAfter(c3)
+
+	// page ending at the last pinned order
+	ids, c1 = paginate(2, Cursor{})
+	s.Equal([]int{4, 2}, ids)
+	s.assertOnlyAfter(c1)
+	ids, c2 = paginate(2, Cursor{After: c1.After})
+	s.Equal([]int{6, 5}, ids)
+	s.assertBoth(c2)
+	ids, c3 = paginate(2, Cursor{Before: c2.Before})
+	s.Equal([]int{4, 2}, ids)
+	s.assertOnlyAfter(c3)
+
+	// last page of the sequence
+	ids, c1 = paginate(4, Cursor{Before: new(string)})
+	s.Equal([]int{6, 5, 3, 1}, ids)
+	s.assertBoth(c1)
+	ids, _ = paginate(4, Cursor{Before: c1.Before})
+	s.Equal([]int{4, 2}, ids)
+
+	// cursor of the rest is not valid without it
+	var out []order
+	p := New()
+	p.SetAfterCursor(*c1.Before)
+	s.True(errors.Is(p.PaginateSequence(&out, newQueries()[:1]...), ErrInvalidCursor))
+
+	// configuration is left as it is
+	p = New()
+	s.Nil(p.PaginateSequence(&out, newQueries()...))
+	s.Equal([]int{4, 2, 6, 5, 3, 1}, orderIDs(out))
+	s.Zero(p.limit)
+	s.Empty(p.order)
+}
+
+func (s *paginatorSuite) TestPaginateRoute() {
+	var orders = s.givenOrders(5)
+	replica, err := gorm.Open(mysql.Open("test:test@(localhost:3306)/test?parseTime=True"), &gorm.Config{})
+	s.Nil(err)
+	defer func() {
+		if conn, err := replica.DB(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	var mu sync.Mutex
+	var kinds []QueryKind
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetLimit(2)
+		p.SetTotalCount(true)
+		p.SetLookAhead(LookAheadExists)
+		p.SetRoute(func(query Query, kind QueryKind) (Query, error) {
+			mu.Lock()
+			kinds = append(kinds, kind)
+			mu.Unlock()
+			q := query.(*gormQuery)
+			// context clones statement of the query
+			db := q.db.Session(&gorm.Session{WithConditions: true, Context: context.Background()})
+			db.Statement.ConnPool = replica.ConnPool
+			return &gormQuery{db: db, out: q.out}, nil
+		})
+		return p
+	}
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), s.db, &o1)
+	s.assertOrders(orders, 4, 3, o1)
+	s.ElementsMatch([]QueryKind{QueryCount, QueryPage, QueryExists}, kinds)
+
+	// cursor is unaffected by routing
+	var o2 []order
+	p := New()
+	p.SetLimit(2)
+	s.Equal(cursor, s.paginateBy(p, s.db, &o2))
+
+	p = New()
+	p.SetRoute(func(query Query, kind QueryKind) (Query, error) {
+		return query, io.ErrUnexpectedEOF
+	})
+	_, err = p.Paginate(newGormQuery(s.db, &o2))
+	s.Equal(io.ErrUnexpectedEOF, err)
+}
+
+func (s *paginatorSuite) TestPaginateValues() {
+	var orders = s.givenOrders(5)
+	values := url.Values{"status": {"paid"}, "limit": {"2"}, "order": {"asc"}}
+
+	p := New()
+	s.Nil(p.FromValues(values))
+	var o1 []order
+	s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 0, 1, o1)
+	s.Nil(p.PrevValues(values))
+
+	next := p.NextValues(values)
+	s.Equal("paid", next.Get("status"))
+	s.Empty(values.Get("after"))
+
+	p = New()
+	s.Nil(p.FromValues(next))
+	s.Equal(next.Get("after"), p.ToValues().Get("after"))
+	s.Equal("ASC", p.ToValues().Get("order"))
+	var o2 []order
+	s.paginateBy(p, s.db, &o2)
+	s.assertOrders(orders, 2, 3, o2)
+
+	prev := p.PrevValues(next)
+	s.Empty(prev.Get("after"))
+	p = New()
+	s.Nil(p.FromValues(prev))
+	var o3 []order
+	s.paginateBy(p, s.db, &o3)
+	s.assertOrders(orders, 0, 1, o3)
+
+	s.True(errors.Is(New().FromValues(url.Values{"limit": {"0"}}), ErrInvalidLimit))
+	s.True(errors.Is(New().FromValues(url.Values{"order": {"up"}}), ErrInvalidOrder))
+}
+
+func (s *paginatorSuite) TestPaginateTotalCount() {
+	var orders = s.givenOrders(5)
+	stmt := s.db.Where("id > ?", orders[0].ID)
+
+	var o1 []order
+	p := New()
+	p.SetLimit(2)
+	p.SetTotalCount(true)
+	c := s.paginateBy(p, stmt, &o1)
+	s.assertOrders(orders, 4, 3, o1)
+	s.Equal(int64(4), *p.GetPageInfo().Total)
+
+	// total is counted regardless of cursor
+	var o2 []order
+	p = New()
+	p.SetLimit(2)
+	p.SetTotalCount(true)
+	p.SetAfterCursor(*c.After)
+	s.paginateBy(p, stmt, &o2)
+	s.assertOrders(orders, 2, 1, o2)
+	s.Equal(int64(4), *p.GetPageInfo().Total)
+
+	var out []order
+	p = New()
+	p.SetTotalCount(true)
+	_, err := p.Paginate(newRecordQuery(&out, "orders"))
+	s.Equal(ErrCountNotSupported, err)
+}
+
+func (s *paginatorSuite) TestPaginateState() {
+	var orders = s.givenOrders(5)
+
+	var o1 []order
+	p := New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetOrder(ASC)
+	p.SetLimit(2)
+	p.SetURLSafeCursor(true)
+	c := s.paginateBy(p, s.db, &o1)
+	s.assertOrders(orders, 0, 1, o1)
+
+	// checkpoint and resume from next cursor
+	p.SetAfterCursor(*c.After)
+	token := p.EncodeState()
+	resumed, err := NewFromState(token)
+	s.Nil(err)
+	var o2 []order
+	c = s.paginateBy(resumed, s.db, &o2)
+	s.assertOrders(orders, 2, 3, o2)
+	s.NotContains(*c.After, "=")
+
+	for _, token := range []string{"invalid", base64.RawURLEncoding.EncodeToString([]byte(`{"v":0}`))} {
+		_, err = NewFromState(token)
+		s.Equal(ErrInvalidState, err)
+	}
+}
+
+func (s *paginatorSuite) TestBuildStatement() {
+	cursor := NewCursorEncoder("ID").Encode(order{ID: 3})
+	for _, c := range []struct {
+		dialect   Dialect
+		statement string
+	}{
+		{"", "SELECT * FROM orders WHERE orders.id < ? ORDER BY orders.id DESC LIMIT 3"},
+		{MySQL, "SELECT * FROM `orders` WHERE `orders`.`id` < ? ORDER BY `orders`.`id` DESC LIMIT 3"},
+		{Postgres, `SELECT * FROM "orders" WHERE "orders"."id" < $1 ORDER BY "orders"."id" DESC LIMIT 3`},
+		{SQLServer, "SELECT * FROM [orders] WHERE [orders].[id] < @p1 ORDER BY [orders].[id] DESC OFFSET 0 ROWS FETCH NEXT 3 ROWS ONLY"},
+		{Oracle, `SELECT * FROM "ORDERS" WHERE "ORDERS"."ID" < :1 ORDER BY "ORDERS"."ID" DESC OFFSET 0 ROWS FETCH NEXT 3 ROWS ONLY`},
+	} {
+		p := New()
+		p.SetDialect(c.dialect)
+		p.SetLimit(2)
+		p.SetAfterCursor(cursor)
+		statement, args := p.BuildStatement(&order{})
+		s.Equal(c.statement, statement)
+		s.Equal([]interface{}{3}, args)
+	}
+}
+
+func (s *paginatorSuite) TestBuildOrderByForNullsRule() {
+	for _, c := range []struct {
+		dialect Dialect
+		orderBy string
+	}{
+		{"", "orders.published_at IS NULL ASC, orders.published_at ASC, orders.id ASC"},
+		{MySQL, "`orders`.`published_at` IS NULL ASC, `orders`.`published_at` ASC, `orders`.`id` ASC"},
+		{SQLServer, "CASE WHEN [orders].[published_at] IS NULL THEN 1 ELSE 0 END ASC, [orders].[published_at] ASC, [orders].[id] ASC"},
+		{Oracle, `"ORDERS"."PUBLISHED_AT" ASC NULLS LAST, "ORDERS"."ID" ASC`},
+	} {
+		p := New()
+		p.SetDialect(c.dialect)
+		p.SetRules(Rule{Key: "PublishedAt", Nulls: NullsLast}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		orderBy, err := p.BuildOrderBy(&order{})
+		s.Nil(err)
+		s.Equal(c.orderBy, orderBy, c.dialect)
+	}
+
+	// NULL values come first of flipped order of before cursor
+	p := New()
+	p.SetDialect(Oracle)
+	p.SetRules(Rule{Key: "PublishedAt", Nulls: NullsLast}, Rule{Key: "ID"})
+	p.SetBeforeCursor(NewCursorEncoder("PublishedAt", "ID").Encode(order{ID: 3}))
+	orderBy, err := p.BuildOrderBy(&order{})
+	s.Nil(err)
+	s.Equal(`"ORDERS"."PUBLISHED_AT" ASC NULLS FIRST, "ORDERS"."ID" ASC`, orderBy)
+}
+
+func (s *paginatorSuite) TestDialectQuoteOracle() {
+	s.Equal(`"ORDERS"."CREATED_AT"`, Oracle.column("orders", "created_at"))
+	s.Equal(`"App"."Orders"."CreatedAt"`, Oracle.column("App.Orders", "CreatedAt"))
+}
+
+type analyticsEvent struct {
+	ID int
+}
+
+func (analyticsEvent) TableName() string {
+	return "analytics.events"
+}
+
+func (s *paginatorSuite) TestBuildStatementForSchema() {
+	cursor := NewCursorEncoder("ID").Encode(order{ID: 3})
+	p := New()
+	p.SetDialect(Postgres)
+	p.SetAfterCursor(cursor)
+	statement, _ := p.BuildStatement(&analyticsEvent{})
+	s.Equal(`SELECT * FROM "analytics"."events" WHERE "analytics"."events"."id" < $1 ORDER BY "analytics"."events"."id" DESC LIMIT 11`, statement)
+
+	p = New()
+	p.SetDialect(Postgres)
+	p.SetSchema("sales")
+	p.SetAfterCursor(cursor)
+	statement, _ = p.BuildStatement(&order{})
+	s.Equal(`SELECT * FROM "sales"."orders" WHERE "sales"."orders"."id" < $1 ORDER BY "sales"."orders"."id" DESC LIMIT 11`, statement)
+
+	// tables qualified already are left as they are
+	statement, _ = p.BuildStatement(&analyticsEvent{})
+	s.Equal(`SELECT * FROM "analytics"."events" WHERE "analytics"."events"."id" < $1 ORDER BY "analytics"."events"."id" DESC LIMIT 11`, statement)
+}
+
+func (s *paginatorSuite) TestPaginateSchema() {
+	s.givenOrders(3)
+	p := New()
+	p.SetDialect(MySQL)
+	p.SetSchema("test")
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, s.db, &o1)
+	s.Equal([]int{3, 2}, orderIDs(o1))
+
+	var o2 []order
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, s.db, &o2)
+	s.Equal([]int{1}, orderIDs(o2))
+
+	// schema of table expression qualifies keys
+	var o3 []order
+	p = New()
+	p.SetDialect(MySQL)
+	p.SetLimit(2)
+	s.paginateBy(p, s.db.Table("test.orders"), &o3)
+	s.Equal([]int{3, 2}, orderIDs(o3))
+	s.Equal("`test`.`orders`.`id` DESC", p.resolved().orderBy)
+}
+
+func (s *paginatorSuite) TestPaginateAlias() {
+	s.givenOrders(4)
+	// each order is joined to its previous order
+	selfJoin := func() *gorm.DB {
+		return s.db.Table("orders o JOIN orders prev ON prev.id = o.id - 1")
+	}
+	p := New()
+	p.SetDialect(MySQL)
+	p.SetAlias("o")
+	p.SetLimit(2)
+	var o1 []order
+	cursor := s.paginateBy(p, selfJoin(), &o1)
+	s.Equal([]int{4, 3}, orderIDs(o1))
+	s.Equal("`o`.`id` DESC", p.resolved().orderBy)
+
+	var o2 []order
+	p.SetAfterCursor(*cursor.After)
+	s.paginateBy(p, selfJoin(), &o2)
+	s.Equal([]int{2}, orderIDs(o2))
+	s.Equal("`o`.`id` < ?", p.resolved().where)
+}
+
+func (s *paginatorSuite) TestPaginatePredicate() {
+	// ties on CreatedAt are broken by ID
+	createdAt := time.Now().Truncate(time.Second)
+	var orders = s.givenCustomOrders([]order{
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+		{CreatedAt: createdAt},
+	})
+	cursor := NewCursorEncoder("CreatedAt", "ID").Encode(orders[3])
+
+	for _, c := range []struct {
+		dialect   Dialect
+		predicate Predicate
+		where     string
+		args      int
+	}{
+		{"", "", "orders.created_at < ? OR orders.created_at = ? AND orders.id < ?", 3},
+		{Postgres, "", `("orders"."created_at", "orders"."id") < ($1, $2)`, 2},
+		{MySQL, "", "`orders`.`created_at` <= ? AND (`orders`.`created_at` < ? OR `orders`.`created_at` = ? AND `orders`.`id` < ?)", 4},
+		{Postgres, PredicateOR, `"orders"."created_at" < $1 OR "orders"."created_at" = $2 AND "orders"."id" < $3`, 3},
+		{"", PredicateTuple, "(orders.created_at, orders.id) < (?, ?)", 2},
+		{"", PredicateRange, "orders.created_at <= ? AND (orders.created_at < ? OR orders.created_at = ? AND orders.id < ?)", 4},
+	} {
+		p := New()
+		p.SetKeys("CreatedAt", "ID")
+		p.SetDialect(c.dialect)
+		p.SetPredicate(c.predicate)
+		p.SetAfterCursor(cursor)
+		where, args, _, _ := p.BuildSQL(&order{})
+		s.Equal(c.where, where)
+		s.Len(args, c.args)
+
+		// MySQL accepts all strategies
+		if c.dialect == Postgres {
+			continue
+		}
+		var out []order
+		p = New()
+		p.SetKeys("CreatedAt", "ID")
+		p.SetDialect(c.dialect)
+		p.SetPredicate(c.predicate)
+		p.SetAfterCursor(cursor)
+		s.paginateBy(p, s.db, &out)
+		s.assertOrders(orders, 2, 0, out)
+	}
+}
+
+func (s *paginatorSuite) TestValidate() {
+	s.NoError(pq{Keys: []string{"CreatedAt", "ID"}}.Paginator().Validate(order{}))
+	s.NoError(pq{TieBreaker: true}.Paginator().Validate(&[]*order{}))
+	s.Equal(
+		&InvalidKeyError{Key: "Unknown", Model: "order"},
+		pq{Keys: []string{"ID", "Unknown"}}.Paginator().Validate(order{}),
+	)
+	s.Equal(ErrInvalidModel, New().Validate(1))
+}
+
+func (s *paginatorSuite) TestPrimaryKeys() {
+	type embedded struct {
+		ID uint `gorm:"primaryKey"`
+	}
+	type embeddedModel struct {
+		embedded
+		Name string
+	}
+	type compositeModel struct {
+		ID       int    `gorm:"primary_key"`
+		Language string `gorm:"type:varchar(8);primaryKey"`
+	}
+	type untaggedModel struct {
+		ID int
+	}
+	s.Equal([]string{"ID"}, primaryKeys(reflect.TypeOf(embeddedModel{})))
+	s.Equal([]string{"ID", "Language"}, primaryKeys(reflect.TypeOf(compositeModel{})))
+	s.Equal([]string{"ID"}, primaryKeys(reflect.TypeOf(untaggedModel{})))
+	s.Empty(primaryKeys(reflect.TypeOf(struct{ Name string }{})))
+}
+
+func (s *paginatorSuite) TestPaginateCursorQueryArgsForBigNumber() {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigFloat, _, _ := big.ParseFloat("1.25", 10, 64, big.ToNearestEven)
+
+	args := New().getCursorQueryArgs([]interface{}{bigInt, bigFloat})
+	s.Equal([]interface{}{"123456789012345678901234567890", "123456789012345678901234567890", "1.25"}, args)
+}
+
+/* util */
+
+func (s *paginatorSuite) paginate(stmt *gorm.DB, out interface{}, q pq) Cursor {
+	return s.paginateBy(q.Paginator(), stmt, out)
+}
+
+func (s *paginatorSuite) paginateBy(p *Paginator, stmt *gorm.DB, out interface{}) Cursor {
+	result, err := p.Paginate(newGormQuery(stmt, out))
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+	if err := result.(*gormQuery).db.Error; err != nil {
+		s.FailNow(err.Error())
+	}
+	return p.GetNextCursor()
+}
+
+// prefixCodec encodes cursors with prefix
+type prefixCodec struct {
+	encoder CursorEncoder
+	decoder CursorDecoder
+}
+
+func (c prefixCodec) Encode(v interface{}) string {
+	return "cur_" + c.encoder.Encode(v)
+}
+
+func (c prefixCodec) Decode(cursor string) []interface{} {
+	if !strings.HasPrefix(cursor, "cur_") {
+		return nil
+	}
+	return c.decoder.Decode(strings.TrimPrefix(cursor, "cur_"))
+}
+
+// memoryCache is an in-memory Cache
+type memoryCache struct {
+	values map[string][]byte
+	ttl    time.Duration
+	err    error
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool, error) {
+	value, ok := c.values[key]
+	return value, ok, c.err
+}
+
+func (c *memoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.values[key], c.ttl = value, ttl
+	return nil
+}
+
+// gormQuery adapts *gorm.DB to Query
+type gormQuery struct {
+	db  *gorm.DB
+	out interface{}
+}
+
+func newGormQuery(db *gorm.DB, out interface{}) *gormQuery {
+	return &gormQuery{db: db, out: out}
+}
+
+func (q *gormQuery) Model() interface{} {
+	return q.out
+}
+
+func (q *gormQuery) Value() interface{} {
+	return q.out
+}
+
+func (q *gormQuery) Table() string {
+	if q.db.Statement.Table == "" {
+		q.db.Statement.Parse(q.out)
+	}
+	// tables of schema are qualified by the schema
+	if expr := q.db.Statement.TableExpr; expr != nil {
+		name := strings.NewReplacer("`", "", `"`, "").Replace(expr.SQL)
+		if parts := strings.Split(name, "."); len(parts) == 2 && parts[1] == q.db.Statement.Table {
+			return name
+		}
+	}
+	return q.db.Statement.Table
+}
+
+func (q *gormQuery) Selects() []string {
+	return q.db.Statement.Selects
+}
+
+func (q *gormQuery) AddSelects(columns ...string) Query {
+	selects := append(append([]string(nil), q.db.Statement.Selects...), columns...)
+	return &gormQuery{db: q.db.Select(selects), out: q.out}
+}
+
+func (q *gormQuery) Wrap(alias string, selects ...string) Query {
+	columns := q.db.Statement.Selects
+	if len(columns) == 0 {
+		columns = []string{q.Table() + ".*"}
+	}
+	inner := q.db.Model(q.out).Select(strings.Join(append(append([]string(nil), columns...), selects...), ", "))
+	outer := q.db.Session(&gorm.Session{}).Table(fmt.Sprintf("(?) AS %s", alias), inner)
+	return &gormQuery{db: outer, out: q.out}
+}
+
+func (q *gormQuery) With(name string) Query {
+	// CTE is defined in a derived table since FROM is the only clause before
+	// WHERE which can be customized
+	inner := q.db.Model(q.out)
+	outer := q.db.Session(&gorm.Session{}).Table(fmt.Sprintf("(WITH %s AS (?) SELECT * FROM %s) AS %s", name, name, name), inner)
+	return &gormQuery{db: outer, out: q.out}
+}
+
+func (q *gormQuery) Hint(hint Hint) Query {
+	// hint comments are not supported by GORM yet
+	table := q.Table()
+	db := q.db.Table(fmt.Sprintf("%s %s", table, hint.Table))
+	db.Statement.Table = table
+	return &gormQuery{db: db, out: q.out}
+}
+
+func (q *gormQuery) UnionAll(alias string, others ...Query) Query {
+	parts := []interface{}{q.db.Model(q.out)}
+	for _, other := range others {
+		parts = append(parts, other.(*gormQuery).db)
+	}
+	union := strings.TrimSuffix(strings.Repeat("? UNION ALL ", len(parts)), " UNION ALL ")
+	outer := q.db.Session(&gorm.Session{}).Table(fmt.Sprintf("(%s) AS %s", union, alias), parts...)
+	return &gormQuery{db: outer, out: q.out}
+}
+
+func (q *gormQuery) SelectOnly(columns ...string) Query {
+	return &gormQuery{db: q.db.Select(columns), out: q.out}
+}
+
+func (q *gormQuery) Indexes() ([]Index, error) {
+	rows, err := q.db.Session(&gorm.Session{}).Raw(MySQLIndexesSQL, q.Table()).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, column)
+	}
+	return indexes, rows.Err()
+}
+
+func (q *gormQuery) Explain() (string, error) {
+	stmt := q.db.Session(&gorm.Session{DryRun: true, WithConditions: true}).Find(q.out).Statement
+	rows, err := q.db.Session(&gorm.Session{}).Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Rows()
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+func (q *gormQuery) Counter() func() (int64, error) {
+	// conditions chained on the query later must not leak into count
+	db := q.db.Session(&gorm.Session{WithConditions: true}).Model(q.out)
+	db.Statement.TableExpr = q.db.Statement.TableExpr
+	return func() (int64, error) {
+		var total int64
+		err := db.Count(&total).Error
+		return total, err
+	}
+}
+
+func (q *gormQuery) Distinct() Query {
+	return &gormQuery{db: q.db.Distinct(), out: q.out}
+}
+
+func (q *gormQuery) Err() error {
+	return q.db.Error
+}
+
+func (q *gormQuery) Exists() (bool, error) {
+	var exists bool
+	sub := q.db.Session(&gorm.Session{WithConditions: true}).Model(q.out).Select("1")
+	err := q.db.Session(&gorm.Session{}).Raw("SELECT EXISTS (?)", sub).Row().Scan(&exists)
+	return exists, err
+}
+
+func (q *gormQuery) Having(query string, args ...interface{}) Query {
+	return &gormQuery{db: q.db.Having(query, args...), out: q.out}
+}
+
+func (q *gormQuery) Where(query string, args ...interface{}) Query {
+	return &gormQuery{db: q.db.Where(query, args...), out: q.out}
+}
+
+func (q *gormQuery) Limit(limit int) Query {
+	return &gormQuery{db: q.db.Limit(limit), out: q.out}
+}
+
+func (q *gormQuery) Context() context.Context {
+	return q.db.Statement.Context
+}
+
+func (q *gormQuery) WithContext(ctx context.Context) Query {
+	return &gormQuery{db: q.db.WithContext(ctx), out: q.out}
+}
+
+func (q *gormQuery) Offset(n int) Query {
+	return &gormQuery{db: q.db.Offset(n), out: q.out}
+}
+
+func (q *gormQuery) Order(order string) Query {
+	return &gormQuery{db: q.db.Order(order), out: q.out}
+}
+
+func (q *gormQuery) Select() Query {
+	return &gormQuery{db: q.db.Find(q.out), out: q.out}
+}
+
+// pq stands for paging query
+type pq struct {
+	Keys         []string
+	Rules        []Rule
+	After        *string
+	Before       *string
+	Limit        *int
+	Order        *Order
+	TieBreaker   bool
+	DeferredJoin bool
+}
+
+func (q pq) Paginator() *Paginator {
+	p := New()
+	if q.Keys != nil {
+		p.SetKeys(q.Keys...)
+	}
+	if q.Rules != nil {
+		p.SetRules(q.Rules...)
+	}
+	if q.After != nil {
+		p.SetAfterCursor(*q.After)
+	}
+	if q.Before != nil {
+		p.SetBeforeCursor(*q.Before)
+	}
+	if q.Limit != nil {
+		p.SetLimit(*q.Limit)
+	}
+	if q.Order != nil {
+		p.SetOrder(*q.Order)
+	}
+	p.SetTieBreaker(q.TieBreaker)
+	p.SetDeferredJoin(q.DeferredJoin)
+	return p
+}
+
+func orderNames(orders []order) []string {
+	names := make([]string, len(orders))
+	for i, o := range orders {
+		names[i] = *o.Name
+	}
+	return names
+}
+
+func eventSeqs(events []event) []int {
+	seqs := make([]int, len(events))
+	for i, e := range events {
+		seqs[i] = e.Seq
+	}
+	return seqs
+}
+
+func orderIDs(orders []order) []int {
+	ids := make([]int, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func pqString(str string) *string {
+	return &str
+}
+
+func pqTime(t time.Time) *time.Time {
+	return &t
+}
+
+func pqLimit(limit int) *int {
+	return &limit
+}
+
+func pqOrder(order Order) *Order {
+	return &order
+}
+
+// gormTxBeginner begins transactions of db
+type gormTxBeginner struct {
+	db *gorm.DB
+}
+
+func (b gormTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx := b.db.WithContext(ctx).Begin(opts)
+	// statements of tx are not chained onto each other
+	return gormTx{tx.Session(&gorm.Session{})}, tx.Error
+}
+
+// gormTx is a transaction of gorm
+type gormTx struct {
+	db *gorm.DB
+}
+
+func (tx gormTx) Exec(statement string) error {
+	return tx.db.Exec(statement).Error
+}
+
+func (tx gormTx) Commit() error {
+	return tx.db.Commit().Error
+}
+
+func (tx gormTx) Rollback() error {
+	return tx.db.Rollback().Error
+}
+
+// savepointTxBeginner begins transactions recording savepoint statements
+// instead of executing them
+type savepointTxBeginner struct {
+	TxBeginner
+	statements []string
+	committed  bool
+}
+
+func (b *savepointTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := b.TxBeginner.BeginTx(ctx, opts)
+	return &savepointTx{Tx: tx, beginner: b}, err
+}
+
+type savepointTx struct {
+	Tx
+	beginner *savepointTxBeginner
+}
+
+func (tx *savepointTx) Exec(statement string) error {
+	tx.beginner.statements = append(tx.beginner.statements, statement)
+	return nil
+}
+
+func (tx *savepointTx) Commit() error {
+	tx.beginner.committed = true
+	return tx.Tx.Commit()
+}
+
+var errTxBegin = errors.New("begin")
+
+// isolationTxBeginner records isolation level of transactions and fails to
+// begin them
+type isolationTxBeginner struct {
+	isolation sql.IsolationLevel
+}
+
+func (b *isolationTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	b.isolation = opts.Isolation
+	return nil, errTxBegin
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrShortWrite
+}
+
+// recordQuery records how paginator builds query without executing it
+type recordQuery struct {
+	out        interface{}
+	table      string
+	wheres     []string
+	args       [][]interface{}
+	orders     []string
+	limit      int
+	distinctOn []string
+	// rows is number of rows appended into out on select, which are limited
+	// by limit of query
+	rows int
+}
+
+func newRecordQuery(out interface{}, table string) *recordQuery {
+	return &recordQuery{out: out, table: table}
+}
+
+func (q *recordQuery) Model() interface{} {
+	return q.out
+}
+
+func (q *recordQuery) Value() interface{} {
+	return q.out
+}
+
+func (q *recordQuery) Table() string {
+	return q.table
+}
+
+func (q *recordQuery) Where(query string, args ...interface{}) Query {
+	q.wheres = append(q.wheres, query)
+	q.args = append(q.args, args)
+	return q
+}
+
+func (q *recordQuery) Limit(limit int) Query {
+	q.limit = limit
+	return q
+}
+
+func (q *recordQuery) Order(order string) Query {
+	q.orders = append(q.orders, order)
+	return q
+}
+
+func (q *recordQuery) DistinctOn(expressions ...string) Query {
+	q.distinctOn = expressions
+	return q
+}
+
+func (q *recordQuery) Select() Query {
+	elems := reflect.ValueOf(q.out).Elem()
+	for i := 0; i < q.rows && (q.limit <= 0 || i < q.limit); i++ {
+		elem := reflect.New(elems.Type().Elem()).Elem()
+		elem.FieldByName("ID").SetInt(int64(q.rows - i))
+		elems.Set(reflect.Append(elems, elem))
+	}
+	return q
+}
+
+// hintRecordQuery is a recordQuery recording hints
+type hintRecordQuery struct {
+	*recordQuery
+	hints *[]Hint
+}
+
+func (q *hintRecordQuery) Hint(hint Hint) Query {
+	*q.hints = append(*q.hints, hint)
+	return q
+}
+
+// indexRecordQuery is a recordQuery listing indexes, calls counts listings
+type indexRecordQuery struct {
+	*recordQuery
+	indexes []Index
+	err     error
+	calls   *int
+}
+
+func (q *indexRecordQuery) Indexes() ([]Index, error) {
+	*q.calls++
+	return q.indexes, q.err
+}
+
+// recordTracer records spans started by paginator
+type recordTracer struct {
+	spans []*recordSpan
+}
+
+func (t *recordTracer) Start(name string) Span {
+	span := &recordSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+type recordSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordSpan) SetAttributes(attributes ...Attribute) {
+	for _, attr := range attributes {
+		s.attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordSpan) End() {
+	s.ended = true
+}
+
+// recordMetrics records metrics observed by paginator
+type recordMetrics struct {
+	fillRatios     []float64
+	invalidCursors int
+}
+
+func (m *recordMetrics) ObservePage(fillRatio float64, duration time.Duration) {
+	m.fillRatios = append(m.fillRatios, fillRatio)
+}
+
+func (m *recordMetrics) IncInvalidCursors() {
+	m.invalidCursors++
+}
+
+// recordLogger records log entries of paginator
+// negatedDirection orders keys by their negation in the reverse order
+type negatedDirection struct{}
+
+func (negatedDirection) OrderBy(sqlKey string, order Order) string {
+	if order == ASC {
+		return fmt.Sprintf("-%s DESC", sqlKey)
+	}
+	return fmt.Sprintf("-%s ASC", sqlKey)
+}
+
+func (negatedDirection) Operator(order Order) string {
+	return DefaultDirection.Operator(order)
+}
+
+type recordLogger struct {
+	entries []LogEntry
+}
+
+func (l *recordLogger) Log(entry LogEntry) {
+	l.entries = append(l.entries, entry)
+}
+
+/* order */
+
+func (s *paginatorSuite) givenOrders(n int) []order {
+	orders := make([]order, n)
+	for i := 0; i < n; i++ {
+		orders[i] = order{}
+	}
+	return s.createOrders(orders)
+}
+
+func (s *paginatorSuite) givenPtrOrders(n int) []*order {
+	var result []*order
+	orders := s.givenOrders(n)
+	for i := 0; i < len(orders); i++ {
+		result = append(result, &orders[i])
+	}
+	return result
+}
+
+func (s *paginatorSuite) givenCustomOrders(orders []order) []order {
+	return s.createOrders(orders)
+}
+
+func (s *paginatorSuite) createOrders(orders []order) []order {
+	for i := 0; i < len(orders); i++ {
+		if err := s.db.Create(&orders[i]).Error; err != nil {
+			s.FailNow(err.Error())
+		}
+	}
+	return orders
+}
+
+/* item */
+
+func (s *paginatorSuite) givenItems(orderID int, n int) []item {
+	items := make([]item, n)
+	for i := 0; i < n; i++ {
+		items[i] = item{
+			OrderID: orderID,
+		}
+	}
+	return s.createItems(items)
+}
+
+func (s *paginatorSuite) createItems(items []item) []item {
+	for i := 0; i < len(items); i++ {
+		if err := s.db.Create(&items[i]).Error; err != nil {
+			s.FailNow(err.Error())
+		}
+	}
+	return items
+}
+
+/* assert */
+
+func (s *paginatorSuite) assertOnlyAfter(cursor Cursor) {
+	s.NotNil(cursor.After)
+	s.Nil(cursor.Before)
+}
+
+func (s *paginatorSuite) assertOnlyBefore(cursor Cursor) {
+	s.Nil(cursor.After)
+	s.NotNil(cursor.Before)
+}
+
+func (s *paginatorSuite) assertBoth(cursor Cursor) {
+	s.NotNil(cursor.After)
+	s.NotNil(cursor.Before)
+}
+
+func (s *paginatorSuite) assertPageInfo(total, start, end int64, info PageInfo) {
+	s.Equal(total, *info.Total)
+	s.Equal(start, *info.StartPosition)
+	s.Equal(end, *info.EndPosition)
 }
 
 func (s *paginatorSuite) assertOrders(expected []order, head, tail int, got []order) {
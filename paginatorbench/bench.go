@@ -0,0 +1,270 @@
+// Package paginatorbench provides benchmarks of paginator over representative
+// models, i.e. building cursor predicates, encoding and decoding cursors and
+// post-processing fetched pages, so that the project and its users can
+// measure regressions by `go test -bench` or gate them by Run and Compare
+package paginatorbench
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// ErrRegression is returned by Compare when results regress from baseline
+var ErrRegression = errors.New("performance regression")
+
+// PageSize is limit of benchmarked pages
+const PageSize = 50
+
+// Order is a representative model paged by time and ID
+type Order struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+// Event is a representative model of multi-tenant data paged by tenant,
+// score and string ID
+type Event struct {
+	ID       string
+	TenantID int64
+	Score    float64
+	Payload  []byte
+}
+
+// Benchmark is a named benchmark function
+type Benchmark struct {
+	Name string
+	F    func(b *testing.B)
+}
+
+// Result is result of a benchmark
+type Result struct {
+	Name              string `json:"name"`
+	N                 int    `json:"n"`
+	NsPerOp           int64  `json:"ns_per_op"`
+	AllocsPerOp       int64  `json:"allocs_per_op"`
+	AllocedBytesPerOp int64  `json:"alloced_bytes_per_op"`
+}
+
+// model is a representative model with its paging keys
+type model struct {
+	name string
+	keys []string
+	// rows returns n rows as a slice of model in descending order of keys
+	rows func(n int) interface{}
+}
+
+var models = []model{
+	{
+		name: "Order",
+		keys: []string{"CreatedAt", "ID"},
+		rows: func(n int) interface{} {
+			createdAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			rows := make([]Order, n)
+			for i := range rows {
+				id := n - i
+				rows[i] = Order{ID: id, Name: fmt.Sprintf("order %d", id), CreatedAt: createdAt.Add(time.Duration(id) * time.Second)}
+			}
+			return rows
+		},
+	},
+	{
+		name: "Event",
+		keys: []string{"TenantID", "Score", "ID"},
+		rows: func(n int) interface{} {
+			rows := make([]Event, n)
+			for i := range rows {
+				id := n - i
+				rows[i] = Event{ID: fmt.Sprintf("evt_%08d", id), TenantID: 42, Score: float64(id) / 4, Payload: []byte("{}")}
+			}
+			return rows
+		},
+	},
+}
+
+// Benchmarks returns benchmarks of each representative model, named by model
+// and operation, e.g. Order/Encode
+func Benchmarks() []Benchmark {
+	var benchmarks []Benchmark
+	for _, m := range models {
+		benchmarks = append(benchmarks,
+			Benchmark{Name: m.name + "/Predicate", F: m.benchmarkPredicate},
+			Benchmark{Name: m.name + "/Encode", F: m.benchmarkEncode},
+			Benchmark{Name: m.name + "/Decode", F: m.benchmarkDecode},
+			Benchmark{Name: m.name + "/PostProcess", F: m.benchmarkPostProcess},
+		)
+	}
+	return benchmarks
+}
+
+// BenchmarkAll runs all benchmarks as sub-benchmarks of b, e.g. from
+// `func BenchmarkPaginator(b *testing.B) { paginatorbench.BenchmarkAll(b) }`
+func BenchmarkAll(b *testing.B) {
+	for _, benchmark := range Benchmarks() {
+		b.Run(benchmark.Name, benchmark.F)
+	}
+}
+
+// Run runs benchmarks, or all benchmarks if none is given, and returns their
+// results
+func Run(benchmarks ...Benchmark) []Result {
+	if len(benchmarks) == 0 {
+		benchmarks = Benchmarks()
+	}
+	results := make([]Result, len(benchmarks))
+	for i, benchmark := range benchmarks {
+		r := testing.Benchmark(benchmark.F)
+		results[i] = Result{
+			Name:              benchmark.Name,
+			N:                 r.N,
+			NsPerOp:           r.NsPerOp(),
+			AllocsPerOp:       r.AllocsPerOp(),
+			AllocedBytesPerOp: r.AllocedBytesPerOp(),
+		}
+	}
+	return results
+}
+
+// Compare compares results with baseline of the same names, an error wrapping
+// ErrRegression is returned if time of any result exceeds its baseline by
+// more than tolerance (e.g. 0.1 for 10%) or any result allocates more often.
+// Results without baseline are ignored.
+func Compare(baseline, results []Result, tolerance float64) error {
+	base := make(map[string]Result, len(baseline))
+	for _, r := range baseline {
+		base[r.Name] = r
+	}
+	var regressions []string
+	for _, r := range results {
+		b, ok := base[r.Name]
+		if !ok {
+			continue
+		}
+		if float64(r.NsPerOp) > float64(b.NsPerOp)*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s takes %d ns/op, baseline %d ns/op", r.Name, r.NsPerOp, b.NsPerOp))
+		}
+		if r.AllocsPerOp > b.AllocsPerOp {
+			regressions = append(regressions, fmt.Sprintf("%s allocates %d allocs/op, baseline %d allocs/op", r.Name, r.AllocsPerOp, b.AllocsPerOp))
+		}
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%w: %s", ErrRegression, strings.Join(regressions, "; "))
+	}
+	return nil
+}
+
+/* benchmarks */
+
+func (m model) newPaginator() *paginator.Paginator {
+	p := paginator.New()
+	p.SetKeys(m.keys...)
+	p.SetLimit(PageSize)
+	return p
+}
+
+// cursor returns after cursor of the middle row of rows
+func (m model) cursor(rows reflect.Value) string {
+	return paginator.NewCursorEncoder(m.keys...).Encode(rows.Index(rows.Len() / 2).Interface())
+}
+
+func (m model) benchmarkPredicate(b *testing.B) {
+	rows := reflect.ValueOf(m.rows(1))
+	ref := rows.Index(0).Interface()
+	decoder, err := paginator.NewCursorDecoder(ref, m.keys...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	fields := decoder.Decode(m.cursor(rows))
+	p := m.newPaginator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := p.BuildCursorWhere(ref, fields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func (m model) benchmarkEncode(b *testing.B) {
+	row := reflect.ValueOf(m.rows(1)).Index(0).Interface()
+	encoder := paginator.NewCursorEncoder(m.keys...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encoder.Encode(row)
+	}
+}
+
+func (m model) benchmarkDecode(b *testing.B) {
+	rows := reflect.ValueOf(m.rows(1))
+	decoder, err := paginator.NewCursorDecoder(rows.Index(0).Interface(), m.keys...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	cursor := m.cursor(rows)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if decoder.Decode(cursor) == nil {
+			b.Fatal("paginatorbench: cannot decode cursor")
+		}
+	}
+}
+
+func (m model) benchmarkPostProcess(b *testing.B) {
+	rows := reflect.ValueOf(m.rows(PageSize + 1))
+	out := reflect.New(rows.Type())
+	query := &pageQuery{rows: rows, out: out}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.newPaginator().Paginate(query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// pageQuery is a paginator.Query whose selection is the fetched page rows,
+// so that benchmark of Paginate is not dominated by the query
+type pageQuery struct {
+	rows reflect.Value
+	out  reflect.Value
+}
+
+func (q *pageQuery) Model() interface{} {
+	return q.out.Interface()
+}
+
+func (q *pageQuery) Value() interface{} {
+	return q.out.Interface()
+}
+
+func (q *pageQuery) Table() string {
+	return "rows"
+}
+
+func (q *pageQuery) Where(query string, args ...interface{}) paginator.Query {
+	return q
+}
+
+func (q *pageQuery) Limit(limit int) paginator.Query {
+	return q
+}
+
+func (q *pageQuery) Order(order string) paginator.Query {
+	return q
+}
+
+// Select copies rows into out, reusing capacity of out as drivers scanning
+// into a reused slice do
+func (q *pageQuery) Select() paginator.Query {
+	out := q.out.Elem()
+	out.Set(reflect.AppendSlice(out.Slice(0, 0), q.rows))
+	return q
+}
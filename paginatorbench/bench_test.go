@@ -0,0 +1,63 @@
+package paginatorbench
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func BenchmarkPaginator(b *testing.B) {
+	BenchmarkAll(b)
+}
+
+var sink []byte
+
+func TestRun(t *testing.T) {
+	results := Run(Benchmark{Name: "alloc", F: func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sink = make([]byte, 64)
+		}
+	}})
+	assert.Len(t, results, 1)
+	assert.Equal(t, "alloc", results[0].Name)
+	assert.True(t, results[0].N > 0)
+	assert.Equal(t, int64(1), results[0].AllocsPerOp)
+	assert.Equal(t, int64(64), results[0].AllocedBytesPerOp)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []Result{
+		{Name: "Order/Encode", NsPerOp: 1000, AllocsPerOp: 10},
+		{Name: "Order/Decode", NsPerOp: 2000, AllocsPerOp: 20},
+	}
+	assert.NoError(t, Compare(baseline, []Result{
+		{Name: "Order/Encode", NsPerOp: 1090, AllocsPerOp: 10},
+		{Name: "Order/Decode", NsPerOp: 1500, AllocsPerOp: 18},
+		{Name: "Event/Encode", NsPerOp: 9000, AllocsPerOp: 90},
+	}, 0.1))
+
+	err := Compare(baseline, []Result{
+		{Name: "Order/Encode", NsPerOp: 1200, AllocsPerOp: 10},
+		{Name: "Order/Decode", NsPerOp: 2000, AllocsPerOp: 21},
+	}, 0.1)
+	assert.True(t, errors.Is(err, ErrRegression))
+	assert.EqualError(t, err, "performance regression: "+
+		"Order/Encode takes 1200 ns/op, baseline 1000 ns/op; "+
+		"Order/Decode allocates 21 allocs/op, baseline 20 allocs/op")
+}
+
+func TestBenchmarksPaginateRepresentativePages(t *testing.T) {
+	for _, m := range models {
+		rows := reflect.ValueOf(m.rows(PageSize + 1))
+		out := reflect.New(rows.Type())
+		p := m.newPaginator()
+		_, err := p.Paginate(&pageQuery{rows: rows, out: out})
+		assert.NoError(t, err, m.name)
+		assert.Equal(t, PageSize, out.Elem().Len(), m.name)
+		assert.NotNil(t, p.GetNextCursor().After, m.name)
+	}
+	assert.Len(t, Benchmarks(), 4*len(models))
+}
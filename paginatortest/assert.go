@@ -0,0 +1,165 @@
+package paginatortest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// UpdateGoldenEnv is the environment variable to set for AssertGoldenCursor
+// to (re)write golden files instead of comparing with them
+const UpdateGoldenEnv = "PAGINATORTEST_UPDATE_GOLDEN"
+
+// TestingT is the subset of testing.T used by assertions
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertGoldenCursor asserts cursor equals the content of golden file at
+// path, so that changes of cursor format which break issued cursors are
+// caught. The file is written instead if UpdateGoldenEnv is set.
+func AssertGoldenCursor(t TestingT, path string, cursor string) bool {
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := ioutil.WriteFile(path, []byte(cursor+"\n"), 0644); err != nil {
+			t.Errorf("paginatortest: cannot write golden file %s: %s", path, err)
+			return false
+		}
+		return true
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("paginatortest: cannot read golden file %s: %s", path, err)
+		return false
+	}
+	if golden := strings.TrimSpace(string(b)); golden != cursor {
+		t.Errorf("paginatortest: cursor %q does not match golden %q of %s", cursor, golden, path)
+		return false
+	}
+	return true
+}
+
+// AssertContinuity asserts pages (slices of rows) concatenated in order are
+// the same as expected rows, which reports the first gap or duplicate
+func AssertContinuity(t TestingT, expected interface{}, pages ...interface{}) bool {
+	ev := reflect.ValueOf(expected)
+	var got []interface{}
+	for _, page := range pages {
+		pv := reflect.ValueOf(page)
+		for i := 0; i < pv.Len(); i++ {
+			got = append(got, pv.Index(i).Interface())
+		}
+	}
+	for i := 0; i < ev.Len() || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			t.Errorf("paginatortest: gap at row %d, missing %s", i, dump(ev.Index(i).Interface()))
+			return false
+		case i >= ev.Len():
+			t.Errorf("paginatortest: unexpected row %d: %s", i, dump(got[i]))
+			return false
+		case !reflect.DeepEqual(ev.Index(i).Interface(), got[i]):
+			t.Errorf("paginatortest: row %d is %s, expected %s", i, dump(got[i]), dump(ev.Index(i).Interface()))
+			return false
+		}
+	}
+	return true
+}
+
+// errWalked stops walks which return more rows than the dataset
+var errWalked = errors.New("paginatortest: walked more rows than dataset")
+
+// AssertStable asserts walking all pages of query by paginator p forward and
+// then backward both return each of rows, the whole dataset in any order,
+// exactly once, so that apps can assert in CI that their paging keys neither
+// skip nor duplicate rows. Each walk is by a clone of p from its first (or
+// last) page, and query builds a fresh query of each page as in EachPage.
+func AssertStable(t TestingT, p *paginator.Paginator, query func() paginator.Query, rows interface{}) bool {
+	forward := p.Clone()
+	backward := p.Clone()
+	backward.SetBeforeCursor("")
+	for _, walk := range []struct {
+		name string
+		p    *paginator.Paginator
+	}{
+		{"forward", forward},
+		{"backward", backward},
+	} {
+		got, err := walkRows(walk.p, query, reflect.ValueOf(rows).Len())
+		if err != nil {
+			t.Errorf("paginatortest: %s walk failed: %s", walk.name, err)
+			return false
+		}
+		if !assertSameRows(t, walk.name, rows, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkRows returns rows of all pages walked by p, the walk stops once it
+// returns more than n rows
+func walkRows(p *paginator.Paginator, query func() paginator.Query, n int) ([]interface{}, error) {
+	var got []interface{}
+	err := p.EachPage(query, func(page interface{}) error {
+		pv := reflect.Indirect(reflect.ValueOf(page))
+		for i := 0; i < pv.Len(); i++ {
+			got = append(got, pv.Index(i).Interface())
+		}
+		if len(got) > n {
+			return errWalked
+		}
+		return nil
+	})
+	if errors.Is(err, errWalked) {
+		err = nil
+	}
+	return got, err
+}
+
+// assertSameRows asserts got rows are expected rows regardless of order,
+// which reports the first duplicate, unexpected or missing row
+func assertSameRows(t TestingT, walk string, expected interface{}, got []interface{}) bool {
+	ev := reflect.ValueOf(expected)
+	matched := make([]bool, ev.Len())
+	for i, row := range got {
+		j := indexOf(ev, matched, row)
+		switch {
+		case j >= 0:
+			matched[j] = true
+		case indexOf(ev, nil, row) >= 0:
+			t.Errorf("paginatortest: %s walk duplicates row %d: %s", walk, i, dump(row))
+			return false
+		default:
+			t.Errorf("paginatortest: %s walk returns unexpected row %d: %s", walk, i, dump(row))
+			return false
+		}
+	}
+	for j, ok := range matched {
+		if !ok {
+			t.Errorf("paginatortest: %s walk skips %s", walk, dump(ev.Index(j).Interface()))
+			return false
+		}
+	}
+	return true
+}
+
+// indexOf returns index of the first row of rows equal to row and not
+// matched yet, or -1 if there is none
+func indexOf(rows reflect.Value, matched []bool, row interface{}) int {
+	r := reflect.Indirect(reflect.ValueOf(row)).Interface()
+	for i := 0; i < rows.Len(); i++ {
+		if (matched == nil || !matched[i]) && reflect.DeepEqual(reflect.Indirect(rows.Index(i)).Interface(), r) {
+			return i
+		}
+	}
+	return -1
+}
+
+func dump(v interface{}) string {
+	return fmt.Sprintf("%+v", reflect.Indirect(reflect.ValueOf(v)).Interface())
+}
@@ -0,0 +1,265 @@
+// Package paginatortest provides helpers for testing code using paginator
+// without a database
+package paginatortest
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+// Query is an in-memory paginator.Query over rows, it evaluates cursor
+// predicates and orders which paginator builds on plain column keys
+// (expressions such as LOWER or COALESCE are not supported)
+type Query struct {
+	table  string
+	rows   reflect.Value
+	out    interface{}
+	conds  [][]cond
+	orders []orderBy
+	limit  int
+	err    error
+}
+
+type cond struct {
+	column string
+	op     string
+	arg    interface{}
+}
+
+type orderBy struct {
+	column string
+	desc   bool
+}
+
+// NewQuery creates query of table over rows (a slice of structs or struct
+// pointers), out is a pointer to slice receiving selected rows
+func NewQuery(table string, rows interface{}, out interface{}) *Query {
+	return &Query{table: table, rows: reflect.ValueOf(rows), out: out, limit: -1}
+}
+
+// Err returns error of unsupported predicate or order
+func (q *Query) Err() error {
+	return q.err
+}
+
+// Model implements paginator.Query
+func (q *Query) Model() interface{} {
+	return q.out
+}
+
+// Value implements paginator.Query
+func (q *Query) Value() interface{} {
+	return q.out
+}
+
+// Table implements paginator.Query
+func (q *Query) Table() string {
+	return q.table
+}
+
+// Where implements paginator.Query, query should be a disjunction of
+// conjunctions of `column op ?` as paginator builds
+func (q *Query) Where(query string, args ...interface{}) paginator.Query {
+	for _, disjunct := range strings.Split(query, " OR ") {
+		var conds []cond
+		for _, conjunct := range strings.Split(disjunct, " AND ") {
+			parts := strings.Fields(conjunct)
+			if len(parts) != 3 || !isOperator(parts[1]) || parts[2] != "?" ||
+				strings.ContainsAny(parts[0], "()") || len(args) == 0 {
+				q.err = fmt.Errorf("paginatortest: unsupported predicate %q", conjunct)
+				return q
+			}
+			conds = append(conds, cond{column: q.toColumn(parts[0]), op: parts[1], arg: args[0]})
+			args = args[1:]
+		}
+		q.conds = append(q.conds, conds)
+	}
+	return q
+}
+
+// Limit implements paginator.Query
+func (q *Query) Limit(limit int) paginator.Query {
+	q.limit = limit
+	return q
+}
+
+// Order implements paginator.Query
+func (q *Query) Order(order string) paginator.Query {
+	for _, expr := range strings.Split(order, ",") {
+		parts := strings.Fields(expr)
+		if len(parts) == 0 || len(parts) > 2 {
+			q.err = fmt.Errorf("paginatortest: unsupported order %q", expr)
+			return q
+		}
+		desc := len(parts) == 2 && strings.EqualFold(parts[1], "DESC")
+		q.orders = append(q.orders, orderBy{column: q.toColumn(parts[0]), desc: desc})
+	}
+	return q
+}
+
+// Select implements paginator.Query, matched rows are stored into out
+func (q *Query) Select() paginator.Query {
+	out := reflect.ValueOf(q.out).Elem()
+	result := reflect.MakeSlice(out.Type(), 0, q.rows.Len())
+	for i := 0; i < q.rows.Len(); i++ {
+		if row := q.rows.Index(i); q.match(row) {
+			result = reflect.Append(result, row)
+		}
+	}
+	sort.SliceStable(result.Interface(), func(i, j int) bool {
+		for _, o := range q.orders {
+			c, _ := compare(q.field(result.Index(i), o.column), q.field(result.Index(j), o.column))
+			if c != 0 {
+				return (c < 0) != o.desc
+			}
+		}
+		return false
+	})
+	if q.limit >= 0 && result.Len() > q.limit {
+		result = result.Slice(0, q.limit)
+	}
+	out.Set(result)
+	return q
+}
+
+func isOperator(op string) bool {
+	return op == "=" || op == "<" || op == ">"
+}
+
+func (q *Query) toColumn(expr string) string {
+	return strings.TrimPrefix(expr, q.table+".")
+}
+
+func (q *Query) match(row reflect.Value) bool {
+	if len(q.conds) == 0 {
+		return true
+	}
+	for _, conds := range q.conds {
+		if q.matchAll(row, conds) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Query) matchAll(row reflect.Value, conds []cond) bool {
+	for _, c := range conds {
+		r, ok := compare(q.field(row, c.column), c.arg)
+		if !ok {
+			return false
+		}
+		switch {
+		case c.op == "=" && r == 0, c.op == "<" && r < 0, c.op == ">" && r > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (q *Query) field(row reflect.Value, column string) interface{} {
+	row = reflect.Indirect(row)
+	for i := 0; i < row.NumField(); i++ {
+		if strcase.ToSnake(row.Type().Field(i).Name) == column {
+			return row.Field(i).Interface()
+		}
+	}
+	q.err = fmt.Errorf("paginatortest: unknown column %q", column)
+	return nil
+}
+
+// compare compares a and b, false if they are not comparable (e.g. NULL)
+func compare(a, b interface{}) (int, bool) {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	for _, v := range []*reflect.Value{&av, &bv} {
+		for v.IsValid() && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return 0, false
+			}
+			*v = v.Elem()
+		}
+		if !v.IsValid() {
+			return 0, false
+		}
+	}
+	if at, ok := av.Interface().(time.Time); ok {
+		bt, ok := bv.Interface().(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case at.Before(bt):
+			return -1, true
+		case at.After(bt):
+			return 1, true
+		}
+		return 0, true
+	}
+	switch {
+	case isInt(av) && isInt(bv):
+		return toBigInt(av).Cmp(toBigInt(bv)), true
+	case isNumber(av) && isNumber(bv):
+		return compareFloat(toFloat(av), toFloat(bv)), true
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return strings.Compare(av.String(), bv.String()), true
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		return compareFloat(boolToFloat(av.Bool()), boolToFloat(bv.Bool())), true
+	}
+	return 0, false
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func toBigInt(v reflect.Value) *big.Int {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint())
+	}
+	return big.NewInt(v.Int())
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package paginatortest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+type order struct {
+	ID        int
+	CreatedAt time.Time
+}
+
+func givenOrders() []order {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var orders []order
+	for i := 1; i <= 7; i++ {
+		// duplicated created at to exercise composite predicate
+		orders = append(orders, order{ID: i, CreatedAt: base.Add(time.Duration(i/2) * time.Hour)})
+	}
+	return orders
+}
+
+func paginate(t *testing.T, rows []order, cursor paginator.Cursor) ([]order, paginator.Cursor) {
+	p := paginator.New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetLimit(3)
+	if cursor.After != nil {
+		p.SetAfterCursor(*cursor.After)
+	}
+	if cursor.Before != nil {
+		p.SetBeforeCursor(*cursor.Before)
+	}
+	var out []order
+	q := NewQuery("orders", rows, &out)
+	_, err := p.Paginate(q)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Err())
+	return out, p.GetNextCursor()
+}
+
+func TestQueryPaginatesContinuously(t *testing.T) {
+	rows := givenOrders()
+	expected := make([]order, len(rows))
+	for i := range rows {
+		expected[i] = rows[len(rows)-1-i]
+	}
+
+	p1, c1 := paginate(t, rows, paginator.Cursor{})
+	p2, c2 := paginate(t, rows, paginator.Cursor{After: c1.After})
+	p3, c3 := paginate(t, rows, paginator.Cursor{After: c2.After})
+	assert.Nil(t, c3.After)
+	AssertContinuity(t, expected, p1, p2, p3)
+
+	back, _ := paginate(t, rows, paginator.Cursor{Before: c2.Before})
+	assert.Equal(t, p1, back)
+}
+
+func TestQueryShouldReportUnsupportedPredicate(t *testing.T) {
+	var out []order
+	q := NewQuery("orders", givenOrders(), &out)
+	q.Where("LOWER(orders.name) < ?", "a")
+	assert.Error(t, q.Err())
+
+	q = NewQuery("orders", givenOrders(), &out)
+	q.Where("orders.name LIKE ?", "a")
+	assert.Error(t, q.Err())
+}
+
+func TestAssertContinuity(t *testing.T) {
+	rows := givenOrders()
+	rec := &recordT{}
+	assert.False(t, AssertContinuity(rec, rows, rows[:2], rows[3:]))
+	assert.Contains(t, rec.errors[0], "row 2")
+
+	rec = &recordT{}
+	assert.False(t, AssertContinuity(rec, rows, rows[:2], rows[1:]))
+	assert.Len(t, rec.errors, 1)
+
+	assert.True(t, AssertContinuity(t, rows, rows[:2], rows[2:]))
+}
+
+func TestAssertStable(t *testing.T) {
+	rows := givenOrders()
+	query := func() paginator.Query {
+		var out []order
+		return NewQuery("orders", rows, &out)
+	}
+	p := paginator.New()
+	p.SetKeys("CreatedAt", "ID")
+	p.SetLimit(2)
+	assert.True(t, AssertStable(t, p, query, rows))
+
+	// rows of the same created at are skipped without tie-breaker
+	p = paginator.New()
+	p.SetKeys("CreatedAt")
+	p.SetLimit(3)
+	rec := &recordT{}
+	assert.False(t, AssertStable(rec, p, query, rows))
+	assert.Contains(t, rec.errors[0], "forward walk skips")
+}
+
+func TestAssertGoldenCursor(t *testing.T) {
+	cursor := paginator.NewCursorEncoder("CreatedAt", "ID").Encode(reflect.ValueOf(givenOrders()[0]))
+	AssertGoldenCursor(t, "testdata/cursor.golden", cursor)
+
+	rec := &recordT{}
+	assert.False(t, AssertGoldenCursor(rec, "testdata/cursor.golden", "changed"))
+}
+
+type recordT struct {
+	errors []string
+}
+
+func (t *recordT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
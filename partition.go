@@ -0,0 +1,56 @@
+package paginator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SetPartitionKey sets key whose value is constant for the query, e.g. ListID
+// of per-list timelines, which is pinned to value in WHERE clause and left out
+// of paging keys, so that it is neither ordered by nor encoded into cursors.
+// Cursor fingerprint is enabled with the value included, cursors of other
+// partitions are then rejected by ErrCursorFingerprintMismatch.
+func (p *Paginator) SetPartitionKey(key string, value interface{}) {
+	p.partitionKey, p.partitionValue = key, value
+	p.fingerprint = true
+}
+
+// validatePartitionKey validates partition key against model type rt
+func (p *Paginator) validatePartitionKey(rt reflect.Type) error {
+	if p.partitionKey == "" {
+		return nil
+	}
+	return validateRules(rt, []Rule{{Key: p.partitionKey}})
+}
+
+// appendPartition pins partition key of query to its value
+func (p *Paginator) appendPartition(query Query) Query {
+	if p.partitionKey == "" {
+		return query
+	}
+	sqlKey := Rule{Key: p.partitionKey}.sqlKey(p.getTable(query), p.dialect)
+	return query.Where(fmt.Sprintf("%s = ?", sqlKey), p.partitionValue)
+}
+
+// withoutPartitionKey returns rules without rule of partition key
+func (p *Paginator) withoutPartitionKey(rules []Rule) []Rule {
+	if p.partitionKey == "" {
+		return rules
+	}
+	result := rules[:0]
+	for _, rule := range rules {
+		if rule.Key != p.partitionKey {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// getPartitionHash returns hash of partition key and its value included in
+// cursor fingerprint
+func (p *Paginator) getPartitionHash() string {
+	if p.partitionKey == "" {
+		return ""
+	}
+	return hashFilters(map[string]interface{}{p.partitionKey: p.partitionValue})
+}
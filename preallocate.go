@@ -0,0 +1,27 @@
+package paginator
+
+import "reflect"
+
+// SetPreallocate sets whether to pre-allocate empty out of query to capacity
+// of rows fetched for the page (limit+1 by LookAheadRow) before the query is
+// executed, so that queries appending rows into out do not grow it repeatedly
+// for large pages. Queries which replace out by their own slice, e.g. Find of
+// GORM, do not benefit from it.
+func (p *Paginator) SetPreallocate(enabled bool) {
+	p.preallocate = enabled
+}
+
+// preallocatePage pre-allocates empty out of query to capacity of fetch limit
+func (p *Paginator) preallocatePage(query Query) {
+	if !p.preallocate || p.unlimited() {
+		return
+	}
+	elems := reflect.ValueOf(query.Value())
+	if elems.Kind() != reflect.Ptr || elems.Elem().Kind() != reflect.Slice {
+		return
+	}
+	elems = elems.Elem()
+	if n := p.fetchLimit(); elems.Len() == 0 && elems.Cap() < n {
+		elems.Set(reflect.MakeSlice(elems.Type(), 0, n))
+	}
+}
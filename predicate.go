@@ -0,0 +1,141 @@
+package paginator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Predicate strategy of cursor predicate
+type Predicate string
+
+// Predicate strategies
+const (
+	// PredicateOR expands keys into OR of conjunctions, e.g.
+	// a > ? OR a = ? AND b > ?
+	PredicateOR Predicate = "or"
+	// PredicateTuple compares keys as row value, e.g. (a, b) > (?, ?)
+	PredicateTuple Predicate = "tuple"
+	// PredicateRange leads OR-expansion with a range condition on the first
+	// key, which is friendly to index range scan of MySQL optimizer, e.g.
+	// a >= ? AND (a > ? OR a = ? AND b > ?)
+	PredicateRange Predicate = "range"
+)
+
+// SetPredicate sets strategy of cursor predicate, which defaults to the best
+// performing one of dialect (see SetDialect): tuple on Postgres and SQLite
+// supporting row values (see SetSQLiteVersion), range on MySQL and
+// OR-expansion otherwise. Keys with Nulls or Operators are always
+// OR-expanded, and keys in mixed orders are never compared as row value.
+func (p *Paginator) SetPredicate(predicate Predicate) {
+	p.predicate = predicate
+}
+
+func (p *Paginator) getPredicate() Predicate {
+	if p.predicate != "" {
+		return p.predicate
+	}
+	switch p.dialect {
+	case Postgres:
+		return PredicateTuple
+	case SQLite:
+		if p.supportsRowValues() {
+			return PredicateTuple
+		}
+		return PredicateOR
+	case MySQL:
+		return PredicateRange
+	default:
+		return PredicateOR
+	}
+}
+
+// SetInclusive sets whether the anchor row of cursor, whose keys equal to
+// values of cursor, is included in the page, e.g. to resume sync exactly
+// from the row a client left off. Next cursors of inclusive pages are of
+// their boundary rows, which are then included again.
+func (p *Paginator) SetInclusive(enabled bool) {
+	p.inclusive = enabled
+}
+
+// buildCursorQuery returns cursor predicate and its args by strategy, which
+// also matches the anchor row of cursor in inclusive mode
+func (p *Paginator) buildCursorQuery(fields []interface{}) (string, []interface{}) {
+	if p.useTupleCursorQuery() {
+		return p.getTupleCursorQuery(fields)
+	}
+	query, args := p.buildExpandedCursorQuery(fields)
+	if !p.inclusive {
+		return query, args
+	}
+	anchor, anchorArgs := p.getAnchorQuery(fields)
+	return fmt.Sprintf("%s OR %s", query, anchor), append(args, anchorArgs...)
+}
+
+// useTupleCursorQuery reports whether keys are compared as row value, which
+// cannot be compared by nulls or operators of each key, or in mixed orders
+func (p *Paginator) useTupleCursorQuery() bool {
+	if p.hasNullsKey() || p.hasOperatorsKey() || p.getPredicate() != PredicateTuple {
+		return false
+	}
+	return len(p.tableKeys) > 1 && !p.hasMixedOrder() && p.supportsRowValues()
+}
+
+// buildExpandedCursorQuery returns cursor predicate expanded by keys
+func (p *Paginator) buildExpandedCursorQuery(fields []interface{}) (string, []interface{}) {
+	if p.hasNullsKey() {
+		return p.getNullsCursorQuery(fields)
+	}
+	if !p.hasOperatorsKey() && p.getPredicate() == PredicateRange && len(p.tableKeys) > 1 {
+		return p.getRangeCursorQuery(fields)
+	}
+	return p.getCursorQuery(), p.getCursorQueryArgs(fields)
+}
+
+// getAnchorQuery returns predicate matching the anchor row of cursor
+func (p *Paginator) getAnchorQuery(fields []interface{}) (string, []interface{}) {
+	qs := make([]string, len(p.tableKeys))
+	var args []interface{}
+	for i, sqlKey := range p.tableKeys {
+		if isNullField(fields[i]) {
+			qs[i] = fmt.Sprintf("%s IS NULL", sqlKey)
+			continue
+		}
+		qs[i] = fmt.Sprintf("%s %s %s", sqlKey, p.rules[i].operator("="), p.rules[i].placeholder())
+		args = append(args, toQueryArg(fields[i]))
+	}
+	return strings.Join(qs, " AND "), args
+}
+
+func (p *Paginator) getTupleCursorQuery(fields []interface{}) (string, []interface{}) {
+	placeholders := make([]string, len(p.tableKeys))
+	args := make([]interface{}, len(fields))
+	for i, field := range fields {
+		placeholders[i] = p.rules[i].placeholder()
+		args[i] = toQueryArg(field)
+	}
+	operator := p.getOperator(p.rules[0])
+	if p.inclusive {
+		operator += "="
+	}
+	return fmt.Sprintf(
+		"(%s) %s (%s)",
+		strings.Join(p.tableKeys, ", "),
+		operator,
+		strings.Join(placeholders, ", "),
+	), args
+}
+
+func (p *Paginator) getRangeCursorQuery(fields []interface{}) (string, []interface{}) {
+	query := fmt.Sprintf("%s %s= %s AND (%s)", p.tableKeys[0], p.getOperator(p.rules[0]), p.rules[0].placeholder(), p.getCursorQuery())
+	args := append([]interface{}{toQueryArg(fields[0])}, p.getCursorQueryArgs(fields)...)
+	return query, args
+}
+
+func (p *Paginator) hasOperatorsKey() bool {
+	for _, rule := range p.rules {
+		if rule.hasOperators() {
+			return true
+		}
+	}
+	return false
+}
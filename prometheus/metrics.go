@@ -0,0 +1,64 @@
+// Package prometheus implements paginator.Metrics with Prometheus collectors
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "paginator"
+
+// Metrics is a paginator.Metrics backed by Prometheus collectors
+type Metrics struct {
+	pages          prometheus.Counter
+	invalidCursors prometheus.Counter
+	fillRatio      prometheus.Histogram
+	duration       prometheus.Histogram
+}
+
+// NewMetrics creates metrics and registers its collectors to reg
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		pages: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pages_total",
+			Help:      "Total number of pages served.",
+		}),
+		invalidCursors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "invalid_cursors_total",
+			Help:      "Total number of invalid cursors rejected.",
+		}),
+		fillRatio: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "page_fill_ratio",
+			Help:      "Rows of page divided by limit.",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Duration of pagination queries.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.pages, m.invalidCursors, m.fillRatio, m.duration} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObservePage implements paginator.Metrics
+func (m *Metrics) ObservePage(fillRatio float64, duration time.Duration) {
+	m.pages.Inc()
+	m.fillRatio.Observe(fillRatio)
+	m.duration.Observe(duration.Seconds())
+}
+
+// IncInvalidCursors implements paginator.Metrics
+func (m *Metrics) IncInvalidCursors() {
+	m.invalidCursors.Inc()
+}
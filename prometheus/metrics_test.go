@@ -0,0 +1,33 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	paginator "github.com/savvi-ai/gorm-cursor-paginator"
+)
+
+var _ paginator.Metrics = (*Metrics)(nil)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	assert.NoError(t, err)
+
+	m.ObservePage(0.5, time.Millisecond)
+	m.ObservePage(1, time.Millisecond)
+	m.IncInvalidCursors()
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.pages))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.invalidCursors))
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, families, 4)
+
+	_, err = NewMetrics(reg)
+	assert.Error(t, err)
+}
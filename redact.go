@@ -0,0 +1,59 @@
+package paginator
+
+import "fmt"
+
+// SetVerbose sets whether values of cursors are embedded into errors of
+// invalid cursors (see CursorError) and args of LogEntry. Values of keys may
+// be PII such as emails or names, so they are left out by default.
+func (p *Paginator) SetVerbose(enabled bool) {
+	p.verbose = enabled
+}
+
+// CursorError is returned when cursor is malformed or does not match keys,
+// it wraps ErrInvalidCursor
+type CursorError struct {
+	Reason string
+	// Key is key whose value is invalid, empty if cursor is invalid as whole
+	Key string
+	// Value is raw value of Key in cursor, which is only set in verbose mode
+	Value string
+}
+
+func (e *CursorError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %s", ErrInvalidCursor, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidCursor, e.Reason, e.Value)
+}
+
+// Unwrap returns ErrInvalidCursor
+func (e *CursorError) Unwrap() error {
+	return ErrInvalidCursor
+}
+
+// redactArgs returns copy of args of cursor predicate, which are replaced by
+// RedactedArg unless verbose mode is set
+func (p *Paginator) redactArgs(args []interface{}) []interface{} {
+	redacted := make([]interface{}, len(args))
+	for i, arg := range args {
+		if !p.verbose {
+			arg = RedactedArg
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
+
+func invalidCursorError(format string, args ...interface{}) error {
+	return &CursorError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// invalidValueError returns error of invalid raw value of key, the value is
+// only embedded in verbose mode
+func (d *cursorDecoder) invalidValueError(key string, raw []byte, format string) error {
+	err := &CursorError{Reason: fmt.Sprintf(format, key), Key: key}
+	if d.verbose {
+		err.Value = string(raw)
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package paginator
+
+// QueryKind is kind of query executed by paginator
+type QueryKind string
+
+// Query kinds
+const (
+	// QueryPage is the query selecting rows of the page
+	QueryPage QueryKind = "page"
+	// QueryCount is the query counting total rows, see SetTotalCount
+	QueryCount QueryKind = "count"
+	// QueryExists is the query checking more rows, see LookAheadExists
+	QueryExists QueryKind = "exists"
+)
+
+// RouteHook routes query of kind to a connection, e.g. session of a read
+// replica, and returns the routed query. Routing should not change the query
+// itself, so that cursors are unaffected.
+type RouteHook func(query Query, kind QueryKind) (Query, error)
+
+// SetRoute sets hook routing queries to connections, so that read-heavy
+// lists can be served by replicas instead of the primary
+func (p *Paginator) SetRoute(hook RouteHook) {
+	p.router = hook
+}
+
+func (p *Paginator) route(query Query, kind QueryKind) (Query, error) {
+	if p.router == nil {
+		return query, nil
+	}
+	return p.router(query, kind)
+}
@@ -0,0 +1,266 @@
+package paginator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+// Rule for paging key
+type Rule struct {
+	// Key is the struct field name of paging key
+	Key string
+	// CaseInsensitive paginates key by its lower case representation
+	CaseInsensitive bool
+	// Coalesce are struct field names of fallback keys, paging key becomes
+	// COALESCE(key, coalesce...) and cursor takes the first non-NULL value
+	Coalesce []string
+	// SQLRepr is SQL expression of paging key used instead of table column,
+	// value of the expression is expected to be scanned into Key field
+	SQLRepr string
+	// Aggregate indicates SQLRepr is an aggregate expression, cursor
+	// predicate is then placed in HAVING clause
+	Aggregate bool
+	// JSONPath is the path into JSON value of Key field (e.g. a JSONB
+	// column) where the paging value is extracted from. SQL expression
+	// defaults to Postgres text extraction (key #>> '{path}'), set SQLRepr
+	// to apply a cast (e.g. (orders.data->>'score')::numeric).
+	JSONPath []string
+	// Alias indicates Key refers to an alias in SELECT list (snake case of
+	// Key), query is then wrapped as subquery to place cursor predicate on
+	// the outer query since aliases cannot be referred in WHERE clause
+	Alias bool
+	// Subquery is a correlated subquery of paging key, e.g. SELECT
+	// MAX(orders.created_at) FROM orders WHERE orders.user_id = users.id,
+	// which is selected as column of Key so that its value is scanned into
+	// Key field (the query must implement SelectQuery)
+	Subquery string
+	// Column is column name of Key, it defaults to snake case of Key
+	Column string
+	// Order is order of key, which overrides order of paginator (see
+	// SetOrder) so that keys can be ordered in mixed directions
+	Order Order
+	// Nulls is position of NULL values of key in paging order, NULL values
+	// are excluded from the cursor predicate if it is not set
+	Nulls Nulls
+	// Operators overrides comparison operators of key in cursor predicate
+	Operators Operators
+	// Direction decides ORDER BY expression and comparison operator of key
+	// in each order, it defaults to DefaultDirection. Cursor predicate of
+	// keys with direction is built by OR-expansion.
+	Direction Direction `json:"-"`
+	// Zero is how zero values of key are treated in cursors, they are
+	// legitimate values by default
+	Zero ZeroPolicy
+	// Collation is collation key is ordered and compared by, e.g. "C" or
+	// utf8mb4_bin, it is applied by COLLATE to key in ORDER BY and cursor
+	// predicate alike. It marks string keys whose database ordering differs
+	// from Go string comparison (see PaginateShards).
+	Collation string
+	// ArgType is database type cursor values of key are cast to in cursor
+	// predicate, e.g. citext, so that they are compared by operators of the
+	// column type instead of those of the parameter type of driver (text).
+	ArgType string
+	// EncodeValue transforms value of key before it is encoded into cursor,
+	// e.g. mapping enum string to int. It is not included in paginator
+	// state (see EncodeState).
+	EncodeValue func(v interface{}) interface{} `json:"-"`
+	// DecodeValue transforms value decoded from cursor into query argument
+	// of key, it is the inverse of EncodeValue. The value is a generic JSON
+	// value (numbers are json.Number) instead of the type of Key field, and
+	// an error rejects the cursor as invalid.
+	DecodeValue func(v interface{}) (interface{}, error) `json:"-"`
+}
+
+// Operators are comparison operators of key in cursor predicate, empty ones
+// default to <, > and =. They are for keys whose raw < and > are not the
+// right semantics, e.g. custom types with operator classes, paging order of
+// the key should be consistent with them (e.g. by SQLRepr or collation).
+type Operators struct {
+	Less    string
+	Greater string
+	Equal   string
+}
+
+// Nulls position of NULL values in paging order
+type Nulls string
+
+// Nulls positions
+const (
+	NullsFirst Nulls = "FIRST"
+	NullsLast  Nulls = "LAST"
+)
+
+func toRules(keys []string) []Rule {
+	rules := make([]Rule, len(keys))
+	for i, key := range keys {
+		rules[i] = Rule{Key: key}
+	}
+	return rules
+}
+
+// hasOperators reports whether any operator of rule is overridden, either
+// by Operators or Direction
+func (r Rule) hasOperators() bool {
+	return r.Operators != Operators{} || r.Direction != nil
+}
+
+// operator returns comparison operator of rule for default operator op
+func (r Rule) operator(op string) string {
+	var custom string
+	switch op {
+	case "<":
+		custom = r.Operators.Less
+	case ">":
+		custom = r.Operators.Greater
+	case "=":
+		custom = r.Operators.Equal
+	}
+	if custom != "" {
+		return custom
+	}
+	return op
+}
+
+// column returns column name of key
+func (r Rule) column() string {
+	if r.Column != "" {
+		return r.Column
+	}
+	return strcase.ToSnake(r.Key)
+}
+
+func (r Rule) sqlKey(table string, d Dialect) string {
+	sqlKey := d.column(table, r.column())
+	if len(r.JSONPath) > 0 {
+		sqlKey = fmt.Sprintf("%s #>> '{%s}'", sqlKey, strings.Join(r.JSONPath, ","))
+	}
+	if r.Subquery != "" {
+		sqlKey = fmt.Sprintf("(%s)", r.Subquery)
+	}
+	if r.SQLRepr != "" {
+		sqlKey = r.SQLRepr
+	}
+	if len(r.Coalesce) > 0 {
+		sqlKeys := []string{sqlKey}
+		for _, key := range r.Coalesce {
+			sqlKeys = append(sqlKeys, d.column(table, strcase.ToSnake(key)))
+		}
+		sqlKey = fmt.Sprintf("COALESCE(%s)", strings.Join(sqlKeys, ", "))
+	}
+	if r.CaseInsensitive {
+		sqlKey = fmt.Sprintf("LOWER(%s)", sqlKey)
+	}
+	if r.Collation != "" {
+		sqlKey = fmt.Sprintf("%s COLLATE %s", sqlKey, d.collation(r.Collation))
+	}
+	return sqlKey
+}
+
+// placeholder returns placeholder of cursor value of key in cursor predicate
+func (r Rule) placeholder() string {
+	if r.ArgType != "" {
+		return fmt.Sprintf("CAST(? AS %s)", r.ArgType)
+	}
+	return "?"
+}
+
+// dbCompared reports whether key is compared by database in a way which may
+// differ from Go comparison
+func (r Rule) dbCompared() bool {
+	return r.Collation != "" || r.ArgType != "" || r.Direction != nil
+}
+
+// encode returns value of the key from struct rv for encoding into cursor
+func (r Rule) encode(rv reflect.Value) interface{} {
+	return r.encodeValue(r.keyField(rv))
+}
+
+// keyField returns field of the key from struct rv, which is the first
+// non-NULL one of coalesce keys
+func (r Rule) keyField(rv reflect.Value) reflect.Value {
+	field := fieldByName(rv, r.Key)
+	if rv.Kind() == reflect.Map && r.Column != "" {
+		field = fieldByName(rv, r.Column)
+	}
+	for _, key := range r.Coalesce {
+		if !isNullValue(field) {
+			break
+		}
+		field = fieldByName(rv, key)
+	}
+	return field
+}
+
+// encodeValue returns value of field of the key for encoding into cursor
+func (r Rule) encodeValue(field reflect.Value) interface{} {
+	if !field.IsValid() || (r.Zero == ZeroAsNull && isZeroKey(field)) {
+		return nil
+	}
+	var v interface{}
+	if len(r.JSONPath) > 0 {
+		v = extractJSONPath(field, r.JSONPath)
+	} else {
+		v = encodeField(field)
+	}
+	if r.CaseInsensitive {
+		v = toLower(v)
+	}
+	if r.EncodeValue != nil {
+		v = r.EncodeValue(v)
+	}
+	return v
+}
+
+// extractJSONPath extracts value at path from JSON value of field, which is
+// either raw JSON ([]byte, string, json.RawMessage) or any value marshaled
+// into JSON. nil is returned if path is not found.
+func extractJSONPath(field reflect.Value, path []string) interface{} {
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return nil
+	}
+	var raw []byte
+	switch v := reflect.Indirect(field).Interface().(type) {
+	case json.RawMessage:
+		raw = v
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil
+		}
+	}
+	v, err := unmarshalJSONValue(raw)
+	if err != nil {
+		return nil
+	}
+	for _, key := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+func toLower(v interface{}) interface{} {
+	switch s := v.(type) {
+	case string:
+		return strings.ToLower(s)
+	case *string:
+		if s == nil {
+			return s
+		}
+		lower := strings.ToLower(*s)
+		return &lower
+	default:
+		return v
+	}
+}
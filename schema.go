@@ -0,0 +1,19 @@
+package paginator
+
+import "strings"
+
+// SetSchema sets schema which table of query is qualified by, e.g. analytics
+// of multi-schema Postgres databases, keys are then qualified as
+// schema.table.column. Tables already qualified (e.g. Table() of query being
+// analytics.events) and tables set by SetTable are left as they are.
+func (p *Paginator) SetSchema(schema string) {
+	p.schema = schema
+}
+
+// qualifyTable qualifies table of query by schema
+func (p *Paginator) qualifyTable(table string) string {
+	if p.schema == "" || p.wrapped || strings.Contains(table, ".") {
+		return table
+	}
+	return p.schema + "." + table
+}
@@ -0,0 +1,173 @@
+package paginator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+)
+
+// sequenceCursor is position of a sequence cursor, which is cursor of the
+// query the position belongs to
+type sequenceCursor struct {
+	Query  int    `json:"q"`
+	Cursor string `json:"c"`
+}
+
+// sequenceRow is a row of page of query i of sequence
+type sequenceRow struct {
+	query int
+	elem  reflect.Value
+}
+
+// PaginateSequence paginates queries as a single stream into out, a pointer
+// to slice of model, where rows of each query follow all rows of the previous
+// one, e.g. pinned items first and then the rest. Queries are the same model
+// built with their own destinations, and each query is paginated by the
+// paging keys. Next cursor holds the query its position belongs to, so that
+// it is only valid for the same queries in the same order. Like Paginate,
+// queries are paginated by a copy of configuration of paginator.
+func (p *Paginator) PaginateSequence(out interface{}, queries ...Query) error {
+	run := p.newRun()
+	err := run.paginateSequence(out, queries)
+	p.setResult(run)
+	return err
+}
+
+func (p *Paginator) paginateSequence(out interface{}, queries []Query) error {
+	elems := reflect.ValueOf(out)
+	if elems.Kind() != reflect.Ptr || elems.Elem().Kind() != reflect.Slice {
+		return ErrInvalidModel
+	}
+	elems = elems.Elem()
+	p.initOptions()
+	backward := p.hasBeforeCursor()
+	cursor, start := "", 0
+	if backward {
+		cursor, start = *p.cursor.Before, len(queries)-1
+	} else if p.hasAfterCursor() {
+		cursor = *p.cursor.After
+	}
+	var position *string
+	if cursor != "" {
+		c, err := decodeSequenceCursor(cursor, len(queries))
+		if err != nil {
+			return err
+		}
+		start, position = c.Query, &c.Cursor
+	}
+	parts := make([]*Paginator, len(queries))
+	var rows []sequenceRow
+	remaining, hasMore := p.limit, false
+	for i := start; i >= 0 && i < len(queries); i = p.nextInSequence(i) {
+		if remaining == 0 {
+			// look for any row of the rest of queries
+			probe := p.sequencePart(nil, 1)
+			if _, err := probe.paginate(queries[i]); err != nil {
+				return err
+			}
+			if hasMore = probe.count > 0; hasMore {
+				break
+			}
+			continue
+		}
+		sp := p.sequencePart(position, remaining)
+		parts[i], position = sp, nil
+		if _, err := sp.paginate(queries[i]); err != nil {
+			return err
+		}
+		var part []sequenceRow
+		if page := reflect.ValueOf(sp.page); page.Kind() == reflect.Ptr && page.Elem().Kind() == reflect.Slice {
+			for j := 0; j < page.Elem().Len(); j++ {
+				part = append(part, sequenceRow{query: i, elem: page.Elem().Index(j)})
+			}
+		}
+		if backward {
+			rows = append(part, rows...)
+		} else {
+			rows = append(rows, part...)
+		}
+		if !p.unlimited() {
+			remaining -= len(part)
+		}
+		if sp.hasMore {
+			hasMore = true
+			break
+		}
+	}
+	result := reflect.MakeSlice(elems.Type(), 0, len(rows))
+	for _, row := range rows {
+		result = reflect.Append(result, row.elem)
+	}
+	elems.Set(result)
+	p.page, p.count, p.hasMore, p.next = out, len(rows), hasMore, Cursor{}
+	if len(rows) == 0 {
+		return nil
+	}
+	if backward || hasMore {
+		cursor := p.encodeSequenceCursor(parts, rows[len(rows)-1])
+		p.next.After = &cursor
+	}
+	if p.hasAfterCursor() || (hasMore && backward) {
+		cursor := p.encodeSequenceCursor(parts, rows[0])
+		p.next.Before = &cursor
+	}
+	return nil
+}
+
+// nextInSequence returns index of query following query i in paging direction
+func (p *Paginator) nextInSequence(i int) int {
+	if p.hasBeforeCursor() {
+		return i - 1
+	}
+	return i + 1
+}
+
+// sequencePart returns copy of paginator to paginate limit rows of a query
+// of sequence from position, a query is paginated from its start (or its end
+// by before cursor) without position
+func (p *Paginator) sequencePart(position *string, limit int) *Paginator {
+	sp := *p
+	sp.keys = nil
+	sp.tracer, sp.metrics, sp.logger = nil, nil, nil
+	sp.cache, sp.totalCount, sp.skipFlip = nil, false, false
+	sp.cursor, sp.next = Cursor{}, Cursor{}
+	sp.limit = limit
+	if position == nil {
+		position = new(string)
+	}
+	if p.hasBeforeCursor() {
+		sp.cursor.Before = position
+	} else {
+		sp.cursor.After = position
+	}
+	return &sp
+}
+
+// encodeSequenceCursor encodes position of row into sequence cursor by
+// paginator of its query in parts
+func (p *Paginator) encodeSequenceCursor(parts []*Paginator, row sequenceRow) string {
+	b, _ := json.Marshal(sequenceCursor{
+		Query:  row.query,
+		Cursor: parts[row.query].newCursorEncoder().Encode(row.elem),
+	})
+	if p.urlSafe {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeSequenceCursor decodes sequence cursor of n queries
+func decodeSequenceCursor(cursor string, n int) (sequenceCursor, error) {
+	var c sequenceCursor
+	b, err := decodeBase64(cursor)
+	if err != nil {
+		return c, invalidCursorError("cursor is not base64 encoded")
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.Cursor == "" {
+		return c, invalidCursorError("cursor is not a sequence cursor")
+	}
+	if c.Query < 0 || c.Query >= n {
+		return c, invalidCursorError("cursor is of query %d of %d queries", c.Query, n)
+	}
+	return c, nil
+}
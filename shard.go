@@ -0,0 +1,303 @@
+package paginator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors for shards
+var (
+	ErrShardBeforeCursor = errors.New("shards can only be paginated by after cursor")
+	ErrShardKeyType      = errors.New("paging key of shards should be number, string, bool, bytes or time")
+	ErrShardCollation    = errors.New("paging key of shards should not be compared by collation, argument type or direction")
+)
+
+// PaginateShards runs paging query against each of shards, which are the
+// same query on different databases, and merges their pages by paging keys
+// into out, a pointer to slice of model. Next cursor is a composite cursor
+// holding position of each shard, it is only valid for the same shards in the
+// same order and as after cursor. Keys should be unique across shards (e.g.
+// tie-breaker with globally unique IDs), and string keys are merged by byte
+// order regardless of collation of databases, keys marked by Collation or
+// ArgType are therefore rejected.
+func (p *Paginator) PaginateShards(out interface{}, shards ...Query) error {
+	run := p.newRun()
+	err := run.paginateShards(out, shards)
+	p.setResult(run)
+	return err
+}
+
+func (p *Paginator) paginateShards(out interface{}, shards []Query) error {
+	if p.hasBeforeCursor() {
+		return ErrShardBeforeCursor
+	}
+	positions := make([]*string, len(shards))
+	if p.hasAfterCursor() && *p.cursor.After != "" {
+		var err error
+		if positions, err = decodeShardCursor(*p.cursor.After, len(shards)); err != nil {
+			return err
+		}
+	}
+	pages := make([]*Paginator, len(shards))
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		pages[i] = p.shard(positions[i])
+		wg.Add(1)
+		go func(sp *Paginator, shard Query, i int) {
+			defer wg.Done()
+			_, errs[i] = sp.paginate(shard)
+		}(pages[i], shard, i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return p.mergeShards(out, pages, positions)
+}
+
+// shard returns copy of paginator to paginate a shard from position
+func (p *Paginator) shard(position *string) *Paginator {
+	sp := *p
+	sp.keys = nil
+	sp.tracer, sp.metrics, sp.logger = nil, nil, nil
+	sp.cursor, sp.next = Cursor{}, Cursor{}
+	sp.cursor.After = position
+	return &sp
+}
+
+// shardRow is a row of shard page with its encoded key values
+type shardRow struct {
+	shard  int
+	elem   reflect.Value
+	values []interface{}
+}
+
+// mergeShards merges pages of shards into out, positions are updated by last
+// rows of shards in the merged page
+func (p *Paginator) mergeShards(out interface{}, pages []*Paginator, positions []*string) error {
+	elems := reflect.ValueOf(out)
+	if elems.Kind() != reflect.Ptr || elems.Elem().Kind() != reflect.Slice {
+		return ErrInvalidModel
+	}
+	elems = elems.Elem()
+	var rows []shardRow
+	hasMore := false
+	for i, sp := range pages {
+		page := reflect.ValueOf(sp.page)
+		if page.Kind() != reflect.Ptr || page.Elem().Kind() != reflect.Slice {
+			continue
+		}
+		page = page.Elem()
+		for j := 0; j < page.Len(); j++ {
+			elem := reflect.Indirect(page.Index(j))
+			row := shardRow{shard: i, elem: page.Index(j), values: make([]interface{}, len(sp.rules))}
+			for k, rule := range sp.rules {
+				row.values[k] = rule.encode(elem)
+			}
+			rows = append(rows, row)
+		}
+		hasMore = hasMore || sp.hasMore
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+	// rows of each shard are already in order, so that merge is stable sort
+	sp := pages[0]
+	var cmpErr error
+	sort.SliceStable(rows, func(i, j int) bool {
+		c, err := sp.compareShardRows(rows[i].values, rows[j].values)
+		if err != nil && cmpErr == nil {
+			cmpErr = err
+		}
+		return c < 0
+	})
+	if cmpErr != nil {
+		return cmpErr
+	}
+	if !sp.unlimited() && len(rows) > sp.limit {
+		rows, hasMore = rows[:sp.limit], true
+	}
+	result := reflect.MakeSlice(elems.Type(), 0, len(rows))
+	for _, row := range rows {
+		result = reflect.Append(result, row.elem)
+		cursor := pages[row.shard].newCursorEncoder().Encode(row.elem)
+		positions[row.shard] = &cursor
+	}
+	elems.Set(result)
+	p.page, p.count, p.hasMore = out, len(rows), hasMore
+	p.next = Cursor{}
+	if hasMore {
+		cursor := p.encodeShardCursor(positions)
+		p.next.After = &cursor
+	}
+	return nil
+}
+
+// compareShardRows compares key values of rows in paging order
+func (p *Paginator) compareShardRows(a, b []interface{}) (int, error) {
+	for i, rule := range p.rules {
+		av, bv := derefKeyValue(a[i]), derefKeyValue(b[i])
+		aNull, bNull := !av.IsValid(), !bv.IsValid()
+		if aNull && bNull {
+			continue
+		}
+		// NULL values are the least unless positioned by Nulls
+		c := 1
+		if aNull {
+			c = -1
+		}
+		if aNull == bNull {
+			if rule.dbCompared() {
+				return 0, fmt.Errorf("%w: %s", ErrShardCollation, rule.Key)
+			}
+			var err error
+			if c, err = compareKeyValues(av, bv); err != nil {
+				return 0, err
+			}
+		} else if rule.Nulls != "" {
+			if rule.Nulls == NullsLast {
+				c = -c
+			}
+			return c, nil
+		}
+		if c != 0 {
+			if p.getKeyOrder(rule) == DESC {
+				c = -c
+			}
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+// derefKeyValue returns value of encoded key value v, which is invalid if v is
+// NULL
+func derefKeyValue(v interface{}) reflect.Value {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// compareKeyValues compares non-NULL encoded key values
+func compareKeyValues(av, bv reflect.Value) (int, error) {
+	if at, ok := av.Interface().(time.Time); ok {
+		if bt, ok := bv.Interface().(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1, nil
+			case at.After(bt):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	if ab, ok := av.Interface().([]byte); ok {
+		if bb, ok := bv.Interface().([]byte); ok {
+			return bytes.Compare(ab, bb), nil
+		}
+	}
+	// NaN is greater than any other number and equal to NaN, as ordered by
+	// Postgres, and it has no big.Float
+	if aNaN, bNaN := isNaN(av), isNaN(bv); aNaN || bNaN {
+		if _, ok := toBigFloat(av); !ok && !aNaN {
+			return 0, fmt.Errorf("%w: %s", ErrShardKeyType, av.Type())
+		}
+		if _, ok := toBigFloat(bv); !ok && !bNaN {
+			return 0, fmt.Errorf("%w: %s", ErrShardKeyType, bv.Type())
+		}
+		switch {
+		case aNaN && bNaN:
+			return 0, nil
+		case aNaN:
+			return 1, nil
+		}
+		return -1, nil
+	}
+	if an, ok := toBigFloat(av); ok {
+		if bn, ok := toBigFloat(bv); ok {
+			return an.Cmp(bn), nil
+		}
+	}
+	switch {
+	case av.Kind() == reflect.String && bv.Kind() == reflect.String:
+		return strings.Compare(av.String(), bv.String()), nil
+	case av.Kind() == reflect.Bool && bv.Kind() == reflect.Bool:
+		if av.Bool() == bv.Bool() {
+			return 0, nil
+		}
+		if bv.Bool() {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	return 0, fmt.Errorf("%w: %s", ErrShardKeyType, av.Type())
+}
+
+// isNaN reports whether v is a float NaN
+func isNaN(v reflect.Value) bool {
+	return (v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64) && math.IsNaN(v.Float())
+}
+
+// toBigFloat converts numeric value to big.Float losslessly, NaN is not
+// converted
+func toBigFloat(v reflect.Value) (*big.Float, bool) {
+	if n, ok := v.Interface().(json.Number); ok {
+		f, _, err := big.ParseFloat(n.String(), 10, 256, big.ToNearestEven)
+		return f, err == nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Float).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Float).SetUint64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) {
+			return nil, false
+		}
+		return big.NewFloat(v.Float()), true
+	}
+	return nil, false
+}
+
+// encodeShardCursor encodes positions of shards into composite cursor
+func (p *Paginator) encodeShardCursor(positions []*string) string {
+	b, _ := json.Marshal(positions)
+	if p.urlSafe {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// decodeShardCursor decodes composite cursor into positions of n shards
+func decodeShardCursor(cursor string, n int) ([]*string, error) {
+	b, err := decodeBase64(cursor)
+	if err != nil {
+		return nil, invalidCursorError("cursor is not base64 encoded")
+	}
+	var positions []*string
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return nil, invalidCursorError("cursor is not a shard cursor")
+	}
+	if len(positions) != n {
+		return nil, invalidCursorError("cursor has %d positions for %d shards", len(positions), n)
+	}
+	return positions, nil
+}
@@ -0,0 +1,55 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSort is returned when sort expression refers to a field which is
+// not allowed, or refers to a field more than once
+var ErrInvalidSort = errors.New("invalid sort")
+
+// ParseSort parses REST-style sort expression, e.g. "-created_at,+id", into
+// rules of paging keys. Fields maps names allowed in the expression to
+// struct field names of keys, e.g. {"created_at": "CreatedAt"}. Names
+// prefixed by - are descending, others (optionally prefixed by +, which may
+// arrive as space from query strings) are ascending.
+func ParseSort(sort string, fields map[string]string) ([]Rule, error) {
+	var rules []Rule
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(sort, ",") {
+		name, order := strings.TrimSpace(part), ASC
+		if name == "" {
+			continue
+		}
+		switch name[0] {
+		case '-':
+			name, order = name[1:], DESC
+		case '+':
+			name = name[1:]
+		}
+		key, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not a sortable field", ErrInvalidSort, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is sorted more than once", ErrInvalidSort, name)
+		}
+		seen[name] = true
+		rules = append(rules, Rule{Key: key, Order: order})
+	}
+	return rules, nil
+}
+
+// SetSort sets paging keys and their orders by sort expression (see
+// ParseSort), so that ?sort=-created_at,id can be wired into paginator.
+// Tie breaker (see SetTieBreaker) follows order of paginator.
+func (p *Paginator) SetSort(sort string, fields map[string]string) error {
+	rules, err := ParseSort(sort, fields)
+	if err != nil {
+		return err
+	}
+	p.SetRules(rules...)
+	return nil
+}
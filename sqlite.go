@@ -0,0 +1,46 @@
+package paginator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SQLiteVersionSQL queries version of SQLite, which can be passed to
+// SetSQLiteVersion
+const SQLiteVersionSQL = "SELECT sqlite_version()"
+
+// SetSQLiteVersion sets version of SQLite (e.g. "3.31.1", see
+// SQLiteVersionSQL) with SQLite dialect, which decides SQL features used in
+// queries. Keys are compared as row value by default since 3.15, which
+// supports row values, and never before it. NULL positions of keys are
+// ordered by IS NULL expressions, which do not require NULLS FIRST and
+// NULLS LAST of 3.30.
+func (p *Paginator) SetSQLiteVersion(version string) {
+	p.sqliteVersion = version
+}
+
+// supportsRowValues reports whether keys can be compared as row value
+func (p *Paginator) supportsRowValues() bool {
+	if p.dialect != SQLite {
+		return true
+	}
+	return versionAtLeast(p.sqliteVersion, 3, 15)
+}
+
+// versionAtLeast reports whether version (e.g. "3.31.1") is at least
+// major.minor, unknown version is not
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	ma, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	mi, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return ma > major || ma == major && mi >= minor
+}
@@ -0,0 +1,183 @@
+package paginator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSQLite(t *testing.T) {
+	suite.Run(t, &sqliteSuite{})
+}
+
+/* suite */
+
+type sqliteSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	version string
+}
+
+/* suite setup */
+
+func (s *sqliteSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+	conn, err := db.DB()
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+	// every connection has its own in-memory database
+	conn.SetMaxOpenConns(1)
+	s.Nil(conn.QueryRow(SQLiteVersionSQL).Scan(&s.version))
+	s.db = db
+	s.Nil(s.db.AutoMigrate(&order{}))
+}
+
+func (s *sqliteSuite) TearDownTest() {
+	if conn, err := s.db.DB(); err == nil {
+		conn.Close()
+	}
+}
+
+/* suite test cases */
+
+func (s *sqliteSuite) TestPaginatePredicates() {
+	var orders []order
+	for _, name := range []string{"A", "A", "B", "B", "C"} {
+		name := name
+		orders = append(orders, order{Name: &name})
+	}
+	s.givenCustomOrders(orders)
+	for _, predicate := range []Predicate{"", PredicateOR, PredicateTuple, PredicateRange} {
+		newPaginator := func() *Paginator {
+			p := New()
+			p.SetDialect(SQLite)
+			p.SetSQLiteVersion(s.version)
+			p.SetPredicate(predicate)
+			p.SetKeys("Name", "ID")
+			p.SetLimit(2)
+			return p
+		}
+		var o1 []order
+		cursor := s.paginateBy(newPaginator(), &o1)
+		s.Equal([]int{5, 4}, orderIDs(o1))
+
+		var o2 []order
+		p := newPaginator()
+		p.SetAfterCursor(*cursor.After)
+		cursor = s.paginateBy(p, &o2)
+		s.Equal([]int{3, 2}, orderIDs(o2))
+
+		var o3 []order
+		p = newPaginator()
+		p.SetBeforeCursor(*cursor.Before)
+		s.paginateBy(p, &o3)
+		s.Equal(orderIDs(o1), orderIDs(o3))
+	}
+}
+
+func (s *sqliteSuite) TestPaginateNulls() {
+	name := "B"
+	other := "A"
+	s.givenCustomOrders([]order{{Name: &name}, {}, {Name: &other}, {}})
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetDialect(SQLite)
+		p.SetRules(Rule{Key: "Name", Nulls: NullsLast}, Rule{Key: "ID"})
+		p.SetOrder(ASC)
+		p.SetLimit(2)
+		return p
+	}
+	var o1 []order
+	cursor := s.paginateBy(newPaginator(), &o1)
+	s.Equal([]int{3, 1}, orderIDs(o1))
+
+	var o2 []order
+	p := newPaginator()
+	p.SetAfterCursor(*cursor.After)
+	cursor = s.paginateBy(p, &o2)
+	s.Equal([]int{2, 4}, orderIDs(o2))
+
+	var o3 []order
+	p = newPaginator()
+	p.SetBeforeCursor(*cursor.Before)
+	s.paginateBy(p, &o3)
+	s.Equal([]int{3, 1}, orderIDs(o3))
+}
+
+func (s *sqliteSuite) TestPaginateBoolKey() {
+	type post struct {
+		ID     int `gorm:"primary_key"`
+		Pinned bool
+	}
+	s.Nil(s.db.AutoMigrate(&post{}))
+	rows := []post{{Pinned: false}, {Pinned: true}, {Pinned: false}, {Pinned: true}}
+	s.Nil(s.db.Create(&rows).Error)
+
+	newPaginator := func() *Paginator {
+		p := New()
+		p.SetDialect(SQLite)
+		p.SetSQLiteVersion(s.version)
+		p.SetKeys("Pinned", "ID")
+		p.SetLimit(2)
+		return p
+	}
+	var p1 []post
+	result, err := newPaginator().Paginate(newGormQuery(s.db, &p1))
+	s.Nil(err)
+	s.Nil(result.(*gormQuery).db.Error)
+	s.Equal([]post{{4, true}, {2, true}}, p1)
+
+	var p2 []post
+	p := newPaginator()
+	p.SetAfterCursor(NewCursorEncoder("Pinned", "ID").Encode(p1[1]))
+	_, err = p.Paginate(newGormQuery(s.db, &p2))
+	s.Nil(err)
+	s.Equal([]post{{3, false}, {1, false}}, p2)
+}
+
+func (s *sqliteSuite) TestRowValuesByVersion() {
+	for _, c := range []struct {
+		version string
+		where   string
+	}{
+		{"3.31.1", `("orders"."name", "orders"."id") < (?, ?)`},
+		{"3.8.2", `"orders"."name" < ? OR "orders"."name" = ? AND "orders"."id" < ?`},
+		{"", `"orders"."name" < ? OR "orders"."name" = ? AND "orders"."id" < ?`},
+	} {
+		p := New()
+		p.SetDialect(SQLite)
+		p.SetSQLiteVersion(c.version)
+		p.SetKeys("Name", "ID")
+		p.SetAfterCursor(NewCursorEncoder("Name", "ID").Encode(order{ID: 1}))
+		where, _, _, _ := p.BuildSQL(&order{})
+		s.Equal(c.where, where, c.version)
+	}
+}
+
+/* util */
+
+func (s *sqliteSuite) givenCustomOrders(orders []order) []order {
+	for i := range orders {
+		if err := s.db.Create(&orders[i]).Error; err != nil {
+			s.FailNow(err.Error())
+		}
+	}
+	return orders
+}
+
+func (s *sqliteSuite) paginateBy(p *Paginator, out *[]order) Cursor {
+	result, err := p.Paginate(newGormQuery(s.db, out))
+	if err != nil {
+		s.FailNow(err.Error())
+	}
+	if err := result.(*gormQuery).db.Error; err != nil {
+		s.FailNow(err.Error())
+	}
+	return p.GetNextCursor()
+}
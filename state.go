@@ -0,0 +1,89 @@
+package paginator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// stateVersion is version of state token, which covers cursor codec
+const stateVersion = 1
+
+// ErrInvalidState is returned when state token cannot be decoded
+var ErrInvalidState = errors.New("invalid paginator state")
+
+// state is serializable state of paginator
+type state struct {
+	Version     int      `json:"v"`
+	Rules       []Rule   `json:"rules,omitempty"`
+	Order       Order    `json:"order,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+	TieBreaker  bool     `json:"tie_breaker,omitempty"`
+	DistinctOn  []string `json:"distinct_on,omitempty"`
+	After       *string  `json:"after,omitempty"`
+	Before      *string  `json:"before,omitempty"`
+	URLSafe     bool     `json:"url_safe,omitempty"`
+	Compression bool     `json:"compression,omitempty"`
+	Compact     bool     `json:"compact,omitempty"`
+	// AsOf and Snapshot are of historical reads
+	AsOf     time.Duration `json:"as_of,omitempty"`
+	Snapshot *time.Time    `json:"snapshot,omitempty"`
+}
+
+// EncodeState encodes keys, order, limit, cursor, cursor codec options and
+// snapshot of historical reads (see SetAsOfSystemTime) of paginator into a
+// resumable token, e.g. a checkpoint of background job walking a large table
+// after setting next cursor. Other options such as hooks and observers are
+// not included and should be set again on resume.
+func (p *Paginator) EncodeState() string {
+	// snapshot of the paginated page is carried to resumed pages
+	var snapshot *time.Time
+	if t := p.GetSnapshotTime(); !t.IsZero() {
+		snapshot = &t
+	}
+	b, _ := json.Marshal(state{
+		Version:     stateVersion,
+		Rules:       p.rules,
+		Order:       p.order,
+		Limit:       p.limit,
+		TieBreaker:  p.tieBreaker,
+		DistinctOn:  p.distinctOn,
+		After:       p.cursor.After,
+		Before:      p.cursor.Before,
+		URLSafe:     p.urlSafe,
+		Compression: p.compression,
+		Compact:     p.compact,
+		AsOf:        p.asOf,
+		Snapshot:    snapshot,
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// NewFromState creates paginator from token encoded by EncodeState,
+// ErrInvalidState is returned if token is malformed or of another version
+func NewFromState(token string) (*Paginator, error) {
+	b, err := decodeBase64(token)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	var s state
+	if err := json.Unmarshal(b, &s); err != nil || s.Version != stateVersion {
+		return nil, ErrInvalidState
+	}
+	p := New()
+	p.rules = s.Rules
+	p.order = s.Order
+	p.limit = s.Limit
+	p.tieBreaker = s.TieBreaker
+	p.distinctOn = s.DistinctOn
+	p.cursor = Cursor{After: s.After, Before: s.Before}
+	p.urlSafe = s.URLSafe
+	p.compression = s.Compression
+	p.compact = s.Compact
+	p.asOf = s.AsOf
+	if s.Snapshot != nil {
+		p.snapshot = *s.Snapshot
+	}
+	return p, nil
+}
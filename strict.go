@@ -0,0 +1,22 @@
+package paginator
+
+import "errors"
+
+// ErrCursorConflict is returned in strict mode when both after and before
+// cursors are set
+var ErrCursorConflict = errors.New("after and before cursors should not be both set")
+
+// SetStrictCursors sets whether to reject paginating with both after and
+// before cursors by ErrCursorConflict. Otherwise before cursor is ignored
+// when after cursor is set.
+func (p *Paginator) SetStrictCursors(enabled bool) {
+	p.strict = enabled
+}
+
+// validateCursors validates cursors against strict mode
+func (p *Paginator) validateCursors() error {
+	if p.strict && p.cursor.After != nil && p.cursor.Before != nil {
+		return ErrCursorConflict
+	}
+	return nil
+}
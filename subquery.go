@@ -0,0 +1,32 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSubqueryKeyNotSupported is returned when query of subquery keys cannot
+// select them
+var ErrSubqueryKeyNotSupported = errors.New("query should implement SelectQuery to paginate by subquery keys")
+
+// appendSubqueryKeys selects subquery keys as columns of their keys, all
+// columns of table are selected along with them if query selects all columns
+func (p *Paginator) appendSubqueryKeys(query Query) (Query, error) {
+	var selects []string
+	for _, rule := range append(p.rules, p.orderRules...) {
+		if rule.Subquery != "" {
+			selects = append(selects, fmt.Sprintf("(%s) AS %s", rule.Subquery, rule.column()))
+		}
+	}
+	if len(selects) == 0 {
+		return query, nil
+	}
+	sq, ok := query.(SelectQuery)
+	if !ok {
+		return query, ErrSubqueryKeyNotSupported
+	}
+	if len(sq.Selects()) == 0 {
+		selects = append([]string{p.getTable(query) + ".*"}, selects...)
+	}
+	return sq.AddSelects(selects...), nil
+}
@@ -0,0 +1,66 @@
+package paginator
+
+import (
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag declaring default paging keys of model, e.g.
+// `paginator:"order:desc;column:published_at;null:last"`
+const tagName = "paginator"
+
+// taggedRules returns rules of fields tagged by paginator in declaration
+// order, embedded structs are included. The order option of the first field
+// declaring it is returned as default order.
+func taggedRules(rt reflect.Type) (rules []Rule, order Order) {
+	if rt.Kind() != reflect.Struct {
+		return nil, ""
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				embedded, embeddedOrder := taggedRules(field.Type)
+				rules = append(rules, embedded...)
+				if order == "" {
+					order = embeddedOrder
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+		rule := Rule{Key: field.Name}
+		for _, option := range strings.Split(tag, ";") {
+			name, value := parseTagOption(option)
+			switch name {
+			case "order":
+				if o := Order(strings.ToUpper(value)); (o == ASC || o == DESC) && order == "" {
+					order = o
+				}
+			case "column":
+				rule.Column = value
+			case "null":
+				switch strings.ToUpper(value) {
+				case string(NullsFirst):
+					rule.Nulls = NullsFirst
+				case string(NullsLast):
+					rule.Nulls = NullsLast
+				}
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return
+}
+
+func parseTagOption(option string) (name, value string) {
+	parts := strings.SplitN(option, ":", 2)
+	name = strings.ToLower(strings.TrimSpace(parts[0]))
+	if len(parts) == 2 {
+		value = strings.TrimSpace(parts[1])
+	}
+	return
+}
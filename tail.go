@@ -0,0 +1,23 @@
+package paginator
+
+// TailAfter sets tail mode polling rows arriving after cursor, e.g. for
+// notification and activity feeds. Rows are in ascending order, primary key
+// breaks ties of equal keys (see SetTieBreaker) so that rows with equal
+// timestamps are neither skipped nor repeated, and next after cursor is
+// always set, which is cursor of the last row or the cursor itself if there
+// is no new row. Empty cursor tails from the first row.
+func (p *Paginator) TailAfter(cursor string) {
+	p.SetOrder(ASC)
+	p.SetTieBreaker(true)
+	p.SetAfterCursor(cursor)
+	p.tail = true
+}
+
+// initTailCursor keeps the incoming cursor as next after cursor of empty
+// page in tail mode
+func (p *Paginator) initTailCursor() {
+	if p.tail && p.next.After == nil && p.count == 0 && p.hasAfterCursor() {
+		cursor := *p.cursor.After
+		p.next.After = &cursor
+	}
+}
@@ -0,0 +1,68 @@
+package paginator
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// compactMarker leads compact cursor payload of time-ordered key, which is
+// raw bytes of the key value, it never leads JSON or old encoding
+const compactMarker byte = 0x02
+
+// SetTimeOrderedKey sets the lone paging key to a time-ordered identifier,
+// such as UUIDv7, ULID or KSUID, which is unique and ordered by creation time
+// so that it gives stable total ordering without tie-breaker. Binary IDs
+// (byte arrays or slices) and string IDs are encoded compactly by their raw bytes
+// unless fingerprint or TTL is enabled.
+func (p *Paginator) SetTimeOrderedKey(key string) {
+	p.rules = []Rule{{Key: key}}
+	p.tieBreaker = false
+	p.compact = true
+}
+
+// encodeCompact returns compact payload of key value v, ok is false if v is
+// neither bytes nor string
+func encodeCompact(v interface{}) ([]byte, bool) {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte{compactMarker}, v...), true
+	case string:
+		return append([]byte{compactMarker}, v...), true
+	case *string:
+		if v != nil {
+			return append([]byte{compactMarker}, *v...), true
+		}
+	}
+	return nil, false
+}
+
+// decodeCompact converts compact payload b into JSON array of key value, key
+// values of map rows are decoded as string since drivers may scan strings of
+// map rows as bytes
+func (d *cursorDecoder) decodeCompact(b []byte) ([]byte, error) {
+	if len(d.rules) != 1 || d.fingerprint != "" || d.ttl > 0 {
+		return nil, invalidCursorError("cursor is not expected to be compact")
+	}
+	raw := b[1:]
+	if isMapType(d.ref) {
+		return json.Marshal([]interface{}{string(raw)})
+	}
+	if field, ok := d.ref.FieldByName(d.rules[0].Key); ok && (isByteArray(field.Type) || isByteSlice(field.Type)) {
+		return json.Marshal([]interface{}{raw})
+	}
+	return json.Marshal([]interface{}{string(raw)})
+}
+
+// isCompact reports whether payload b is compact
+func isCompact(b []byte) bool {
+	return len(b) > 0 && b[0] == compactMarker
+}
+
+// reduceValue reduces value of row to underlying struct or map
+func reduceValue(value interface{}) reflect.Value {
+	rv := toReflectValue(value)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	return rv
+}
@@ -0,0 +1,48 @@
+package paginator
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ContextQuery is a Query bound to a context, which is required by query
+// timeout
+type ContextQuery interface {
+	Query
+	// Context returns context of the query
+	Context() context.Context
+	// WithContext returns a new query bound to ctx
+	WithContext(ctx context.Context) Query
+}
+
+// ErrContextNotSupported is returned when query does not implement
+// ContextQuery with query timeout set
+var ErrContextNotSupported = errors.New("query should implement ContextQuery to set query timeout")
+
+// SetQueryTimeout sets timeout of queries of a page, i.e. page, count and
+// look-ahead queries, which are bound to a deadline derived from context of
+// query regardless of deadline of the request, so that a pathological cursor
+// cannot hold a connection indefinitely. Timed out queries fail by the
+// context error reported by driver (e.g. context.DeadlineExceeded).
+func (p *Paginator) SetQueryTimeout(d time.Duration) {
+	p.queryTimeout = d
+}
+
+// withQueryTimeout binds query to deadline of query timeout, cancel releases
+// the deadline once queries of the page are done
+func (p *Paginator) withQueryTimeout(query Query) (Query, context.CancelFunc, error) {
+	if p.queryTimeout <= 0 {
+		return query, func() {}, nil
+	}
+	cq, ok := query.(ContextQuery)
+	if !ok {
+		return query, func() {}, ErrContextNotSupported
+	}
+	ctx := cq.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout)
+	return cq.WithContext(ctx), cancel, nil
+}
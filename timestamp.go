@@ -0,0 +1,72 @@
+package paginator
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// timestamp is implemented by protobuf timestamps (timestamppb.Timestamp)
+type timestamp interface {
+	GetSeconds() int64
+	GetNanos() int32
+}
+
+var timestampType = reflect.TypeOf((*timestamp)(nil)).Elem()
+
+// isTimestamp reports whether t (or pointer to t) is a protobuf timestamp,
+// which is a struct with Seconds and Nanos fields and their getters. It is
+// detected by shape so that protobuf is not required as a dependency.
+func isTimestamp(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || !reflect.PtrTo(t).Implements(timestampType) {
+		return false
+	}
+	seconds, ok := t.FieldByName("Seconds")
+	if !ok || seconds.Type.Kind() != reflect.Int64 {
+		return false
+	}
+	nanos, ok := t.FieldByName("Nanos")
+	return ok && nanos.Type.Kind() == reflect.Int32
+}
+
+// timestampToTime converts protobuf timestamp v to time in UTC, nil is
+// returned for nil timestamp, ok is false if v is not a timestamp
+func timestampToTime(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !isTimestamp(rv.Type()) {
+		return nil, false
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, true
+		}
+		rv = rv.Elem()
+	}
+	ts := toAddressable(rv).Addr().Interface().(timestamp)
+	return time.Unix(ts.GetSeconds(), int64(ts.GetNanos())).UTC(), true
+}
+
+// decodeTimestamp decodes next time from dec into a new protobuf timestamp
+// of type t, the returned value is a pointer to t when isPtr is true.
+func decodeTimestamp(dec *json.Decoder, t reflect.Type, isPtr bool) (interface{}, bool) {
+	var tm *time.Time
+	if err := dec.Decode(&tm); err != nil {
+		return nil, false
+	}
+	if tm == nil {
+		if !isPtr {
+			return nil, false
+		}
+		return reflect.Zero(reflect.PtrTo(t)).Interface(), true
+	}
+	pv := reflect.New(t)
+	pv.Elem().FieldByName("Seconds").SetInt(tm.Unix())
+	pv.Elem().FieldByName("Nanos").SetInt(int64(tm.Nanosecond()))
+	if isPtr {
+		return pv.Interface(), true
+	}
+	return pv.Elem().Interface(), true
+}
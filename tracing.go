@@ -0,0 +1,75 @@
+package paginator
+
+const spanName = "paginator.Paginate"
+
+// Tracer starts a span for each Paginate call, it is the extension point for
+// tracing libraries such as OpenTelemetry
+type Tracer interface {
+	Start(name string) Span
+}
+
+// Span is a span started by Tracer
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair recorded on span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attribute keys of Paginate span
+const (
+	AttributeKeys        = "paginator.keys"
+	AttributeLimit       = "paginator.limit"
+	AttributeOrder       = "paginator.order"
+	AttributeDirection   = "paginator.direction"
+	AttributeResultCount = "paginator.result_count"
+	AttributeHasMore     = "paginator.has_more"
+)
+
+// SetTracer sets tracer starting a span for each Paginate call
+func (p *Paginator) SetTracer(tracer Tracer) {
+	p.tracer = tracer
+}
+
+func (p *Paginator) startSpan() Span {
+	if p.tracer == nil {
+		return nil
+	}
+	return p.tracer.Start(spanName)
+}
+
+func (p *Paginator) endSpan(span Span, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(
+		Attribute{Key: AttributeKeys, Value: append([]string(nil), p.keys...)},
+		Attribute{Key: AttributeLimit, Value: p.limit},
+		Attribute{Key: AttributeOrder, Value: string(p.order)},
+		Attribute{Key: AttributeDirection, Value: p.direction()},
+		Attribute{Key: AttributeResultCount, Value: p.count},
+		Attribute{Key: AttributeHasMore, Value: p.hasMore},
+	)
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// direction returns paging direction, which is "after", "before" or "none"
+// for the first page
+func (p *Paginator) direction() string {
+	switch {
+	case p.hasAfterCursor():
+		return "after"
+	case p.hasBeforeCursor():
+		return "before"
+	default:
+		return "none"
+	}
+}
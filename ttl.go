@@ -0,0 +1,23 @@
+package paginator
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCursorExpired is returned when cursor is issued earlier than cursor TTL
+var ErrCursorExpired = errors.New("cursor expired")
+
+// SetCursorTTL sets TTL of cursors, issued-at time is embedded into cursors
+// and cursors older than ttl are rejected by ErrCursorExpired. Enabling TTL
+// invalidates cursors issued without it, and vice versa.
+func (p *Paginator) SetCursorTTL(ttl time.Duration) {
+	p.cursorTTL = ttl
+}
+
+func (p *Paginator) getNow() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,85 @@
+package paginator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TxBeginner begins transactions which pages are walked in, e.g. adapter of
+// *sql.DB or *gorm.DB
+type TxBeginner interface {
+	// BeginTx begins a transaction of opts
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+}
+
+// Tx is a transaction begun by TxBeginner, e.g. adapter of *sql.Tx or
+// *gorm.DB in a transaction
+type Tx interface {
+	// Exec executes statement, e.g. SAVEPOINT, in the transaction
+	Exec(statement string) error
+	// Commit commits the transaction
+	Commit() error
+	// Rollback aborts the transaction
+	Rollback() error
+}
+
+// TxOptions are options of transaction of EachPageInTx
+type TxOptions struct {
+	// Isolation is isolation level of the transaction, default is repeatable
+	// read so that all pages are read from the same snapshot, or serializable
+	// on Oracle, which does not support repeatable read
+	Isolation sql.IsolationLevel
+	// Savepoints sets whether to create a savepoint before fn of each page,
+	// when fn fails, work of the page is rolled back to its savepoint while
+	// work of previous pages is committed
+	Savepoints bool
+}
+
+// EachPageInTx walks pages as EachPage does inside a single transaction begun
+// by db, which gives reconciliation jobs consistent snapshots across pages
+// without plumbing the transaction. Query builds query of each page on tx, and
+// fn is called with tx and each page. The transaction is committed when the
+// walk completes, and rolled back on errors unless fn fails with savepoints
+// (see TxOptions), the error of fn is then returned after commit.
+func (p *Paginator) EachPageInTx(ctx context.Context, db TxBeginner, opts TxOptions, query func(tx Tx) Query, fn func(tx Tx, page interface{}) error) error {
+	isolation := opts.Isolation
+	if isolation == sql.LevelDefault {
+		isolation = p.dialect.snapshotIsolation()
+	}
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: isolation})
+	if err != nil {
+		return err
+	}
+	savepoint, rollbackTo, release := p.dialect.savepointStatements()
+	// failed is error of fn rolled back to savepoint
+	var failed error
+	n := 0
+	err = p.EachPage(func() Query { return query(tx) }, func(page interface{}) error {
+		n++
+		name := fmt.Sprintf("paginator_page_%d", n)
+		if opts.Savepoints {
+			if err := tx.Exec(savepoint + " " + name); err != nil {
+				return err
+			}
+		}
+		if err := fn(tx, page); err != nil {
+			if opts.Savepoints && tx.Exec(rollbackTo+" "+name) == nil {
+				failed = err
+			}
+			return err
+		}
+		if opts.Savepoints && release != "" {
+			return tx.Exec(release + " " + name)
+		}
+		return nil
+	})
+	if err != nil && failed == nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return failed
+}
@@ -0,0 +1,40 @@
+package paginator
+
+import "errors"
+
+// UnionQuery is a Query which can be combined with other queries by UNION
+// ALL, which is required for union mode
+type UnionQuery interface {
+	Query
+	// UnionAll returns a new query selecting all columns from UNION ALL of
+	// this query and others as subquery aliased by alias
+	UnionAll(alias string, others ...Query) Query
+}
+
+// ErrUnionNotSupported is returned when query does not implement UnionQuery in
+// union mode
+var ErrUnionNotSupported = errors.New("query should implement UnionQuery to paginate UNION ALL queries")
+
+const unionAlias = "paginator_union"
+
+// SetUnionAll sets queries to be combined with the paginated query by UNION
+// ALL, cursor predicate and ordering are then placed on the outer query
+// selecting from the union. Key columns are not added to the selection since
+// all queries must select the same columns, so every query must select them.
+func (p *Paginator) SetUnionAll(queries ...Query) {
+	p.union = append(p.union, queries...)
+}
+
+func (p *Paginator) appendUnion(query Query) (Query, error) {
+	if len(p.union) == 0 {
+		return query, nil
+	}
+	uq, ok := query.(UnionQuery)
+	if !ok {
+		return query, ErrUnionNotSupported
+	}
+	query = uq.UnionAll(unionAlias, p.union...)
+	p.wrapped = true
+	p.initTableKeys(query)
+	return query, nil
+}
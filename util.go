@@ -1,12 +1,20 @@
 package paginator
 
 import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/iancoleman/strcase"
 )
 
 var (
@@ -26,10 +34,11 @@ func Decode(cursor string) []interface{} {
 	if err != nil {
 		return nil
 	}
-	var fields []interface{}
-	err = json.Unmarshal(b, &fields)
-	if err != nil {
-		return decodeOld(b)
+	// numbers are kept as json.Number so that large integers are not rounded
+	v, err := unmarshalJSONValue(b)
+	fields, ok := v.([]interface{})
+	if err != nil || !ok {
+		return decodeOld(b, nil)
 	}
 	// ensure forward compatibility
 	for i, field := range fields {
@@ -46,6 +55,16 @@ func Decode(cursor string) []interface{} {
 
 /* util */
 
+// decodeBase64 decodes both standard and URL-safe base64 encoding, with or
+// without padding
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "+/") {
+		return base64.RawStdEncoding.DecodeString(s)
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
 func toReflectValue(value interface{}) reflect.Value {
 	rv, ok := value.(reflect.Value)
 	if !ok {
@@ -53,3 +72,253 @@ func toReflectValue(value interface{}) reflect.Value {
 	}
 	return rv
 }
+
+// toStructType reduces type of value to underlying struct type
+func toStructType(value interface{}) (reflect.Type, error) {
+	rt := toReflectValue(value).Type()
+	for rt.Kind() == reflect.Slice || rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, ErrInvalidModel
+	}
+	return rt, nil
+}
+
+// toModelType reduces type of value to underlying struct type, or map type
+// with string keys for map destinations
+func toModelType(value interface{}) (reflect.Type, error) {
+	rt := toReflectValue(value).Type()
+	for rt.Kind() == reflect.Slice || rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if isMapType(rt) {
+		return rt, nil
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil, ErrInvalidModel
+	}
+	return rt, nil
+}
+
+func isMapType(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Map && rt.Key().Kind() == reflect.String
+}
+
+var nilInterfacePtr = reflect.Zero(reflect.TypeOf((*interface{})(nil)))
+
+// fieldByName returns field key of struct value rv, or entry of map value rv
+// keyed by column (snake case) of key or key itself. Missing and NULL entries
+// are returned as nil pointer, raw bytes entries are returned as strings.
+func fieldByName(rv reflect.Value, key string) reflect.Value {
+	if rv.Kind() != reflect.Map {
+		return rv.FieldByName(key)
+	}
+	for _, k := range []string{strcase.ToSnake(key), key} {
+		v := rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			continue
+		}
+		if v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nilInterfacePtr
+			}
+			v = v.Elem()
+		}
+		if b, ok := v.Interface().([]byte); ok {
+			return reflect.ValueOf(string(b))
+		}
+		return v
+	}
+	return nilInterfacePtr
+}
+
+// primaryKeys returns field names of struct type rt tagged as gorm primary
+// key in declaration order, embedded structs are included. Field "ID" is
+// returned if there is no tagged field.
+func primaryKeys(rt reflect.Type) []string {
+	if isMapType(rt) {
+		return []string{"ID"}
+	}
+	keys := taggedPrimaryKeys(rt)
+	if len(keys) == 0 {
+		if _, ok := rt.FieldByName("ID"); ok {
+			keys = append(keys, "ID")
+		}
+	}
+	return keys
+}
+
+func taggedPrimaryKeys(rt reflect.Type) (keys []string) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			keys = append(keys, taggedPrimaryKeys(field.Type)...)
+			continue
+		}
+		for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+			setting = strings.ToLower(strings.TrimSpace(setting))
+			if setting == "primarykey" || setting == "primary_key" {
+				keys = append(keys, field.Name)
+				break
+			}
+		}
+	}
+	return
+}
+
+// isSelected reports whether column of table is covered by selects
+func isSelected(selects []string, table, column string) bool {
+	table, column = strings.ToLower(table), strings.ToLower(column)
+	for _, s := range selects {
+		s = strings.ToLower(strings.TrimSpace(strings.NewReplacer("`", "", `"`, "", "[", "", "]", "").Replace(s)))
+		switch {
+		case s == "*", s == table+".*":
+			return true
+		case s == column, s == table+"."+column:
+			return true
+		case strings.HasSuffix(s, " as "+column):
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTime converts time values to UTC, other values are returned as is
+func normalizeTime(v interface{}) interface{} {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC()
+	case *time.Time:
+		if t == nil {
+			return t
+		}
+		utc := t.UTC()
+		return &utc
+	default:
+		return v
+	}
+}
+
+// unmarshalJSONValue unmarshals b into generic JSON value with numbers kept
+// as json.Number
+func unmarshalJSONValue(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// toQueryArg converts cursor values which are not supported by sql drivers
+// into their lossless string representation, protobuf timestamps are
+// converted into times
+func toQueryArg(v interface{}) interface{} {
+	if t, ok := timestampToTime(v); ok {
+		return t
+	}
+	switch n := v.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+			return u
+		}
+		return n.String()
+	case big.Int:
+		return n.String()
+	case *big.Int:
+		if n == nil {
+			return nil
+		}
+		return n.String()
+	case big.Float:
+		return n.Text('g', -1)
+	case *big.Float:
+		if n == nil {
+			return nil
+		}
+		return n.Text('g', -1)
+	default:
+		return v
+	}
+}
+
+var (
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType  = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigIntType   = reflect.TypeOf(big.Int{})
+)
+
+// isBigInt reports whether t is big.Int, which is marshaled by its pointer
+func isBigInt(t reflect.Type) bool {
+	return t == bigIntType
+}
+
+// isBigFloat reports whether t (or pointer to t) is big.Float
+func isBigFloat(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == bigFloatType
+}
+
+// isByteSlice reports whether t (or pointer to t) is a slice of bytes
+func isByteSlice(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// isByteArray reports whether t (or pointer to t) is a fixed size byte array
+func isByteArray(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8
+}
+
+// isValuerScanner reports whether t (or pointer to t) is a custom type which
+// can be both converted to and scanned from driver value
+func isValuerScanner(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pt := reflect.PtrTo(t)
+	return (t.Implements(valuerType) || pt.Implements(valuerType)) && pt.Implements(scannerType)
+}
+
+// isNullValue reports whether rv is a nil pointer or a custom type whose
+// driver value is NULL
+func isNullValue(rv reflect.Value) bool {
+	if (rv.Kind() == reflect.Ptr || isByteSlice(rv.Type())) && rv.IsNil() {
+		return true
+	}
+	if isValuerScanner(rv.Type()) {
+		v, err := toValuer(rv).Value()
+		return err == nil && v == nil
+	}
+	return false
+}
+
+func toValuer(rv reflect.Value) driver.Valuer {
+	if v, ok := rv.Interface().(driver.Valuer); ok {
+		return v
+	}
+	return toAddressable(rv).Addr().Interface().(driver.Valuer)
+}
+
+// toAddressable copies rv to an addressable value if it is not addressable
+func toAddressable(rv reflect.Value) reflect.Value {
+	if rv.CanAddr() {
+		return rv
+	}
+	pv := reflect.New(rv.Type())
+	pv.Elem().Set(rv)
+	return pv.Elem()
+}
@@ -0,0 +1,96 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Names of paging values
+const (
+	valueAfter  = "after"
+	valueBefore = "before"
+	valueLimit  = "limit"
+	valueOrder  = "order"
+)
+
+// Errors of paging values
+var (
+	ErrInvalidLimit = errors.New("limit should be a positive integer")
+	ErrInvalidOrder = errors.New("order should be ASC or DESC")
+)
+
+// FromValues binds paging values after, before, limit and order, e.g. query
+// parameters of request, to paginator. Missing and empty values are ignored.
+func (p *Paginator) FromValues(values url.Values) error {
+	if s := values.Get(valueLimit); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidLimit, s)
+		}
+		p.SetLimit(limit)
+	}
+	if s := values.Get(valueOrder); s != "" {
+		order := Order(strings.ToUpper(s))
+		if order != ASC && order != DESC {
+			return fmt.Errorf("%w: %q", ErrInvalidOrder, s)
+		}
+		p.SetOrder(order)
+	}
+	if after := values.Get(valueAfter); after != "" {
+		p.SetAfterCursor(after)
+	}
+	if before := values.Get(valueBefore); before != "" {
+		p.SetBeforeCursor(before)
+	}
+	return nil
+}
+
+// ToValues returns paging values of paginator, which are bound back by
+// FromValues
+func (p *Paginator) ToValues() url.Values {
+	values := url.Values{}
+	if p.cursor.After != nil && *p.cursor.After != "" {
+		values.Set(valueAfter, *p.cursor.After)
+	}
+	if p.cursor.Before != nil && *p.cursor.Before != "" {
+		values.Set(valueBefore, *p.cursor.Before)
+	}
+	if p.limit > 0 {
+		values.Set(valueLimit, strconv.Itoa(p.limit))
+	}
+	if p.order != "" {
+		values.Set(valueOrder, string(p.order))
+	}
+	return values
+}
+
+// NextValues returns copy of values, e.g. query parameters of request with
+// filters, with cursor of the next page of paginated page, it is nil if
+// there is no next page
+func (p *Paginator) NextValues(values url.Values) url.Values {
+	return withCursor(values, valueAfter, p.resolved().next.After)
+}
+
+// PrevValues returns copy of values with cursor of the previous page of
+// paginated page, it is nil if there is no previous page
+func (p *Paginator) PrevValues(values url.Values) url.Values {
+	return withCursor(values, valueBefore, p.resolved().next.Before)
+}
+
+// withCursor returns copy of values with cursor set to name
+func withCursor(values url.Values, name string, cursor *string) url.Values {
+	if cursor == nil {
+		return nil
+	}
+	result := make(url.Values, len(values)+1)
+	for k, v := range values {
+		result[k] = append([]string(nil), v...)
+	}
+	result.Del(valueAfter)
+	result.Del(valueBefore)
+	result.Set(name, *cursor)
+	return result
+}
@@ -0,0 +1,93 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/iancoleman/strcase"
+)
+
+const windowAlias = "paginator_window"
+
+// ErrWrapNotSupported is returned when paginating in a mode requiring query
+// to be wrapped as subquery, but query does not implement WrapQuery
+var ErrWrapNotSupported = errors.New("query should implement WrapQuery to be wrapped as subquery")
+
+// SetWindow sets window mode, query is wrapped as subquery selecting
+// COUNT(*) OVER () into totalField and ROW_NUMBER() OVER (ORDER BY keys) into
+// positionField of model, so that a single round trip returns both the page
+// and its total and positions in PageInfo. Either field can be empty to skip.
+// Fields should be read-only for GORM, e.g. `gorm:"->;-:migration"`.
+func (p *Paginator) SetWindow(totalField, positionField string) {
+	p.windowTotalField = totalField
+	p.windowPositionField = positionField
+}
+
+func (p *Paginator) isWindowMode() bool {
+	return p.windowTotalField != "" || p.windowPositionField != ""
+}
+
+func (p *Paginator) validateWindowFields(rt reflect.Type) error {
+	if isMapType(rt) {
+		return nil
+	}
+	for _, key := range []string{p.windowTotalField, p.windowPositionField} {
+		if key == "" {
+			continue
+		}
+		if _, ok := rt.FieldByName(key); !ok {
+			return &InvalidKeyError{Key: key, Model: rt.Name()}
+		}
+	}
+	return nil
+}
+
+// appendWindow wraps query as subquery with window functions appended to its
+// selection, paging keys then refer to columns of the subquery
+func (p *Paginator) appendWindow(query Query) (Query, error) {
+	if !p.isWindowMode() {
+		return query, nil
+	}
+	var selects []string
+	if p.windowTotalField != "" {
+		selects = append(selects, fmt.Sprintf("COUNT(*) OVER () AS %s", strcase.ToSnake(p.windowTotalField)))
+	}
+	if p.windowPositionField != "" {
+		selects = append(selects, fmt.Sprintf(
+			"ROW_NUMBER() OVER (ORDER BY %s) AS %s",
+			p.getOrderBy(p.order),
+			strcase.ToSnake(p.windowPositionField),
+		))
+	}
+	return p.wrap(query, windowAlias, selects...)
+}
+
+func (p *Paginator) initWindowPageInfo(elems reflect.Value) {
+	if !p.isWindowMode() || elems.Len() == 0 {
+		return
+	}
+	first := reflect.Indirect(elems.Index(0))
+	last := reflect.Indirect(elems.Index(elems.Len() - 1))
+	if p.windowTotalField != "" {
+		p.pageInfo.Total = toInt64Ptr(fieldByName(first, p.windowTotalField))
+	}
+	if p.windowPositionField != "" {
+		p.pageInfo.StartPosition = toInt64Ptr(fieldByName(first, p.windowPositionField))
+		p.pageInfo.EndPosition = toInt64Ptr(fieldByName(last, p.windowPositionField))
+	}
+}
+
+func toInt64Ptr(rv reflect.Value) *int64 {
+	rv = reflect.Indirect(rv)
+	var n int64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = int64(rv.Uint())
+	default:
+		return nil
+	}
+	return &n
+}
@@ -0,0 +1,62 @@
+package paginator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ZeroPolicy is how zero values of key, e.g. zero time or empty string, are
+// treated in cursors
+type ZeroPolicy string
+
+// Zero policies
+const (
+	// ZeroAsValue treats zero values as legitimate values, which is default
+	ZeroAsValue ZeroPolicy = ""
+	// ZeroAsNull treats zero values as missing values, i.e. NULL of columns
+	// scanned into non-pointer fields, which are encoded into cursors as NULL
+	// and positioned by Nulls of key
+	ZeroAsNull ZeroPolicy = "null"
+	// ZeroReject rejects zero values, rows of page to encode cursors from by
+	// ErrZeroKeyValue and cursors holding them by ErrInvalidCursor
+	ZeroReject ZeroPolicy = "reject"
+)
+
+// ErrZeroKeyValue is returned when a cursor is encoded from row with zero value
+// of key of ZeroReject, e.g. time column not selected by query
+var ErrZeroKeyValue = errors.New("paging key of cursor should not be zero value")
+
+// checkZeroKeys rejects zero values of keys of ZeroReject in rows, which are
+// either rows of page or their keyValues
+func (p *Paginator) checkZeroKeys(rows ...interface{}) error {
+	for i, rule := range p.rules {
+		if rule.Zero != ZeroReject {
+			continue
+		}
+		for _, row := range rows {
+			var field reflect.Value
+			if values, ok := row.(keyValues); ok {
+				field = reflect.ValueOf(values[i])
+			} else {
+				field = rule.keyField(reduceValue(row))
+			}
+			if isZeroKey(field) {
+				return fmt.Errorf("%w: %s", ErrZeroKeyValue, rule.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// isZeroKey reports whether field of key is zero value, pointers are zero by
+// values they point to and NULL values are not zero
+func isZeroKey(field reflect.Value) bool {
+	for field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+		if field.IsNil() {
+			return false
+		}
+		field = field.Elem()
+	}
+	return field.IsValid() && field.IsZero()
+}